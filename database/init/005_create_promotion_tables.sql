@@ -0,0 +1,54 @@
+-- Create promotions schema and tables
+
+-- Create the promotions schema if it doesn't exist
+CREATE SCHEMA IF NOT EXISTS promotions;
+
+-- Grant permissions to admin user
+GRANT ALL PRIVILEGES ON SCHEMA promotions TO admin;
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA promotions TO admin;
+GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA promotions TO admin;
+
+-- Create promotions tables
+DROP TABLE IF EXISTS promotions.t_coupon_redemption;
+DROP TABLE IF EXISTS promotions.t_promotion;
+
+CREATE TABLE promotions.t_promotion (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(50) NOT NULL,
+    description TEXT,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'buy_x_get_y')),
+    value DECIMAL(12,2) NOT NULL DEFAULT 0,
+    category VARCHAR(50),
+    product_code VARCHAR(20),
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    get_quantity INTEGER NOT NULL DEFAULT 0,
+    min_spend DECIMAL(12,2) NOT NULL DEFAULT 0,
+    max_uses_per_user INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX ux_promotion_code ON promotions.t_promotion (UPPER(code));
+
+CREATE TABLE promotions.t_coupon_redemption (
+    id UUID PRIMARY KEY,
+    coupon_code VARCHAR(50) NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    order_id UUID NOT NULL,
+    amount DECIMAL(12,2) NOT NULL,
+    redeemed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_coupon_redemption_order
+        FOREIGN KEY (order_id)
+        REFERENCES orders.t_order(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX ix_coupon_redemption_code_user ON promotions.t_coupon_redemption (UPPER(coupon_code), user_id);
+
+-- Sample promotions
+INSERT INTO promotions.t_promotion (code, description, type, value, min_spend) VALUES ('WELCOME10', '10% off your order', 'percentage', 10, 0);
+INSERT INTO promotions.t_promotion (code, description, type, value, min_spend, max_uses_per_user) VALUES ('SAVE20', '20 off orders over 100', 'fixed', 20, 100, 1);
+INSERT INTO promotions.t_promotion (code, description, type, value, category) VALUES ('BOOKS15', '15% off all books', 'percentage', 15, 'Books');
+INSERT INTO promotions.t_promotion (code, description, type, buy_quantity, get_quantity, category) VALUES ('TOYS3FOR2', 'Buy 2 toys, get 1 free', 'buy_x_get_y', 2, 1, 'Toys');
@@ -0,0 +1,21 @@
+-- Product attributes and variants
+
+ALTER TABLE products.t_product ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_product_attributes ON products.t_product USING GIN (attributes);
+
+DROP TABLE IF EXISTS products.t_product_variant;
+
+CREATE TABLE products.t_product_variant (
+	id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+	product_id INT NOT NULL REFERENCES products.t_product(id) ON DELETE CASCADE,
+	sku VARCHAR(40) UNIQUE NOT NULL,
+	price DECIMAL(12,2),
+	stock INTEGER NOT NULL DEFAULT 0,
+	attributes JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_variant_product_id ON products.t_product_variant (product_id);
+CREATE INDEX idx_product_variant_attributes ON products.t_product_variant USING GIN (attributes);
@@ -0,0 +1,5 @@
+-- Ordered products reference the variant SKU and keep a snapshot of its attributes
+
+ALTER TABLE orders.t_ordered_product
+    ADD COLUMN sku VARCHAR(40),
+    ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
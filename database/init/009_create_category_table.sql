@@ -0,0 +1,39 @@
+-- Category hierarchy
+
+DROP TABLE IF EXISTS products.t_category;
+
+CREATE TABLE products.t_category (
+	id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+	parent_id INT REFERENCES products.t_category(id) ON DELETE RESTRICT,
+	name VARCHAR(50) NOT NULL,
+	slug VARCHAR(60) UNIQUE NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_category_parent_id ON products.t_category (parent_id);
+
+ALTER TABLE products.t_product ADD COLUMN category_id INT REFERENCES products.t_category(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_product_category_id ON products.t_product (category_id);
+
+-- Normalise the existing free-text categories. Spellings that only differ in
+-- case, whitespace or punctuation map to the same slug and become a single
+-- top-level category; the product keeps the canonical name in t_product.category.
+INSERT INTO products.t_category (name, slug)
+SELECT DISTINCT ON (slug) name, slug
+FROM (
+	SELECT
+		initcap(trim(category)) AS name,
+		trim(BOTH '-' FROM regexp_replace(lower(trim(category)), '[^a-z0-9]+', '-', 'g')) AS slug
+	FROM products.t_product
+	WHERE category IS NOT NULL AND trim(category) <> ''
+) AS normalised
+WHERE slug <> ''
+ORDER BY slug, name;
+
+UPDATE products.t_product AS p
+SET category_id = c.id,
+	category = c.name
+FROM products.t_category AS c
+WHERE c.slug = trim(BOTH '-' FROM regexp_replace(lower(trim(p.category)), '[^a-z0-9]+', '-', 'g'));
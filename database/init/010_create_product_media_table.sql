@@ -0,0 +1,18 @@
+-- Product media gallery
+
+DROP TABLE IF EXISTS products.t_product_media;
+
+CREATE TABLE products.t_product_media (
+	id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+	product_id INT NOT NULL REFERENCES products.t_product(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	content_type VARCHAR(50) NOT NULL,
+	storage_key VARCHAR(255) NOT NULL,
+	thumbnails JSONB NOT NULL DEFAULT '{}',
+	width INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	size_bytes BIGINT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	-- Deferred so that a reorder can swap positions within one transaction
+	CONSTRAINT uq_product_media_position UNIQUE (product_id, position) DEFERRABLE INITIALLY DEFERRED
+);
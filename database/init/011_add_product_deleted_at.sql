@@ -0,0 +1,6 @@
+-- Soft deletion of products. Deleted products keep their product code, which
+-- order history refers to, until they are purged.
+
+ALTER TABLE products.t_product ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_product_deleted_at ON products.t_product (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,32 @@
+-- Price history and scheduled price changes
+
+DROP TABLE IF EXISTS products.t_price_history;
+DROP TABLE IF EXISTS products.t_price_schedule;
+
+CREATE TABLE products.t_price_schedule (
+	id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+	product_id INT NOT NULL REFERENCES products.t_product(id) ON DELETE CASCADE,
+	-- Set on the schedule that reverts another one, e.g. the end of a sale
+	parent_id INT REFERENCES products.t_price_schedule(id) ON DELETE CASCADE,
+	price DECIMAL(12,2) NOT NULL,
+	effective_at TIMESTAMPTZ NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	applied_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_price_schedule_pending ON products.t_price_schedule (effective_at) WHERE status = 'pending';
+CREATE INDEX idx_price_schedule_product_id ON products.t_price_schedule (product_id);
+CREATE INDEX idx_price_schedule_parent_id ON products.t_price_schedule (parent_id);
+
+CREATE TABLE products.t_price_history (
+	id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+	product_id INT NOT NULL REFERENCES products.t_product(id) ON DELETE CASCADE,
+	old_price DECIMAL(12,2) NOT NULL,
+	new_price DECIMAL(12,2) NOT NULL,
+	source VARCHAR(20) NOT NULL,
+	schedule_id INT REFERENCES products.t_price_schedule(id) ON DELETE SET NULL,
+	changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_price_history_product_id ON products.t_price_history (product_id, changed_at DESC);
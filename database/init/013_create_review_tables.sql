@@ -0,0 +1,34 @@
+-- Product reviews, helpful votes and the rating aggregate of approved reviews
+
+DROP TABLE IF EXISTS products.t_review_vote;
+DROP TABLE IF EXISTS products.t_review;
+
+ALTER TABLE products.t_product
+	ADD COLUMN rating_average DECIMAL(3,2) NOT NULL DEFAULT 0,
+	ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE products.t_review (
+	id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+	product_id INT NOT NULL REFERENCES products.t_product(id) ON DELETE CASCADE,
+	user_id VARCHAR(100) NOT NULL,
+	rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+	title VARCHAR(150) NOT NULL DEFAULT '',
+	body TEXT NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	moderation_note TEXT NOT NULL DEFAULT '',
+	helpful_count INT NOT NULL DEFAULT 0,
+	moderated_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_id, user_id)
+);
+
+CREATE INDEX idx_review_product_status ON products.t_review (product_id, status, created_at DESC);
+CREATE INDEX idx_review_status ON products.t_review (status, created_at);
+
+CREATE TABLE products.t_review_vote (
+	review_id INT NOT NULL REFERENCES products.t_review(id) ON DELETE CASCADE,
+	user_id VARCHAR(100) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (review_id, user_id)
+);
@@ -0,0 +1,21 @@
+-- Events processed by each message consumer, so that redelivered events are
+-- handled once
+
+CREATE SCHEMA IF NOT EXISTS messaging;
+
+GRANT ALL PRIVILEGES ON SCHEMA messaging TO admin;
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA messaging TO admin;
+GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA messaging TO admin;
+
+DROP TABLE IF EXISTS messaging.t_processed_event;
+
+CREATE TABLE messaging.t_processed_event (
+	consumer VARCHAR(100) NOT NULL,
+	event_id VARCHAR(100) NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	locked_until TIMESTAMPTZ NOT NULL,
+	processed_at TIMESTAMPTZ,
+	PRIMARY KEY (consumer, event_id)
+);
+
+CREATE INDEX idx_processed_event_processed_at ON messaging.t_processed_event (processed_at);
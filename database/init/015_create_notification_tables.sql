@@ -0,0 +1,23 @@
+-- Outcomes of sending notifications through each channel
+
+CREATE SCHEMA IF NOT EXISTS notifications;
+
+GRANT ALL PRIVILEGES ON SCHEMA notifications TO admin;
+GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA notifications TO admin;
+GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA notifications TO admin;
+
+DROP TABLE IF EXISTS notifications.t_delivery;
+
+CREATE TABLE notifications.t_delivery (
+	id INT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+	event_id VARCHAR(100) NOT NULL,
+	event_type VARCHAR(100) NOT NULL,
+	user_id VARCHAR(100) NOT NULL,
+	channel VARCHAR(20) NOT NULL,
+	recipient VARCHAR(255) NOT NULL DEFAULT '',
+	status VARCHAR(20) NOT NULL,
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_delivery_event_channel ON notifications.t_delivery (event_id, channel, status);
@@ -0,0 +1,10 @@
+-- Category slugs only need to be unique among siblings, so that e.g. both
+-- Phones and Laptops can have an Accessories subcategory. NULLS NOT DISTINCT
+-- keeps top-level slugs unique.
+
+ALTER TABLE products.t_category DROP CONSTRAINT t_category_slug_key;
+
+ALTER TABLE products.t_category
+	ADD CONSTRAINT t_category_parent_id_slug_key UNIQUE NULLS NOT DISTINCT (parent_id, slug);
+
+CREATE INDEX idx_category_slug ON products.t_category (slug);
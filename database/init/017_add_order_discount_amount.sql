@@ -0,0 +1,3 @@
+-- Orders record the coupon discount taken off their total
+
+ALTER TABLE orders.t_order ADD COLUMN discount_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
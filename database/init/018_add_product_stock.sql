@@ -0,0 +1,11 @@
+-- Stock and a per-order limit that carts validate against. Seeded products
+-- get some stock and a limit of 10.
+
+ALTER TABLE products.t_product
+	ADD COLUMN stock INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN max_order_quantity INTEGER NOT NULL DEFAULT 0;
+
+UPDATE products.t_product
+SET stock = 10 + floor(random() * 190)::INT,
+    max_order_quantity = 10
+WHERE stock = 0;
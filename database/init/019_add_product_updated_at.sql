@@ -0,0 +1,4 @@
+-- The last update time of a product, which conditional requests compare with
+-- If-Modified-Since and the product ETag is derived from
+
+ALTER TABLE products.t_product ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,10 @@
+package cache
+
+// Cache is implemented by the in-process LRU and can be implemented by a
+// shared cache (e.g. Redis) so that callers do not depend on the backend.
+type Cache[V any] interface {
+	Get(key string) (V, bool)
+	Set(key string, value V)
+	Delete(key string)
+	Purge()
+}
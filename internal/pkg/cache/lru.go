@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// LRU is a size-bounded in-process cache. Entries are evicted when the cache
+// is full, least recently used first, or once they are older than the TTL.
+type LRU[V any] struct {
+	name     string
+	capacity int
+	ttl      time.Duration
+	items    map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// NewLRU creates an LRU cache. name labels the cache metrics; a ttl of zero
+// disables expiry.
+func NewLRU[V any](name string, capacity int, ttl time.Duration) *LRU[V] {
+	return &LRU[V]{
+		name:     name,
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.items[key]
+	if !ok {
+		cacheRequestsTotal.WithLabelValues(c.name, "miss").Inc()
+		return zero, false
+	}
+
+	entry := element.Value.(*lruEntry[V])
+	if c.ttl > 0 && !c.now().Before(entry.expiresAt) {
+		c.removeElement(element)
+		cacheEvictionsTotal.WithLabelValues(c.name, "expired").Inc()
+		cacheRequestsTotal.WithLabelValues(c.name, "miss").Inc()
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	cacheRequestsTotal.WithLabelValues(c.name, "hit").Inc()
+	return entry.value, true
+}
+
+func (c *LRU[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		cacheEvictionsTotal.WithLabelValues(c.name, "capacity").Inc()
+	}
+	cacheEntries.WithLabelValues(c.name).Set(float64(c.order.Len()))
+}
+
+func (c *LRU[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+func (c *LRU[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+	cacheEntries.WithLabelValues(c.name).Set(0)
+}
+
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRU[V]) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*lruEntry[V]).key)
+	cacheEntries.WithLabelValues(c.name).Set(float64(c.order.Len()))
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[int]("test_lru_capacity", 2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("Expected b to be evicted")
+	}
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Expected a to be cached with value 1, got %d, %v", v, ok)
+	}
+
+	if c.Len() != 2 {
+		t.Fatalf("Expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRUExpiresEntriesAfterTTL(t *testing.T) {
+	now := time.Now()
+	c := NewLRU[int]("test_lru_ttl", 10, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Set("a", 1)
+
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("Expected a to be cached before the TTL elapses")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("Expected a to expire after the TTL")
+	}
+}
+
+func TestLRUDeleteAndPurge(t *testing.T) {
+	c := NewLRU[int]("test_lru_delete", 10, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("Expected a to be deleted")
+	}
+
+	c.Purge()
+	if c.Len() != 0 {
+		t.Fatalf("Expected empty cache after purge, got %d entries", c.Len())
+	}
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	cacheRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_requests_total",
+			Help: "Total number of cache lookups by result (hit or miss)",
+		},
+		[]string{"cache", "result"},
+	)
+
+	cacheEvictionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_evictions_total",
+			Help: "Total number of cache entries evicted by reason",
+		},
+		[]string{"cache", "reason"},
+	)
+
+	cacheEntries = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cache_entries",
+			Help: "Number of entries currently held in the cache",
+		},
+		[]string{"cache"},
+	)
+)
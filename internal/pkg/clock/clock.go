@@ -0,0 +1,27 @@
+package clock
+
+import "time"
+
+// Clock abstracts the passage of time so that code which waits for a point
+// in time can be tested without sleeping.
+type Clock interface {
+	Now() time.Time
+	// After delivers the current time on the returned channel once d has
+	// elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+// Real returns a Clock backed by the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
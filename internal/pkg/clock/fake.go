@@ -0,0 +1,80 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to. Channels returned by After
+// fire once Advance or Set moves the clock past their deadline.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now: now,
+	}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	deadline := f.now.Add(d)
+	if !deadline.After(f.now) {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeWaiter{deadline: deadline, ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to t and fires every waiter whose deadline has passed.
+// Moving the clock backwards is ignored.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if t.Before(f.now) {
+		return
+	}
+	f.now = t
+
+	pending := f.waiters[:0]
+	for _, waiter := range f.waiters {
+		if waiter.deadline.After(t) {
+			pending = append(pending, waiter)
+			continue
+		}
+		waiter.ch <- t
+	}
+	f.waiters = pending
+}
+
+// Waiters returns the number of pending After calls. Tests use it to wait
+// until the code under test is blocked on the clock.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
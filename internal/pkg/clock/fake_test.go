@@ -0,0 +1,57 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeAfterFiresWhenAdvancedPastDeadline(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	ch := c.After(time.Minute)
+	if c.Waiters() != 1 {
+		t.Fatalf("Expected 1 waiter, got %d", c.Waiters())
+	}
+
+	c.Advance(59 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("Expected After not to fire before its deadline")
+	default:
+	}
+
+	c.Advance(time.Second)
+	select {
+	case fired := <-ch:
+		if !fired.Equal(start.Add(time.Minute)) {
+			t.Errorf("Expected to fire at %v, got %v", start.Add(time.Minute), fired)
+		}
+	default:
+		t.Fatal("Expected After to fire at its deadline")
+	}
+
+	if c.Waiters() != 0 {
+		t.Errorf("Expected no waiters, got %d", c.Waiters())
+	}
+}
+
+func TestFakeAfterWithNonPositiveDurationFiresImmediately(t *testing.T) {
+	c := NewFake(time.Now())
+
+	select {
+	case <-c.After(0):
+	default:
+		t.Fatal("Expected After(0) to fire immediately")
+	}
+}
+
+func TestFakeSetIgnoresMovingBackwards(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	c.Set(start.Add(-time.Hour))
+	if !c.Now().Equal(start) {
+		t.Errorf("Expected clock to stay at %v, got %v", start, c.Now())
+	}
+}
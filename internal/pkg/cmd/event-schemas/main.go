@@ -0,0 +1,25 @@
+// Command event-schemas writes the JSON Schema of the current version of every
+// event to a directory. It fails, leaving the existing schema in place, when
+// an event changed in a way that breaks consumers of its current version.
+//
+//	event-schemas [-dir events/schemas]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+)
+
+func main() {
+	dir := flag.String("dir", "events/schemas", "directory of the schema files")
+	flag.Parse()
+
+	if err := events.WriteSchemas(*dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %d event schemas to %s\n", len(events.Definitions), *dir)
+}
@@ -0,0 +1,83 @@
+// Command parking-lot inspects, replays and purges the messages parked by
+// consumers with retry queues. It connects with the RABBITMQ_* settings of
+// the services.
+//
+//	parking-lot inspect [-limit 10] <queue>
+//	parking-lot replay [-limit 100] <queue>
+//	parking-lot purge <queue>
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	command := os.Args[1]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	limit := flags.Int("limit", 0, "maximum number of messages (inspect defaults to 10, replay to 100)")
+	flags.Parse(os.Args[2:])
+	if flags.NArg() != 1 {
+		usage()
+	}
+	queue := flags.Arg(0)
+
+	log := logger.NewLogger()
+	client, err := rabbitmq.NewRabbitMQClient(log)
+	if err != nil {
+		log.Error("Failed to connect to RabbitMQ", "error", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	switch command {
+	case "inspect":
+		parked, err := client.InspectParked(queue, limitOr(*limit, 10))
+		if err != nil {
+			fail(err)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(parked)
+	case "replay":
+		replayed, err := client.ReplayParked(queue, limitOr(*limit, 100))
+		fmt.Printf("Replayed %d messages to %s\n", replayed, queue)
+		if err != nil {
+			fail(err)
+		}
+	case "purge":
+		purged, err := client.PurgeParked(queue)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Printf("Purged %d messages from %s\n", purged, rabbitmq.ParkingLotName(queue))
+	default:
+		usage()
+	}
+}
+
+func limitOr(limit int, fallback int) int {
+	if limit > 0 {
+		return limit
+	}
+	return fallback
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: parking-lot inspect|replay|purge [-limit n] <queue>")
+	os.Exit(2)
+}
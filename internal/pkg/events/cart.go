@@ -0,0 +1,19 @@
+package events
+
+const (
+	CartExchange = "cart"
+
+	WishlistPriceDropped = "wishlist.price_dropped"
+)
+
+// WishlistPriceDroppedEvent reports that the catalog price of a wishlisted
+// product, or of the wishlisted variant, fell.
+type WishlistPriceDroppedEvent struct {
+	UserId       string  `json:"user_id"`
+	WishlistName string  `json:"wishlist_name"`
+	ProductCode  string  `json:"product_code"`
+	SKU          string  `json:"sku,omitempty"`
+	Name         string  `json:"name"`
+	OldPrice     float64 `json:"old_price"`
+	NewPrice     float64 `json:"new_price"`
+}
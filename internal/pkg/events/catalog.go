@@ -0,0 +1,45 @@
+package events
+
+const (
+	CatalogExchange = "catalog"
+
+	ProductCreated  = "product.created"
+	ProductUpdated  = "product.updated"
+	ProductDeleted  = "product.deleted"
+	ProductRestored = "product.restored"
+	ProductImported = "product.imported"
+)
+
+type ProductEvent struct {
+	ProductCode string `json:"product_code"`
+}
+
+type ProductCreatedEvent struct {
+	ProductEvent
+	Name        string  `json:"name"`
+	Category    string  `json:"category"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Stock       int     `json:"stock"`
+}
+
+type ProductUpdatedEvent struct {
+	ProductEvent
+	ChangedFields []string `json:"changed_fields"`
+	OldPrice      float64  `json:"old_price"`
+	NewPrice      float64  `json:"new_price"`
+}
+
+type ProductDeletedEvent struct {
+	ProductEvent
+}
+
+type ProductRestoredEvent struct {
+	ProductEvent
+}
+
+type ProductImportedEvent struct {
+	ProductEvent
+	Price float64 `json:"price"`
+	Stock int     `json:"stock"`
+}
@@ -0,0 +1,44 @@
+package events
+
+import "reflect"
+
+// Definition describes the current version of an event. Version must be
+// increased whenever the payload changes in a way that breaks consumers of
+// the previous version; see BreakingChanges.
+type Definition struct {
+	Type    string
+	Version int
+	// Payload is a zero value of the event's payload type
+	Payload any
+}
+
+var Definitions = []Definition{
+	{Type: OrderCreated, Version: 1, Payload: OrderCreatedEvent{}},
+	{Type: OrderStatusUpdated, Version: 1, Payload: OrderStatusUpdatedEvent{}},
+	{Type: OrderConfirmed, Version: 1, Payload: OrderConfirmedEvent{}},
+	{Type: OrderProcessing, Version: 1, Payload: OrderProcessingEvent{}},
+	{Type: OrderShipped, Version: 1, Payload: OrderShippedEvent{}},
+	{Type: OrderDelivered, Version: 1, Payload: OrderDeliveredEvent{}},
+	{Type: OrderCancelled, Version: 1, Payload: OrderCancelledEvent{}},
+
+	{Type: ProductCreated, Version: 1, Payload: ProductCreatedEvent{}},
+	{Type: ProductUpdated, Version: 1, Payload: ProductUpdatedEvent{}},
+	{Type: ProductDeleted, Version: 1, Payload: ProductDeletedEvent{}},
+	{Type: ProductRestored, Version: 1, Payload: ProductRestoredEvent{}},
+	{Type: ProductImported, Version: 1, Payload: ProductImportedEvent{}},
+
+	{Type: WishlistPriceDropped, Version: 1, Payload: WishlistPriceDroppedEvent{}},
+}
+
+var definitionsByPayload = func() map[reflect.Type]Definition {
+	byPayload := make(map[reflect.Type]Definition, len(Definitions))
+	for _, definition := range Definitions {
+		byPayload[reflect.TypeOf(definition.Payload)] = definition
+	}
+	return byPayload
+}()
+
+func definitionOf[T any]() (Definition, bool) {
+	definition, ok := definitionsByPayload[reflect.TypeFor[T]()]
+	return definition, ok
+}
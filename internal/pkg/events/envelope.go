@@ -0,0 +1,105 @@
+// Package events holds the events the services exchange over RabbitMQ, shared
+// by their publishers and consumers. Events are published as an Envelope
+// around their payload, and the JSON Schema of every version of an event is
+// kept in schemas/ to detect changes that would break consumers.
+package events
+
+//go:generate go run ../cmd/event-schemas -dir schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnsupportedVersion means an event was published with a newer schema
+	// version than the consumer knows
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+	ErrUnexpectedType     = errors.New("unexpected event type")
+)
+
+// Metadata is shared by every event.
+type Metadata struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	SchemaVersion int       `json:"schema_version"`
+	Producer      string    `json:"producer"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	// CorrelationID is shared by the events that follow from one another,
+	// see WithCorrelationID, and is the event's own ID otherwise
+	CorrelationID string `json:"correlation_id"`
+}
+
+// Envelope carries an event payload with its metadata.
+type Envelope[T any] struct {
+	Metadata
+	Data T `json:"data"`
+}
+
+// envelope decodes an Envelope without its UnmarshalJSON method.
+type envelope[T any] Envelope[T]
+
+// Option sets optional metadata of a new envelope.
+type Option func(*Metadata)
+
+// WithCorrelationID places an event in an existing chain of events, such as
+// the events of one order. An empty id leaves the event's own ID in place.
+func WithCorrelationID(id string) Option {
+	return func(metadata *Metadata) {
+		if id != "" {
+			metadata.CorrelationID = id
+		}
+	}
+}
+
+// New wraps data in an envelope with the type and current schema version of
+// its event. data must be of a type listed in Definitions.
+func New[T any](producer string, data T, options ...Option) Envelope[T] {
+	definition, ok := definitionOf[T]()
+	if !ok {
+		panic(fmt.Sprintf("events: no definition for payload type %T", data))
+	}
+	id := uuid.New().String()
+
+	envelope := Envelope[T]{
+		Metadata: Metadata{
+			EventID:       id,
+			EventType:     definition.Type,
+			SchemaVersion: definition.Version,
+			Producer:      producer,
+			OccurredAt:    time.Now().UTC(),
+			CorrelationID: id,
+		},
+		Data: data,
+	}
+	for _, option := range options {
+		option(&envelope.Metadata)
+	}
+	return envelope
+}
+
+// UnmarshalJSON decodes an envelope, rejecting events of another type than T
+// and schema versions newer than the current one, which may have broken the
+// payload. Payloads decoded into a type shared by several events, such as
+// ProductEvent, are not checked.
+func (e *Envelope[T]) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*envelope[T])(e)); err != nil {
+		return err
+	}
+
+	definition, ok := definitionOf[T]()
+	if !ok {
+		return nil
+	}
+	if e.EventType != definition.Type {
+		return fmt.Errorf("%w: expected %s, got %s", ErrUnexpectedType, definition.Type, e.EventType)
+	}
+	if e.SchemaVersion > definition.Version {
+		return fmt.Errorf("%w: %s v%d, expected up to v%d", ErrUnsupportedVersion, e.EventType, e.SchemaVersion, definition.Version)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	sent := New("order-service", OrderShippedEvent{
+		OrderEvent:     OrderEvent{OrderID: "o1", UserID: "u1"},
+		TrackingNumber: "TRK1",
+	})
+	if sent.EventType != OrderShipped || sent.SchemaVersion != 1 || sent.CorrelationID != sent.EventID {
+		t.Fatalf("Expected a v1 order.shipped envelope correlated with itself, got %+v", sent)
+	}
+
+	body, err := json.Marshal(sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received Envelope[OrderShippedEvent]
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatalf("Expected the envelope to decode, got %v", err)
+	}
+	if received.EventID != sent.EventID || received.Producer != "order-service" || received.Data.TrackingNumber != "TRK1" || !received.OccurredAt.Equal(sent.OccurredAt) {
+		t.Errorf("Expected %+v, got %+v", sent, received)
+	}
+}
+
+func TestEnvelopeWithCorrelationID(t *testing.T) {
+	created := New("order-service", OrderCreatedEvent{OrderEvent: OrderEvent{OrderID: "o1"}})
+	shipped := New("order-service", OrderShippedEvent{OrderEvent: OrderEvent{OrderID: "o1"}}, WithCorrelationID(created.EventID))
+	if shipped.CorrelationID != created.EventID || shipped.EventID == created.EventID {
+		t.Errorf("Expected order.shipped to be correlated with %s, got %+v", created.EventID, shipped.Metadata)
+	}
+
+	uncorrelated := New("order-service", OrderShippedEvent{}, WithCorrelationID(""))
+	if uncorrelated.CorrelationID != uncorrelated.EventID {
+		t.Errorf("Expected an empty correlation id to be ignored, got %+v", uncorrelated.Metadata)
+	}
+}
+
+func TestEnvelopeRejectsUnknownEvents(t *testing.T) {
+	var envelope Envelope[OrderShippedEvent]
+
+	err := json.Unmarshal([]byte(`{"event_type":"order.shipped","schema_version":2,"data":{}}`), &envelope)
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"event_type":"order.cancelled","schema_version":1,"data":{}}`), &envelope)
+	if !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("Expected ErrUnexpectedType, got %v", err)
+	}
+}
+
+func TestEnvelopeOfSharedPayload(t *testing.T) {
+	body, _ := json.Marshal(New("catalog-service", ProductDeletedEvent{ProductEvent{ProductCode: "p1"}}))
+
+	var envelope Envelope[ProductEvent]
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("Expected the shared payload to decode, got %v", err)
+	}
+	if envelope.EventType != ProductDeleted || envelope.Data.ProductCode != "p1" {
+		t.Errorf("Expected product.deleted of p1, got %+v", envelope)
+	}
+}
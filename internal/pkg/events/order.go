@@ -1,12 +1,20 @@
-package messaging
+package events
 
-import "time"
+const (
+	OrdersExchange = "orders"
+
+	OrderCreated       = "order.created"
+	OrderStatusUpdated = "order.status.updated"
+	OrderConfirmed     = "order.confirmed"
+	OrderProcessing    = "order.processing"
+	OrderShipped       = "order.shipped"
+	OrderDelivered     = "order.delivered"
+	OrderCancelled     = "order.cancelled"
+)
 
 type OrderEvent struct {
-	EventID   string    `json:"event_id"`
-	Timestamp time.Time `json:"timestamp"`
-	OrderID   string    `json:"order_id"`
-	UserID    string    `json:"user_id"`
+	OrderID string `json:"order_id"`
+	UserID  string `json:"user_id"`
 }
 
 type OrderCreatedEvent struct {
@@ -18,10 +26,12 @@ type OrderCreatedEvent struct {
 }
 
 type OrderCreatedProduct struct {
-	ProductCode string  `json:"product_code"`
-	ProductName string  `json:"product_name"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
+	ProductCode string         `json:"product_code"`
+	SKU         string         `json:"sku,omitempty"`
+	ProductName string         `json:"product_name"`
+	Attributes  map[string]any `json:"attributes,omitempty"`
+	Quantity    int            `json:"quantity"`
+	Price       float64        `json:"price"`
 }
 
 type OrderStatusUpdatedEvent struct {
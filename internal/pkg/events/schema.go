@@ -0,0 +1,259 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema that describes Go event types.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Types lists the JSON types a value may have. A single type is encoded as a
+// string, as JSON Schema allows.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// GenerateSchema returns the JSON Schema of the envelope of an event with its
+// payload.
+func GenerateSchema(definition Definition) *Schema {
+	schema := schemaOf(reflect.TypeFor[Metadata]())
+	schema.Dialect = schemaDialect
+	schema.ID = SchemaFile(definition)
+	schema.Title = definition.Type
+	schema.Properties["event_type"].Const = definition.Type
+	schema.Properties["schema_version"].Const = definition.Version
+	schema.Properties["data"] = schemaOf(reflect.TypeOf(definition.Payload))
+	schema.Required = append(schema.Required, "data")
+	return schema
+}
+
+// SchemaFile names the schema file of an event version.
+func SchemaFile(definition Definition) string {
+	return fmt.Sprintf("%s.v%d.json", definition.Type, definition.Version)
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// schemaOf describes a type the way encoding/json encodes it.
+func schemaOf(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		schema := schemaOf(t.Elem())
+		if len(schema.Type) > 0 {
+			schema.Type = append(schema.Type, "null")
+		}
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.Slice:
+		// nil slices encode as null
+		return &Schema{Type: Types{"array", "null"}, Items: schemaOf(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+		addFields(schema, t)
+		return schema
+	}
+	// Interfaces may hold any value
+	return &Schema{}
+}
+
+// addFields adds the fields of a struct to its schema, promoting the fields
+// of embedded structs as encoding/json does.
+func addFields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = schemaOf(field.Type)
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// BreakingChanges lists the changes from the old to the new schema of an
+// event that consumers of the old schema cannot handle: removed fields,
+// fields that became optional and types that were widened or changed. Added
+// fields are compatible, since consumers ignore fields they do not know.
+func BreakingChanges(previous, current *Schema) []string {
+	return breakingChanges("", previous, current)
+}
+
+func breakingChanges(path string, previous, current *Schema) []string {
+	var changes []string
+	at := func(format string, args ...any) {
+		changes = append(changes, fmt.Sprintf("%s: %s", location(path), fmt.Sprintf(format, args...)))
+	}
+
+	if len(previous.Type) > 0 && (len(current.Type) == 0 || slices.ContainsFunc(current.Type, func(t string) bool { return !slices.Contains(previous.Type, t) })) {
+		at("type changed from %v to %v", []string(previous.Type), []string(current.Type))
+	}
+	if previous.Format != current.Format {
+		at("format changed from %q to %q", previous.Format, current.Format)
+	}
+	if previous.Const != nil && fmt.Sprint(previous.Const) != fmt.Sprint(current.Const) {
+		at("constant changed from %v to %v", previous.Const, current.Const)
+	}
+
+	names := make([]string, 0, len(previous.Properties))
+	for name := range previous.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		field := join(path, name)
+		currentProperty, ok := current.Properties[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: field removed", field))
+			continue
+		}
+		changes = append(changes, breakingChanges(field, previous.Properties[name], currentProperty)...)
+		if slices.Contains(previous.Required, name) && !slices.Contains(current.Required, name) {
+			changes = append(changes, fmt.Sprintf("%s: field no longer required", field))
+		}
+	}
+
+	if previous.Items != nil && current.Items != nil {
+		changes = append(changes, breakingChanges(path+"[]", previous.Items, current.Items)...)
+	}
+	if previous.AdditionalProperties != nil && current.AdditionalProperties != nil {
+		changes = append(changes, breakingChanges(path+"{}", previous.AdditionalProperties, current.AdditionalProperties)...)
+	}
+	return changes
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func location(path string) string {
+	if path == "" {
+		return "event"
+	}
+	return path
+}
+
+// WriteSchemas writes the schema of the current version of every event to
+// dir. The schema of a version that was written before is only replaced when
+// the new one is compatible with it; breaking changes need a new version.
+func WriteSchemas(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, definition := range Definitions {
+		path := filepath.Join(dir, SchemaFile(definition))
+		schema := GenerateSchema(definition)
+
+		existing, err := ReadSchema(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		if existing != nil {
+			if changes := BreakingChanges(existing, schema); len(changes) > 0 {
+				errs = append(errs, fmt.Errorf("%s v%d has breaking changes, increase its version: %s", definition.Type, definition.Version, strings.Join(changes, "; ")))
+				continue
+			}
+		}
+
+		data, err := MarshalSchema(schema)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func ReadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+	}
+	return &schema, nil
+}
+
+// MarshalSchema encodes a schema as it is written to schema files.
+func MarshalSchema(schema *Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSchemasAreCompatible fails when an event changed in a way that breaks
+// consumers of its current version, or when its schema file is out of date.
+func TestSchemasAreCompatible(t *testing.T) {
+	for _, definition := range Definitions {
+		path := filepath.Join("schemas", SchemaFile(definition))
+		committed, err := ReadSchema(path)
+		if err != nil {
+			t.Errorf("Missing schema of %s v%d, run go generate ./events: %v", definition.Type, definition.Version, err)
+			continue
+		}
+
+		schema := GenerateSchema(definition)
+		if changes := BreakingChanges(committed, schema); len(changes) > 0 {
+			t.Errorf("%s v%d has breaking changes, increase its version: %s", definition.Type, definition.Version, strings.Join(changes, "; "))
+			continue
+		}
+
+		expected, _ := MarshalSchema(schema)
+		actual, _ := os.ReadFile(path)
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("Schema of %s v%d is out of date, run go generate ./events", definition.Type, definition.Version)
+		}
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	type item struct {
+		SKU string `json:"sku"`
+	}
+	type v1 struct {
+		ID    string  `json:"id"`
+		Price float64 `json:"price"`
+		Note  string  `json:"note,omitempty"`
+		Items []item  `json:"items"`
+	}
+
+	cases := []struct {
+		name     string
+		payload  any
+		breaking []string
+	}{
+		{"unchanged", v1{}, nil},
+		{"field added", struct {
+			v1
+			Currency string `json:"currency"`
+		}{}, nil},
+		{"optional field made required", struct {
+			ID    string  `json:"id"`
+			Price float64 `json:"price"`
+			Note  string  `json:"note"`
+			Items []item  `json:"items"`
+		}{}, nil},
+		{"field removed", struct {
+			ID    string `json:"id"`
+			Note  string `json:"note,omitempty"`
+			Items []item `json:"items"`
+		}{}, []string{"price: field removed"}},
+		{"type changed", struct {
+			ID    string `json:"id"`
+			Price string `json:"price"`
+			Note  string `json:"note,omitempty"`
+			Items []item `json:"items"`
+		}{}, []string{"price: type changed from [number] to [string]"}},
+		{"field made optional", struct {
+			ID    string  `json:"id,omitempty"`
+			Price float64 `json:"price"`
+			Note  string  `json:"note,omitempty"`
+			Items []item  `json:"items"`
+		}{}, []string{"id: field no longer required"}},
+		{"field made nullable", struct {
+			ID    *string `json:"id"`
+			Price float64 `json:"price"`
+			Note  string  `json:"note,omitempty"`
+			Items []item  `json:"items"`
+		}{}, []string{"id: type changed from [string] to [string null]"}},
+		{"nested field renamed", struct {
+			ID    string  `json:"id"`
+			Price float64 `json:"price"`
+			Note  string  `json:"note,omitempty"`
+			Items []struct {
+				Code string `json:"code"`
+			} `json:"items"`
+		}{}, []string{"items[].sku: field removed"}},
+	}
+
+	previous := schemaOf(reflect.TypeFor[v1]())
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			changes := BreakingChanges(previous, schemaOf(reflect.TypeOf(c.payload)))
+			if !reflect.DeepEqual(changes, c.breaking) {
+				t.Errorf("Expected breaking changes %v, got %v", c.breaking, changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package httpx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag returns a strong entity tag derived from the response body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// JSONETag returns the entity tag of v as WriteConditionalJSON would encode it.
+func JSONETag(v any) (string, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return ETag(body), nil
+}
+
+// SetValidators sets the ETag and Last-Modified response headers. A zero
+// lastModified omits Last-Modified.
+func SetValidators(w http.ResponseWriter, etag string, lastModified time.Time) {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// NotModified evaluates If-None-Match and If-Modified-Since. If-Modified-Since
+// is ignored when If-None-Match is present, as required by RFC 9110.
+func NotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagListMatches(ifNoneMatch, etag, false)
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// PreconditionFailed evaluates If-Match and If-Unmodified-Since against the
+// current representation of a resource before it is modified.
+func PreconditionFailed(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		return !etagListMatches(ifMatch, etag, true)
+	}
+
+	ifUnmodifiedSince := r.Header.Get("If-Unmodified-Since")
+	if ifUnmodifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ifUnmodifiedSince)
+	if err != nil {
+		return false
+	}
+	return lastModified.Truncate(time.Second).After(since)
+}
+
+// WriteConditionalJSON encodes v, sets the validators and answers with
+// 304 Not Modified when the client's cached copy is still current.
+func WriteConditionalJSON(w http.ResponseWriter, r *http.Request, v any, lastModified time.Time) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	etag := ETag(body)
+	SetValidators(w, etag, lastModified)
+
+	if NotModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(body)
+	return err
+}
+
+// WriteConditionalListJSON writes a collection like WriteConditionalJSON, but
+// validates it by its ETag only. The newest update time of the members does
+// not change when a member is removed or drops out of a filter, so it cannot
+// tell a client that its cached list is current.
+func WriteConditionalListJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	return WriteConditionalJSON(w, r, v, time.Time{})
+}
+
+// etagListMatches compares etag against a comma separated If-Match or
+// If-None-Match header value. If-Match uses strong comparison, If-None-Match
+// weak comparison.
+func etagListMatches(header string, etag string, strong bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strong {
+			if !strings.HasPrefix(candidate, "W/") && candidate == etag {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteConditionalJSONReturnsNotModifiedForMatchingETag(t *testing.T) {
+	body := map[string]string{"product_code": "PROD-1"}
+	etag, _ := JSONETag(body)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/PROD-1", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	rec := httptest.NewRecorder()
+
+	if err := WriteConditionalJSON(rec, req, body, time.Time{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got %q", rec.Body.String())
+	}
+	if rec.Header().Get("ETag") != etag {
+		t.Errorf("Expected ETag %s, got %s", etag, rec.Header().Get("ETag"))
+	}
+}
+
+func TestWriteConditionalJSONHonoursIfModifiedSince(t *testing.T) {
+	lastModified := time.Date(2025, 5, 1, 10, 0, 0, 500, time.UTC)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("If-Modified-Since", lastModified.Format(http.TimeFormat))
+	rec := httptest.NewRecorder()
+	WriteConditionalJSON(rec, req, []string{}, lastModified)
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304, got %d", rec.Code)
+	}
+
+	req.Header.Set("If-Modified-Since", lastModified.Add(-time.Minute).Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	WriteConditionalJSON(rec, req, []string{}, lastModified)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Last-Modified") != lastModified.Format(http.TimeFormat) {
+		t.Errorf("Unexpected Last-Modified %s", rec.Header().Get("Last-Modified"))
+	}
+}
+
+func TestWriteConditionalListJSONIgnoresIfModifiedSince(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.Header.Set("If-Modified-Since", time.Now().Format(http.TimeFormat))
+	rec := httptest.NewRecorder()
+	WriteConditionalListJSON(rec, req, []string{"PROD-1"})
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Last-Modified") != "" || rec.Header().Get("ETag") == "" {
+		t.Errorf("Expected only an ETag, got headers %v", rec.Header())
+	}
+
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	WriteConditionalListJSON(rec, req, []string{"PROD-1"})
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for a matching ETag, got %d", rec.Code)
+	}
+}
+
+func TestPreconditionFailed(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		etag    string
+		failed  bool
+	}{
+		{"no header", "", `"abc"`, false},
+		{"matching etag", `"abc"`, `"abc"`, false},
+		{"stale etag", `"old"`, `"abc"`, true},
+		{"weak etag never matches", `W/"abc"`, `"abc"`, true},
+		{"wildcard", "*", `"abc"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/products/PROD-1", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			if got := PreconditionFailed(req, tt.etag, time.Time{}); got != tt.failed {
+				t.Errorf("Expected %v, got %v", tt.failed, got)
+			}
+		})
+	}
+}
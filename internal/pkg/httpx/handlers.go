@@ -0,0 +1,15 @@
+package httpx
+
+import "net/http"
+
+// Handlers combines several ApiHandlers that register their routes on the
+// same mux. Handlers returned by the individual RegisterRoutes calls are
+// ignored, so the combined handlers must not wrap the mux themselves.
+type Handlers []ApiHandler
+
+func (hs Handlers) RegisterRoutes(mux *http.ServeMux) http.Handler {
+	for _, h := range hs {
+		h.RegisterRoutes(mux)
+	}
+	return mux
+}
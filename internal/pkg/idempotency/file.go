@@ -0,0 +1,177 @@
+package idempotency
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+)
+
+type fileKey struct {
+	consumer string
+	eventId  string
+}
+
+type fileRecord struct {
+	Consumer    string    `json:"consumer"`
+	EventId     string    `json:"event_id"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// FileStore is an embedded Store for services without a database. Processed
+// events are kept in memory and appended to a file with one JSON record per
+// line, so completing an event costs a single write. Purge compacts the file
+// to the events that are kept. Claims are only held in memory; after a
+// restart, events that were being handled are handled again, and the file
+// cannot be shared by several consumer instances.
+type FileStore struct {
+	path  string
+	clock clock.Clock
+
+	mu        sync.Mutex
+	processed map[fileKey]time.Time
+	claims    map[fileKey]time.Time
+}
+
+// NewFileStore opens the store at path, loading the events it recorded
+// before.
+func NewFileStore(path string, clk clock.Clock) (*FileStore, error) {
+	s := &FileStore{
+		path:      path,
+		clock:     clk,
+		processed: make(map[fileKey]time.Time),
+		claims:    make(map[fileKey]time.Time),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, os.MkdirAll(filepath.Dir(path), 0o755)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read processed events: %w", err)
+	}
+
+	// A crash while appending can leave a partial last line, which is dropped
+	// so that the next record starts on a line of its own
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := os.Truncate(path, int64(end)); err != nil {
+			return nil, fmt.Errorf("failed to repair processed events: %w", err)
+		}
+		data = data[:end]
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		var record fileRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse processed events %s line %d: %w", path, line, err)
+		}
+		s.processed[fileKey{record.Consumer, record.EventId}] = record.ProcessedAt
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse processed events %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Claim(consumer, eventId string, lease time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := fileKey{consumer, eventId}
+	if _, ok := s.processed[key]; ok {
+		return ErrProcessed
+	}
+	now := s.clock.Now()
+	if lockedUntil, ok := s.claims[key]; ok && now.Before(lockedUntil) {
+		return ErrInProgress
+	}
+	s.claims[key] = now.Add(lease)
+	return nil
+}
+
+func (s *FileStore) Complete(consumer, eventId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := fileKey{consumer, eventId}
+	delete(s.claims, key)
+	processedAt := s.clock.Now()
+	if err := s.append(fileRecord{Consumer: consumer, EventId: eventId, ProcessedAt: processedAt}); err != nil {
+		return err
+	}
+	s.processed[key] = processedAt
+	return nil
+}
+
+func (s *FileStore) Release(consumer, eventId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.claims, fileKey{consumer, eventId})
+	return nil
+}
+
+func (s *FileStore) Purge(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for key, processedAt := range s.processed {
+		if processedAt.Before(before) {
+			delete(s.processed, key)
+			purged++
+		}
+	}
+	if purged == 0 {
+		return 0, nil
+	}
+	return purged, s.compact()
+}
+
+// append adds a record to the end of the file. s.mu must be held.
+func (s *FileStore) append(record fileRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write processed events: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write processed events: %w", err)
+	}
+	return file.Close()
+}
+
+// compact replaces the file with the processed events, dropping the records
+// of purged events. s.mu must be held.
+func (s *FileStore) compact() error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for key, processedAt := range s.processed {
+		if err := encoder.Encode(fileRecord{Consumer: key.consumer, EventId: key.eventId, ProcessedAt: processedAt}); err != nil {
+			return err
+		}
+	}
+
+	// Write a new file and rename it, so a crash cannot leave a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write processed events: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write processed events: %w", err)
+	}
+	return nil
+}
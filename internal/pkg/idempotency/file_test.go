@@ -0,0 +1,124 @@
+package idempotency
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+)
+
+func TestFileStoreClaims(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "processed.ndjson"), clk)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	if err := store.Claim("notifications", "e1", time.Minute); err != nil {
+		t.Fatalf("Failed to claim event: %v", err)
+	}
+	if err := store.Claim("notifications", "e1", time.Minute); !errors.Is(err, ErrInProgress) {
+		t.Errorf("Expected ErrInProgress while claimed, got %v", err)
+	}
+	if err := store.Claim("audit", "e1", time.Minute); err != nil {
+		t.Errorf("Expected another consumer to claim the event, got %v", err)
+	}
+
+	clk.Advance(2 * time.Minute)
+	if err := store.Claim("notifications", "e1", time.Minute); err != nil {
+		t.Errorf("Expected the expired claim to be taken over, got %v", err)
+	}
+
+	if err := store.Release("notifications", "e1"); err != nil {
+		t.Fatalf("Failed to release event: %v", err)
+	}
+	if err := store.Claim("notifications", "e1", time.Minute); err != nil {
+		t.Errorf("Expected the released event to be claimed, got %v", err)
+	}
+	if err := store.Complete("notifications", "e1"); err != nil {
+		t.Fatalf("Failed to complete event: %v", err)
+	}
+	if err := store.Claim("notifications", "e1", time.Minute); !errors.Is(err, ErrProcessed) {
+		t.Errorf("Expected ErrProcessed after completion, got %v", err)
+	}
+}
+
+func TestFileStorePersistsAndPurges(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "idempotency", "processed.ndjson")
+
+	store, err := NewFileStore(path, clk)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	for _, eventId := range []string{"e1", "e2"} {
+		store.Claim("notifications", eventId, time.Minute)
+		if err := store.Complete("notifications", eventId); err != nil {
+			t.Fatalf("Failed to complete event: %v", err)
+		}
+		clk.Advance(time.Hour)
+	}
+
+	reopened, err := NewFileStore(path, clk)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	if err := reopened.Claim("notifications", "e2", time.Minute); !errors.Is(err, ErrProcessed) {
+		t.Errorf("Expected ErrProcessed after reopening, got %v", err)
+	}
+
+	purged, err := NewPurger(reopened, 90*time.Minute, time.Hour, clk, logger.NewLogger()).Purge()
+	if err != nil || purged != 1 {
+		t.Fatalf("Expected 1 purged event, got %d (%v)", purged, err)
+	}
+	if err := reopened.Claim("notifications", "e1", time.Minute); err != nil {
+		t.Errorf("Expected the purged event to be claimed again, got %v", err)
+	}
+	if err := reopened.Claim("notifications", "e2", time.Minute); !errors.Is(err, ErrProcessed) {
+		t.Errorf("Expected the retained event to stay processed, got %v", err)
+	}
+}
+
+func TestFileStoreAppendsAndRepairsPartialRecords(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "processed.ndjson")
+
+	store, err := NewFileStore(path, clk)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	store.Claim("notifications", "e1", time.Minute)
+	if err := store.Complete("notifications", "e1"); err != nil {
+		t.Fatalf("Failed to complete event: %v", err)
+	}
+
+	// Simulate a crash in the middle of appending the next record
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"consumer":"notifications","event_id":"e2"`)
+	file.Close()
+
+	reopened, err := NewFileStore(path, clk)
+	if err != nil {
+		t.Fatalf("Expected the partial record to be dropped, got %v", err)
+	}
+	if err := reopened.Claim("notifications", "e1", time.Minute); !errors.Is(err, ErrProcessed) {
+		t.Errorf("Expected e1 to stay processed, got %v", err)
+	}
+	if err := reopened.Claim("notifications", "e2", time.Minute); err != nil {
+		t.Fatalf("Expected the partially recorded event to be claimed again, got %v", err)
+	}
+	if err := reopened.Complete("notifications", "e2"); err != nil {
+		t.Fatalf("Failed to complete event: %v", err)
+	}
+
+	if _, err := NewFileStore(path, clk); err != nil {
+		t.Errorf("Expected the repaired file to reopen, got %v", err)
+	}
+}
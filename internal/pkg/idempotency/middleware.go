@@ -0,0 +1,64 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+)
+
+// Middleware runs the handler once per event, identified by the event_id of
+// its envelope, and acknowledges redeliveries of processed events without
+// calling the handler. Events are recorded per queue, so consumers of the
+// same event on different queues each handle it once. Deliveries without an
+// event ID are passed to the handler unchanged.
+//
+// While an event is being handled it is claimed for lease, which should
+// outlast the handler timeout; deliveries of a claimed event are retried.
+func Middleware(store Store, lease time.Duration, log logger.Logger) rabbitmq.Middleware {
+	return func(next rabbitmq.DeliveryHandler) rabbitmq.DeliveryHandler {
+		return func(ctx context.Context, delivery *rabbitmq.Delivery) error {
+			eventId := eventIdOf(delivery.Body)
+			if eventId == "" {
+				return next(ctx, delivery)
+			}
+
+			err := store.Claim(delivery.Queue, eventId, lease)
+			if errors.Is(err, ErrProcessed) {
+				log.Debug("Skipping processed event", "queue", delivery.Queue, "event_id", eventId)
+				return nil
+			}
+			if err != nil {
+				return rabbitmq.Retryable(err)
+			}
+
+			if err := next(ctx, delivery); err != nil {
+				if releaseErr := store.Release(delivery.Queue, eventId); releaseErr != nil {
+					log.Error("Failed to release event", "queue", delivery.Queue, "event_id", eventId, "error", releaseErr)
+				}
+				return err
+			}
+
+			// The event was handled, so it is acknowledged even if it cannot
+			// be recorded; its claim keeps redeliveries away until the lease
+			// expires.
+			if err := store.Complete(delivery.Queue, eventId); err != nil {
+				log.Error("Failed to record processed event", "queue", delivery.Queue, "event_id", eventId, "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+func eventIdOf(body []byte) string {
+	var envelope struct {
+		EventId string `json:"event_id"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return ""
+	}
+	return envelope.EventId
+}
@@ -0,0 +1,76 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+)
+
+func TestMiddlewareHandlesEventsOnce(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "processed.json")
+	store, err := NewFileStore(path, clk)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	calls := 0
+	fail := true
+	handler := func(context.Context, *rabbitmq.Delivery) error {
+		calls++
+		if fail {
+			fail = false
+			return errors.New("temporary failure")
+		}
+		return nil
+	}
+	middleware := Middleware(store, time.Minute, logger.NewLogger())
+
+	delivery := &rabbitmq.Delivery{Queue: "notifications", Body: []byte(`{"event_id":"e1","data":{}}`)}
+	if err := middleware(handler)(context.Background(), delivery); err == nil {
+		t.Fatal("Expected the handler error")
+	}
+	for range 2 {
+		if err := middleware(handler)(context.Background(), delivery); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected the handler to run until it succeeded once, got %d calls", calls)
+	}
+
+	// A restarted consumer skips the events processed before
+	reopened, err := NewFileStore(path, clk)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	if err := Middleware(reopened, time.Minute, logger.NewLogger())(handler)(context.Background(), delivery); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the processed event to be skipped after a restart, got %d calls", calls)
+	}
+}
+
+func TestMiddlewareRetriesClaimedEvents(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "processed.json"), clock.NewFake(time.Now()))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	store.Claim("notifications", "e1", time.Minute)
+
+	handler := Middleware(store, time.Minute, logger.NewLogger())(func(context.Context, *rabbitmq.Delivery) error {
+		t.Error("Expected the handler not to run for a claimed event")
+		return nil
+	})
+	delivery := &rabbitmq.Delivery{Queue: "notifications", Body: []byte(`{"event_id":"e1"}`)}
+	if err := handler(context.Background(), delivery); !errors.Is(err, ErrInProgress) || rabbitmq.IsPermanent(err) {
+		t.Errorf("Expected a retryable ErrInProgress, got %v", err)
+	}
+}
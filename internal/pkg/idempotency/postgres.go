@@ -0,0 +1,103 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+)
+
+const (
+	statusProcessing = "processing"
+	statusProcessed  = "processed"
+)
+
+type processedEvent struct {
+	Consumer    string `gorm:"primaryKey"`
+	EventId     string `gorm:"primaryKey"`
+	Status      string
+	LockedUntil time.Time
+	ProcessedAt *time.Time
+}
+
+func (processedEvent) TableName() string {
+	return "messaging.t_processed_event"
+}
+
+// PostgresStore records processed events in the messaging.t_processed_event
+// table, shared by every instance of a consumer.
+type PostgresStore struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+func NewPostgresStore(db *gorm.DB, clk clock.Clock) *PostgresStore {
+	return &PostgresStore{
+		db:    db,
+		clock: clk,
+	}
+}
+
+// Claim inserts a claim, or takes over a claim whose lease expired, in one
+// statement so that concurrent deliveries cannot both claim the event.
+func (s *PostgresStore) Claim(consumer, eventId string, lease time.Duration) error {
+	now := s.clock.Now()
+	claim := &processedEvent{
+		Consumer:    consumer,
+		EventId:     eventId,
+		Status:      statusProcessing,
+		LockedUntil: now.Add(lease),
+	}
+
+	result := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "consumer"}, {Name: "event_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"locked_until"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "t_processed_event.status = ? AND t_processed_event.locked_until < ?", Vars: []any{statusProcessing, now}},
+		}},
+	}).Create(claim)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var existing processedEvent
+	err := s.db.Where("consumer = ? AND event_id = ?", consumer, eventId).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The claim was released in the meantime
+		return ErrInProgress
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Status == statusProcessed {
+		return ErrProcessed
+	}
+	return ErrInProgress
+}
+
+func (s *PostgresStore) Complete(consumer, eventId string) error {
+	return s.db.Model(&processedEvent{}).
+		Where("consumer = ? AND event_id = ?", consumer, eventId).
+		Updates(map[string]any{"status": statusProcessed, "processed_at": s.clock.Now()}).Error
+}
+
+func (s *PostgresStore) Release(consumer, eventId string) error {
+	return s.db.
+		Where("consumer = ? AND event_id = ? AND status = ?", consumer, eventId, statusProcessing).
+		Delete(&processedEvent{}).Error
+}
+
+// Purge also deletes claims whose lease expired before the given time, which
+// belong to deliveries that were never completed or released.
+func (s *PostgresStore) Purge(before time.Time) (int64, error) {
+	result := s.db.
+		Where("(status = ? AND processed_at < ?) OR (status = ? AND locked_until < ?)", statusProcessed, before, statusProcessing, before).
+		Delete(&processedEvent{})
+	return result.RowsAffected, result.Error
+}
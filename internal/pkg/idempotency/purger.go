@@ -0,0 +1,72 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+)
+
+// Purger forgets processed events once they are older than the retention
+// window. Redeliveries of an event after that are handled again, so the
+// window must outlast the retry delays of the consumers using the store.
+type Purger struct {
+	store     Store
+	retention time.Duration
+	interval  time.Duration
+	clock     clock.Clock
+	log       logger.Logger
+	stop      chan struct{}
+	done      sync.WaitGroup
+}
+
+func NewPurger(store Store, retention, interval time.Duration, clk clock.Clock, log logger.Logger) *Purger {
+	return &Purger{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		clock:     clk,
+		log:       log,
+		stop:      make(chan struct{}),
+	}
+}
+
+func (p *Purger) Start() {
+	p.log.Info("Starting processed event purger", "retention", p.retention.String(), "interval", p.interval.String())
+
+	p.done.Add(1)
+	go func() {
+		defer p.done.Done()
+
+		for {
+			if _, err := p.Purge(); err != nil {
+				p.log.Error("Failed to purge processed events", "error", err)
+			}
+
+			select {
+			case <-p.clock.After(p.interval):
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (p *Purger) Stop() {
+	close(p.stop)
+	p.done.Wait()
+}
+
+// Purge forgets the events processed before the retention window and
+// returns how many were forgotten.
+func (p *Purger) Purge() (int64, error) {
+	purged, err := p.store.Purge(p.clock.Now().Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		p.log.Info("Purged processed events", "count", purged)
+	}
+	return purged, nil
+}
@@ -0,0 +1,33 @@
+// Package idempotency makes message handlers run once per event, recording
+// the events each consumer processed in a Store.
+package idempotency
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrProcessed means the consumer already processed the event
+	ErrProcessed = errors.New("event was already processed")
+	// ErrInProgress means another delivery of the event is being handled
+	ErrInProgress = errors.New("event is being processed")
+)
+
+// Store records the events processed by each consumer. A consumer claims an
+// event before handling it and completes the claim once handled. Claims that
+// are neither completed nor released expire after their lease, so that an
+// event whose handler crashed is handled again.
+type Store interface {
+	// Claim reserves an event for a consumer for the duration of lease. It
+	// fails with ErrProcessed or ErrInProgress when the event is not
+	// available.
+	Claim(consumer, eventId string, lease time.Duration) error
+	// Complete records a claimed event as processed.
+	Complete(consumer, eventId string) error
+	// Release gives up a claim, so the event can be handled again.
+	Release(consumer, eventId string) error
+	// Purge forgets the events processed before the given time and returns
+	// how many were forgotten.
+	Purge(before time.Time) (int64, error)
+}
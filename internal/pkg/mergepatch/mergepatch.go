@@ -0,0 +1,54 @@
+// Package mergepatch implements JSON Merge Patch as defined in RFC 7396.
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ContentType is the media type of a merge patch document.
+const ContentType = "application/merge-patch+json"
+
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// Apply applies patch to the JSON document target and returns the result.
+// Object members in the patch replace those of the target recursively, a
+// null member removes the member from the target, and any other patch value
+// replaces the target as a whole.
+func Apply(target []byte, patch []byte) ([]byte, error) {
+	var patchValue any
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	var targetValue any
+	if len(target) > 0 {
+		if err := json.Unmarshal(target, &targetValue); err != nil {
+			return nil, fmt.Errorf("invalid merge patch target: %w", err)
+		}
+	}
+
+	return json.Marshal(merge(targetValue, patchValue))
+}
+
+func merge(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any, len(patchObject))
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = merge(targetObject[name], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,54 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// The cases are the examples from RFC 7396, Appendix A.
+func TestApplyRFC7396Examples(t *testing.T) {
+	tests := []struct {
+		target string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		result, err := Apply([]byte(test.target), []byte(test.patch))
+		if err != nil {
+			t.Errorf("Expected no error applying %s to %s, got %v", test.patch, test.target, err)
+			continue
+		}
+
+		var got, want any
+		json.Unmarshal(result, &got)
+		json.Unmarshal([]byte(test.result), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Applying %s to %s: expected %s, got %s", test.patch, test.target, test.result, result)
+		}
+	}
+}
+
+func TestApplyRejectsInvalidPatch(t *testing.T) {
+	if _, err := Apply([]byte(`{}`), []byte(`{"a":`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("Expected ErrInvalidPatch, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONMap maps a JSONB column to a Go map. Values keep their JSON types:
+// strings, float64 numbers, booleans, nested maps and slices.
+type JSONMap map[string]any
+
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (m *JSONMap) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSONMap", value)
+	}
+
+	return json.Unmarshal(data, m)
+}
+
+func (JSONMap) GormDataType() string {
+	return "jsonb"
+}
@@ -0,0 +1,156 @@
+// Package promotions evaluates coupon promotions. cart-service uses it to show
+// the discounts of a cart and order-service to charge them, so that both
+// compute the same amounts from the stored rules.
+package promotions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCoupon means a coupon does not exist or cannot be used on the
+// items it was applied to
+var ErrInvalidCoupon = errors.New("invalid coupon")
+
+// Invalid returns an ErrInvalidCoupon with the reason the coupon was refused.
+func Invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCoupon, fmt.Sprintf(format, args...))
+}
+
+type Type string
+
+const (
+	TypePercentage Type = "percentage"
+	TypeFixed      Type = "fixed"
+	TypeBuyXGetY   Type = "buy_x_get_y"
+)
+
+// Promotion is a discount rule that is opted into with a coupon code.
+// Category and ProductCode narrow the items the rule applies to; when both
+// are empty the rule applies to every item.
+type Promotion struct {
+	ID             string     `gorm:"primaryKey;column:id;default:gen_random_uuid()"`
+	Code           string     `gorm:"column:code"`
+	Description    string     `gorm:"column:description"`
+	Type           Type       `gorm:"column:type"`
+	Value          float64    `gorm:"column:value"`
+	Category       string     `gorm:"column:category"`
+	ProductCode    string     `gorm:"column:product_code"`
+	BuyQuantity    int        `gorm:"column:buy_quantity"`
+	GetQuantity    int        `gorm:"column:get_quantity"`
+	MinSpend       float64    `gorm:"column:min_spend"`
+	MaxUsesPerUser int        `gorm:"column:max_uses_per_user"`
+	StartsAt       *time.Time `gorm:"column:starts_at"`
+	ExpiresAt      *time.Time `gorm:"column:expires_at"`
+	Active         bool       `gorm:"column:active"`
+}
+
+func (Promotion) TableName() string {
+	return "promotions.t_promotion"
+}
+
+// Item is a line a promotion is evaluated against.
+type Item struct {
+	ProductCode string
+	Category    string
+	Quantity    int
+	Price       float64
+}
+
+// Validate checks that a promotion can currently be used on the items by a
+// user who already redeemed it used times.
+func Validate(promotion *Promotion, items []Item, used int, now time.Time) error {
+	if !promotion.Active {
+		return Invalid("coupon %s is not active", promotion.Code)
+	}
+
+	if promotion.StartsAt != nil && now.Before(*promotion.StartsAt) {
+		return Invalid("coupon %s is not valid yet", promotion.Code)
+	}
+
+	if promotion.ExpiresAt != nil && !now.Before(*promotion.ExpiresAt) {
+		return Invalid("coupon %s has expired", promotion.Code)
+	}
+
+	if subtotal := Subtotal(items); subtotal < promotion.MinSpend {
+		return Invalid("coupon %s requires a minimum spend of %.2f", promotion.Code, promotion.MinSpend)
+	}
+
+	if promotion.MaxUsesPerUser > 0 && used >= promotion.MaxUsesPerUser {
+		return Invalid("coupon %s usage limit reached", promotion.Code)
+	}
+
+	if Discount(promotion, items) <= 0 {
+		return Invalid("coupon %s does not apply to any item", promotion.Code)
+	}
+
+	return nil
+}
+
+// Discount returns the amount a promotion takes off the items it applies to.
+// It never exceeds the subtotal of those items.
+func Discount(promotion *Promotion, items []Item) float64 {
+	eligible := eligibleItems(promotion, items)
+	eligibleSubtotal := Subtotal(eligible)
+
+	var discount float64
+	switch promotion.Type {
+	case TypePercentage:
+		discount = eligibleSubtotal * promotion.Value / 100
+
+	case TypeFixed:
+		discount = promotion.Value
+
+	case TypeBuyXGetY:
+		// For every BuyQuantity+GetQuantity units, the GetQuantity cheapest are free
+		groupSize := promotion.BuyQuantity + promotion.GetQuantity
+		if promotion.GetQuantity <= 0 || groupSize <= 0 {
+			return 0
+		}
+
+		var unitPrices []float64
+		for _, item := range eligible {
+			for range item.Quantity {
+				unitPrices = append(unitPrices, item.Price)
+			}
+		}
+		sort.Float64s(unitPrices)
+
+		freeUnits := len(unitPrices) / groupSize * promotion.GetQuantity
+		for _, price := range unitPrices[:freeUnits] {
+			discount += price
+		}
+	}
+
+	return RoundAmount(math.Max(0, math.Min(discount, eligibleSubtotal)))
+}
+
+func eligibleItems(promotion *Promotion, items []Item) []Item {
+	eligible := make([]Item, 0, len(items))
+	for _, item := range items {
+		if promotion.ProductCode != "" && item.ProductCode != promotion.ProductCode {
+			continue
+		}
+		if promotion.Category != "" && !strings.EqualFold(item.Category, promotion.Category) {
+			continue
+		}
+		eligible = append(eligible, item)
+	}
+	return eligible
+}
+
+func Subtotal(items []Item) float64 {
+	var subtotal float64
+	for _, item := range items {
+		subtotal += item.Price * float64(item.Quantity)
+	}
+	return RoundAmount(subtotal)
+}
+
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,25 @@
+package rabbitmq
+
+import "context"
+
+// Broker declares topology, publishes and consumes messages. It is
+// implemented by RabbitMQClient and, for tests, by InMemoryBroker.
+type Broker interface {
+	DeclareExchange(name, kind string) error
+	DeclareQueue(name string) error
+	DeclareTemporaryQueue(name string) error
+	BindQueue(queueName, exchange, routingKey string) error
+	DeclareRetryQueues(queue string, policy RetryPolicy) error
+
+	PublishMessage(exchange, routingKey string, message interface{}) error
+	PublishMandatory(exchange, routingKey string, message interface{}) error
+	PublishBatch(messages []Message) error
+
+	Consume(queueName string, handler MessageHandler) error
+	ConsumeWithOptions(ctx context.Context, queueName string, handler DeliveryHandler, options ConsumeOptions) (*Subscription, error)
+}
+
+var (
+	_ Broker = (*RabbitMQClient)(nil)
+	_ Broker = (*InMemoryBroker)(nil)
+)
@@ -1,9 +1,9 @@
 package rabbitmq
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dinosgnk/agora-project/internal/pkg/config"
@@ -11,18 +11,72 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var ErrNotConnected = errors.New("not connected to RabbitMQ")
+
+// RabbitMQClient holds a connection and channel to RabbitMQ that are
+// re-established when the broker closes them. Exchanges, queues and bindings
+// declared through the client are declared again after every reconnection
+// and consumers resume on the new channel.
 type RabbitMQClient struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
+	url            string
+	initialDelay   time.Duration
+	maxDelay       time.Duration
+	confirms       bool
+	confirmTimeout time.Duration
+	log            logger.Logger
+
+	mu         sync.RWMutex
+	conn       *amqp.Connection
+	channel    *amqp.Channel
+	publishing *confirmChannel
+	// closed receives a value when the connection or any of its channels
+	// closes unexpectedly
+	closed    chan *amqp.Error
+	topology  []declaration
+	consumers []*consumer
+	// retryPolicies holds the retry policies of queues by name
+	retryPolicies map[string]RetryPolicy
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// declaration is a piece of topology to restore after reconnecting. Declaring
+// the same key again replaces the earlier declaration in place.
+type declaration struct {
+	key     string
+	declare func(channel *amqp.Channel) error
 }
 
 func NewRabbitMQClient(log logger.Logger) (*RabbitMQClient, error) {
 	cfg := config.LoadConfig[RabbitMQConfig](log)
 
-	connectionString := fmt.Sprintf("amqp://%s:%s@%s:%s/",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port)
+	c := &RabbitMQClient{
+		url: fmt.Sprintf("amqp://%s:%s@%s:%s/",
+			cfg.User, cfg.Password, cfg.Host, cfg.Port),
+		initialDelay:   cfg.ReconnectInitialDelay,
+		maxDelay:       cfg.ReconnectMaxDelay,
+		confirms:       cfg.PublisherConfirms,
+		confirmTimeout: cfg.ConfirmTimeout,
+		log:            log,
+		done:           make(chan struct{}),
+	}
 
-	conn, err := amqp.Dial(connectionString)
+	closed, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	connectionUp.Set(1)
+
+	go c.supervise(closed)
+	return c, nil
+}
+
+// connect dials the broker and opens a channel, and a separate channel in
+// confirm mode for publishing when publisher confirms are enabled. The
+// returned channel receives a value once any of them closes.
+func (c *RabbitMQClient) connect() (<-chan *amqp.Error, error) {
+	conn, err := amqp.Dial(c.url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
@@ -33,60 +87,210 @@ func NewRabbitMQClient(log logger.Logger) (*RabbitMQClient, error) {
 		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
 
-	return &RabbitMQClient{
-		conn:    conn,
-		channel: channel,
-	}, nil
+	closed := make(chan *amqp.Error, 1)
+	conn.NotifyClose(forward(closed))
+	channel.NotifyClose(forward(closed))
+
+	var publishing *confirmChannel
+	if c.confirms {
+		publishing, err = openConfirmChannel(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to open publishing channel: %w", err)
+		}
+		publishing.channel.NotifyClose(forward(closed))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		conn.Close()
+		return nil, ErrNotConnected
+	default:
+	}
+
+	c.conn = conn
+	c.channel = channel
+	c.publishing = publishing
+	c.closed = closed
+	return closed, nil
 }
 
-func (c *RabbitMQClient) DeclareExchange(name, kind string) error {
-	return c.channel.ExchangeDeclare(
-		name,
-		kind,
-		true,  // durable
-		false, // auto-deleted
-		false, // internal
-		false, // no-wait
-		nil,   // arguments
-	)
+// forward returns a close notification channel for the amqp library that
+// passes its value on to closed, unless closed already holds one. The library
+// closes the returned channel without a value on a graceful shutdown.
+func forward(closed chan<- *amqp.Error) chan *amqp.Error {
+	notify := make(chan *amqp.Error, 1)
+	go func() {
+		if err, ok := <-notify; ok {
+			select {
+			case closed <- err:
+			default:
+			}
+		}
+	}()
+	return notify
 }
 
-func (c *RabbitMQClient) PublishMessage(exchange, routingKey string, message interface{}) error {
-	body, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	err = c.channel.PublishWithContext(
-		ctx,
-		exchange,
-		routingKey,
-		false, // mandatory
-		false, // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			Body:         body,
-			DeliveryMode: amqp.Persistent,
-			Timestamp:    time.Now(),
-		},
-	)
+// supervise waits for the connection or channel to close unexpectedly and
+// reconnects until it succeeds or the client is closed.
+func (c *RabbitMQClient) supervise(closed <-chan *amqp.Error) {
+	for {
+		var reason *amqp.Error
+		select {
+		case <-c.done:
+			return
+		case reason = <-closed:
+		}
 
-	if err != nil {
-		return fmt.Errorf("failed to publish message: %w", err)
+		c.mu.Lock()
+		select {
+		case <-c.done:
+			c.mu.Unlock()
+			return
+		default:
+		}
+		c.conn.Close()
+		c.conn = nil
+		c.channel = nil
+		c.publishing = nil
+		c.mu.Unlock()
+
+		connectionUp.Set(0)
+		connectionLostTotal.Inc()
+		c.log.Warn("RabbitMQ connection lost", "error", reason)
+
+		var ok bool
+		if closed, ok = c.reconnect(); !ok {
+			return
+		}
 	}
+}
+
+// reconnect dials the broker with exponential backoff and restores the
+// topology and consumers. It returns false when the client is closed first.
+func (c *RabbitMQClient) reconnect() (<-chan *amqp.Error, bool) {
+	for attempt := 1; ; attempt++ {
+		delay := backoffDelay(attempt, c.initialDelay, c.maxDelay)
+		select {
+		case <-c.done:
+			return nil, false
+		case <-time.After(delay):
+		}
 
+		closed, err := c.connect()
+		if err == nil {
+			err = c.restore()
+		}
+		if err != nil {
+			reconnectAttemptsTotal.WithLabelValues("failure").Inc()
+			c.log.Warn("Failed to reconnect to RabbitMQ", "attempt", attempt, "retry_in", backoffDelay(attempt+1, c.initialDelay, c.maxDelay).String(), "error", err)
+			c.mu.Lock()
+			if c.conn != nil {
+				c.conn.Close()
+			}
+			c.conn = nil
+			c.channel = nil
+			c.publishing = nil
+			c.mu.Unlock()
+			continue
+		}
+
+		reconnectAttemptsTotal.WithLabelValues("success").Inc()
+		connectionUp.Set(1)
+		c.log.Info("Reconnected to RabbitMQ", "attempts", attempt)
+		return closed, true
+	}
+}
+
+// restore declares the recorded topology on the new channel and restarts the
+// consumers.
+func (c *RabbitMQClient) restore() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, d := range c.topology {
+		if err := d.declare(c.channel); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", d.key, err)
+		}
+	}
+
+	for _, consumer := range c.consumers {
+		if err := consumer.start(c.conn, c.closed); err != nil {
+			return fmt.Errorf("failed to resume consumer on %s: %w", consumer.queue, err)
+		}
+	}
+	return nil
+}
+
+// backoffDelay returns the delay before the given reconnection attempt,
+// starting at initial and doubling up to max.
+func backoffDelay(attempt int, initial time.Duration, max time.Duration) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
+
+// declare applies a declaration to the current channel and records it so that
+// it is restored after reconnecting.
+func (c *RabbitMQClient) declare(key string, declare func(channel *amqp.Channel) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.channel == nil {
+		return ErrNotConnected
+	}
+	if err := declare(c.channel); err != nil {
+		return err
+	}
+
+	for i, d := range c.topology {
+		if d.key == key {
+			c.topology[i].declare = declare
+			return nil
+		}
+	}
+	c.topology = append(c.topology, declaration{key: key, declare: declare})
 	return nil
 }
 
+func (c *RabbitMQClient) DeclareExchange(name, kind string) error {
+	return c.declare("exchange "+name, func(channel *amqp.Channel) error {
+		return channel.ExchangeDeclare(
+			name,
+			kind,
+			true,  // durable
+			false, // auto-deleted
+			false, // internal
+			false, // no-wait
+			nil,   // arguments
+		)
+	})
+}
+
+// Close stops reconnecting and closes the channel and connection.
 func (c *RabbitMQClient) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	connectionUp.Set(0)
+	c.publishing = nil
 	if c.channel != nil {
 		c.channel.Close()
+		c.channel = nil
 	}
 	if c.conn != nil {
-		return c.conn.Close()
+		err := c.conn.Close()
+		c.conn = nil
+		return err
 	}
 	return nil
 }
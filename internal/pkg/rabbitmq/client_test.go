@@ -0,0 +1,36 @@
+package rabbitmq
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelayDoublesUpToMax(t *testing.T) {
+	initial := 500 * time.Millisecond
+	max := 5 * time.Second
+
+	expected := []time.Duration{
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}
+	for i, want := range expected {
+		if got := backoffDelay(i+1, initial, max); got != want {
+			t.Errorf("Expected a delay of %v before attempt %d, got %v", want, i+1, got)
+		}
+	}
+}
+
+func TestDeclareRequiresConnection(t *testing.T) {
+	c := &RabbitMQClient{done: make(chan struct{})}
+
+	if err := c.DeclareExchange("orders", "topic"); err != ErrNotConnected {
+		t.Fatalf("Expected ErrNotConnected, got %v", err)
+	}
+	if len(c.topology) != 0 {
+		t.Fatalf("Expected failed declarations not to be recorded, got %d", len(c.topology))
+	}
+}
@@ -1,8 +1,21 @@
 package rabbitmq
 
+import "time"
+
 type RabbitMQConfig struct {
 	Host     string `env:"RABBITMQ_HOST" envDefault:"localhost"`
 	Port     string `env:"RABBITMQ_PORT" envDefault:"5672"`
 	User     string `env:"RABBITMQ_USER" envDefault:"guest"`
 	Password string `env:"RABBITMQ_PASS" envDefault:"guest"`
+
+	// The delay between reconnection attempts starts at ReconnectInitialDelay
+	// and doubles after every failed attempt up to ReconnectMaxDelay
+	ReconnectInitialDelay time.Duration `env:"RABBITMQ_RECONNECT_INITIAL_DELAY" envDefault:"500ms"`
+	ReconnectMaxDelay     time.Duration `env:"RABBITMQ_RECONNECT_MAX_DELAY" envDefault:"30s"`
+
+	// With PublisherConfirms, messages are published on a channel in
+	// confirm mode and publishing waits up to ConfirmTimeout for the broker
+	// to confirm them
+	PublisherConfirms bool          `env:"RABBITMQ_PUBLISHER_CONFIRMS" envDefault:"true"`
+	ConfirmTimeout    time.Duration `env:"RABBITMQ_CONFIRM_TIMEOUT" envDefault:"5s"`
 }
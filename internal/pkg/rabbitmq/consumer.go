@@ -1,37 +1,205 @@
 package rabbitmq
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var ErrHandlerTimeout = errors.New("message handler timed out")
+
 type MessageHandler func([]byte) error
 
+// Delivery is a consumed message. Exchange and RoutingKey are those the
+// message was first published with, also for retried and replayed copies.
+type Delivery struct {
+	Queue         string
+	Exchange      string
+	RoutingKey    string
+	MessageId     string
+	CorrelationId string
+	Headers       amqp.Table
+	Timestamp     time.Time
+	Body          []byte
+}
+
+// DeliveryHandler handles a delivery. ctx expires after the consumer's
+// handler timeout.
+type DeliveryHandler func(ctx context.Context, delivery *Delivery) error
+
+// ConsumeOptions tune a consumer. The zero value handles one message at a
+// time with no prefetch limit and no handler timeout.
+type ConsumeOptions struct {
+	// Prefetch limits the unacknowledged messages delivered to the consumer
+	Prefetch int
+	// Workers is the number of messages handled concurrently
+	Workers int
+	// HandlerTimeout fails a delivery whose handler has not returned in time.
+	// The handler keeps running, so it should observe its context.
+	HandlerTimeout time.Duration
+	// ShutdownTimeout bounds how long a stopping consumer waits for handlers
+	// that kept running after their timeout. Zero waits up to HandlerTimeout.
+	ShutdownTimeout time.Duration
+}
+
+// Subscription is a running consumer.
+type Subscription struct {
+	stopped chan struct{}
+}
+
+// Wait blocks until the consumer stopped after its context was cancelled.
+func (s *Subscription) Wait() {
+	<-s.stopped
+}
+
+// consumer is a registered subscription to a queue. It consumes on a channel
+// of its own that is opened again after every reconnection.
+type consumer struct {
+	queue   string
+	handler DeliveryHandler
+	options ConsumeOptions
+	// retry is nil for queues without retry queues, whose failed messages
+	// are dropped
+	retry *RetryPolicy
+	// publish sends the copies of failed messages to the retry queues
+	publish publishFunc
+	log     logger.Logger
+
+	mu      sync.Mutex
+	channel *amqp.Channel
+	tag     string
+	workers sync.WaitGroup
+	// handlers tracks the handler goroutines of deliveries with a handler
+	// timeout, which outlive their worker when they time out
+	handlers sync.WaitGroup
+}
+
 func (c *RabbitMQClient) DeclareQueue(name string) error {
-	_, err := c.channel.QueueDeclare(
-		name,
-		true,  // durable
-		false, // delete when unused
-		false, // exclusive
-		false, // no-wait
-		nil,   // arguments
-	)
-	return err
+	return c.declare("queue "+name, func(channel *amqp.Channel) error {
+		_, err := channel.QueueDeclare(
+			name,
+			true,  // durable
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			nil,   // arguments
+		)
+		return err
+	})
+}
+
+// DeclareTemporaryQueue declares a non-durable queue that is deleted when the
+// connection that declared it closes. Used for per-instance subscriptions.
+func (c *RabbitMQClient) DeclareTemporaryQueue(name string) error {
+	return c.declare("queue "+name, func(channel *amqp.Channel) error {
+		_, err := channel.QueueDeclare(
+			name,
+			false, // durable
+			true,  // delete when unused
+			true,  // exclusive
+			false, // no-wait
+			nil,   // arguments
+		)
+		return err
+	})
 }
 
 func (c *RabbitMQClient) BindQueue(queueName, exchange, routingKey string) error {
-	return c.channel.QueueBind(
-		queueName,
-		routingKey,
-		exchange,
-		false, // no-wait
-		nil,   // arguments
-	)
+	key := fmt.Sprintf("binding %s -> %s (%s)", exchange, queueName, routingKey)
+	return c.declare(key, func(channel *amqp.Channel) error {
+		return channel.QueueBind(
+			queueName,
+			routingKey,
+			exchange,
+			false, // no-wait
+			nil,   // arguments
+		)
+	})
 }
 
+// Consume delivers the message bodies of a queue to handler one at a time
+// until the client is closed.
 func (c *RabbitMQClient) Consume(queueName string, handler MessageHandler) error {
-	msgs, err := c.channel.Consume(
-		queueName,
-		"",    // consumer
+	_, err := c.ConsumeWithOptions(context.Background(), queueName, func(_ context.Context, delivery *Delivery) error {
+		return handler(delivery.Body)
+	}, ConsumeOptions{})
+	return err
+}
+
+// ConsumeWithOptions delivers the messages of a queue to handler, resuming
+// after reconnections, until ctx is cancelled. The consumer then stops
+// receiving deliveries, waits for the handlers in flight and closes its
+// channel, which returns prefetched messages to the queue. Failed messages
+// are retried when retry queues were declared for the queue, and dropped
+// otherwise.
+func (c *RabbitMQClient) ConsumeWithOptions(ctx context.Context, queueName string, handler DeliveryHandler, options ConsumeOptions) (*Subscription, error) {
+	options.Workers = max(options.Workers, 1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil, fmt.Errorf("failed to register consumer: %w", ErrNotConnected)
+	}
+
+	consumer := &consumer{queue: queueName, handler: handler, options: options, publish: c.publishAndWait, log: c.log}
+	if policy, ok := c.retryPolicies[queueName]; ok {
+		consumer.retry = &policy
+	}
+	if err := consumer.start(c.conn, c.closed); err != nil {
+		return nil, fmt.Errorf("failed to register consumer: %w", err)
+	}
+	c.consumers = append(c.consumers, consumer)
+
+	subscription := &Subscription{stopped: make(chan struct{})}
+	go func() {
+		<-ctx.Done()
+		c.removeConsumer(consumer)
+		consumer.stop()
+		close(subscription.stopped)
+	}()
+	return subscription, nil
+}
+
+func (c *RabbitMQClient) removeConsumer(stopped *consumer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.consumers = slices.DeleteFunc(c.consumers, func(registered *consumer) bool {
+		return registered == stopped
+	})
+}
+
+// start subscribes to the queue on a new channel of conn. An unexpected close
+// of the channel is reported on closed, and the workers end when the
+// channel closes.
+func (c *consumer) start(conn *amqp.Connection, closed chan<- *amqp.Error) error {
+	channel, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+
+	if c.options.Prefetch > 0 {
+		if err := channel.Qos(c.options.Prefetch, 0, false); err != nil {
+			channel.Close()
+			return err
+		}
+	}
+
+	tag, err := newMessageId()
+	if err != nil {
+		channel.Close()
+		return err
+	}
+
+	msgs, err := channel.Consume(
+		c.queue,
+		tag,   // consumer
 		false, // auto-ack (set to false for manual acknowledgment)
 		false, // exclusive
 		false, // no-local
@@ -39,20 +207,140 @@ func (c *RabbitMQClient) Consume(queueName string, handler MessageHandler) error
 		nil,   // args
 	)
 	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
+		channel.Close()
+		return err
 	}
+	channel.NotifyClose(forward(closed))
 
-	go func() {
-		for msg := range msgs {
-			if err := handler(msg.Body); err != nil {
-				// Log error but acknowledge to avoid infinite redelivery
-				fmt.Printf("Error handling message: %v\n", err)
-				msg.Nack(false, false) // Don't requeue
-			} else {
-				msg.Ack(false)
+	c.mu.Lock()
+	c.channel = channel
+	c.tag = tag
+	c.mu.Unlock()
+
+	for range c.options.Workers {
+		c.workers.Add(1)
+		go func() {
+			defer c.workers.Done()
+			for msg := range msgs {
+				c.handle(msg)
 			}
-		}
-	}()
+		}()
+	}
 
 	return nil
 }
+
+// stop cancels the subscription so that the broker sends no more messages,
+// waits for the workers to finish their current message and for timed out
+// handlers, and closes the channel.
+func (c *consumer) stop() {
+	c.mu.Lock()
+	channel, tag := c.channel, c.tag
+	c.mu.Unlock()
+
+	if channel != nil {
+		channel.Cancel(tag, false)
+	}
+	c.workers.Wait()
+	c.waitForHandlers()
+	if channel != nil {
+		channel.Close()
+	}
+	c.log.Info("Consumer stopped", "queue", c.queue)
+}
+
+// waitForHandlers waits for the handlers that kept running after their
+// timeout, up to the shutdown timeout.
+func (c *consumer) waitForHandlers() {
+	if c.options.HandlerTimeout <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.handlers.Wait()
+		close(done)
+	}()
+
+	timeout := c.options.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = c.options.HandlerTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		c.log.Warn("Consumer stopped with handlers still running", "queue", c.queue, "shutdown_timeout", timeout.String())
+	}
+}
+
+func (c *consumer) handle(msg amqp.Delivery) {
+	// Retried messages keep their original timestamp, so only first
+	// deliveries measure how far the consumer is behind
+	if !msg.Timestamp.IsZero() && attempts(msg.Headers) == 0 {
+		consumerLag.WithLabelValues(c.queue).Observe(float64(time.Since(msg.Timestamp).Nanoseconds()) / 1e6)
+	}
+
+	exchange, routingKey := originalRoute(msg.Headers, msg.Exchange, msg.RoutingKey)
+	err := c.run(&Delivery{
+		Queue:         c.queue,
+		Exchange:      exchange,
+		RoutingKey:    routingKey,
+		MessageId:     msg.MessageId,
+		CorrelationId: msg.CorrelationId,
+		Headers:       msg.Headers,
+		Timestamp:     msg.Timestamp,
+		Body:          msg.Body,
+	})
+
+	if err == nil {
+		msg.Ack(false)
+		messagesConsumedTotal.WithLabelValues(c.queue, "ack").Inc()
+		return
+	}
+
+	if c.retry == nil {
+		// Log error but acknowledge to avoid infinite redelivery
+		fmt.Printf("Error handling message: %v\n", err)
+		msg.Nack(false, false) // Don't requeue
+		messagesConsumedTotal.WithLabelValues(c.queue, "nack").Inc()
+		return
+	}
+
+	if retryErr := retry(c.publish, c.log, c.queue, *c.retry, msg, err); retryErr != nil {
+		// Requeue rather than lose a message that could not be retried
+		c.log.Error("Failed to schedule message retry", "queue", c.queue, "message_id", msg.MessageId, "error", retryErr)
+		msg.Nack(false, true)
+		messagesConsumedTotal.WithLabelValues(c.queue, "requeue").Inc()
+		return
+	}
+	msg.Ack(false)
+	messagesConsumedTotal.WithLabelValues(c.queue, "retry").Inc()
+}
+
+// run calls the handler, giving up on it once the handler timeout elapsed.
+func (c *consumer) run(delivery *Delivery) error {
+	if c.options.HandlerTimeout <= 0 {
+		return c.handler(context.Background(), delivery)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.options.HandlerTimeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	c.handlers.Add(1)
+	go func() {
+		defer c.handlers.Done()
+		result <- c.handler(ctx, delivery)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		c.log.Warn("Message handler timed out", "queue", c.queue, "message_id", delivery.MessageId, "timeout", c.options.HandlerTimeout.String())
+		return Retryable(fmt.Errorf("%w after %s", ErrHandlerTimeout, c.options.HandlerTimeout))
+	}
+}
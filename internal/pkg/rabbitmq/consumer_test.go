@@ -0,0 +1,163 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHandlerTimeout(t *testing.T) {
+	c := &consumer{
+		queue: "notifications",
+		handler: func(ctx context.Context, _ *Delivery) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		options: ConsumeOptions{HandlerTimeout: 10 * time.Millisecond},
+		log:     logger.NewLogger(),
+	}
+
+	err := c.run(&Delivery{MessageId: "1"})
+	if !errors.Is(err, ErrHandlerTimeout) {
+		t.Fatalf("Expected ErrHandlerTimeout, got %v", err)
+	}
+	if IsPermanent(err) {
+		t.Errorf("Expected a timed out message to be retried")
+	}
+}
+
+func TestHandlerWithinTimeout(t *testing.T) {
+	handlerErr := Permanent(errors.New("malformed event"))
+	c := &consumer{
+		queue: "notifications",
+		handler: func(ctx context.Context, delivery *Delivery) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Errorf("Expected the handler context to have a deadline")
+			}
+			return handlerErr
+		},
+		options: ConsumeOptions{HandlerTimeout: time.Second},
+		log:     logger.NewLogger(),
+	}
+
+	if err := c.run(&Delivery{MessageId: "1"}); err != handlerErr {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+}
+
+func TestStopWaitsForTimedOutHandlers(t *testing.T) {
+	broker, _ := newTestBroker(t)
+	broker.DeclareQueue("notifications")
+	broker.BindQueue("notifications", "orders", "order.*")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := broker.ConsumeWithOptions(ctx, "notifications", func(context.Context, *Delivery) error {
+		// The handler ignores its context
+		close(started)
+		<-release
+		return nil
+	}, ConsumeOptions{HandlerTimeout: 10 * time.Millisecond, ShutdownTimeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker.PublishMessage("orders", "order.created", nil)
+	<-started
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		subscription.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Expected the consumer to wait for the timed out handler")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the consumer to stop once the handler returned")
+	}
+}
+
+func TestStopGivesUpOnHandlersAfterShutdownTimeout(t *testing.T) {
+	broker, _ := newTestBroker(t)
+	broker.DeclareQueue("notifications")
+	broker.BindQueue("notifications", "orders", "order.*")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := broker.ConsumeWithOptions(ctx, "notifications", func(context.Context, *Delivery) error {
+		close(started)
+		<-release
+		return nil
+	}, ConsumeOptions{HandlerTimeout: 10 * time.Millisecond, ShutdownTimeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker.PublishMessage("orders", "order.created", nil)
+	<-started
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		subscription.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the consumer to stop after the shutdown timeout")
+	}
+}
+
+func TestConsumerMetrics(t *testing.T) {
+	broker, _ := newTestBroker(t)
+	broker.DeclareQueue("metrics")
+	broker.BindQueue("metrics", "orders", "order.#")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := broker.ConsumeWithOptions(ctx, "metrics", func(_ context.Context, delivery *Delivery) error {
+		if delivery.RoutingKey == "order.cancelled" {
+			return errors.New("temporary failure")
+		}
+		return nil
+	}, ConsumeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker.PublishMessage("orders", "order.created", map[string]string{"order_id": "o1"})
+	broker.PublishMessage("orders", "order.created", map[string]string{"order_id": "o2"})
+	broker.PublishMessage("orders", "order.cancelled", map[string]string{"order_id": "o1"})
+	if err := broker.WaitForQueue("metrics", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	subscription.Wait()
+
+	if acked := testutil.ToFloat64(messagesConsumedTotal.WithLabelValues("metrics", "ack")); acked != 2 {
+		t.Errorf("Expected 2 acked messages, got %v", acked)
+	}
+	// Without retry queues failed messages are dropped
+	if nacked := testutil.ToFloat64(messagesConsumedTotal.WithLabelValues("metrics", "nack")); nacked != 1 {
+		t.Errorf("Expected 1 nacked message, got %v", nacked)
+	}
+	if observed := testutil.CollectAndCount(consumerLag, "rabbitmq_consumer_lag_milliseconds"); observed == 0 {
+		t.Error("Expected the consumer lag to be observed")
+	}
+}
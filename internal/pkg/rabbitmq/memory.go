@@ -0,0 +1,555 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrNotFound means an exchange or queue was used before it was declared.
+var ErrNotFound = errors.New("exchange or queue not found")
+
+// InMemoryBroker is an in-process Broker for tests. It routes messages
+// through direct, fanout and topic exchanges, keeps them in their queues until
+// a consumer acknowledges them and dead-letters rejected and expired messages
+// of queues with a dead-letter exchange, which is how retry queues work.
+// Message TTLs run on the broker's clock, so a fake clock drives retries.
+type InMemoryBroker struct {
+	clock clock.Clock
+	log   logger.Logger
+
+	mu            sync.Mutex
+	exchanges     map[string]string
+	bindings      []memoryBinding
+	queues        map[string]*memoryQueue
+	retryPolicies map[string]RetryPolicy
+	nextTag       uint64
+}
+
+type memoryBinding struct {
+	queue      string
+	exchange   string
+	routingKey string
+}
+
+type memoryQueue struct {
+	name       string
+	autoDelete bool
+	// ttl expires messages that waited in the queue for longer, unless zero
+	ttl time.Duration
+	// deadLetter receives the rejected and expired messages of the queue,
+	// which are dropped when it is nil
+	deadLetter *memoryDeadLetter
+
+	ready     []*memoryMessage
+	unacked   map[uint64]*memoryMessage
+	consumers int
+	// available is closed, and replaced, when messages become ready
+	available chan struct{}
+}
+
+type memoryDeadLetter struct {
+	exchange string
+	// routingKey replaces the routing key of dead-lettered messages unless
+	// empty
+	routingKey string
+}
+
+type memoryMessage struct {
+	exchange    string
+	routingKey  string
+	publishing  amqp.Publishing
+	redelivered bool
+}
+
+func NewInMemoryBroker(clk clock.Clock, log logger.Logger) *InMemoryBroker {
+	return &InMemoryBroker{
+		clock: clk,
+		log:   log,
+		// The default exchange routes messages to the queue named by their
+		// routing key
+		exchanges:     map[string]string{"": "direct"},
+		queues:        make(map[string]*memoryQueue),
+		retryPolicies: make(map[string]RetryPolicy),
+	}
+}
+
+func (b *InMemoryBroker) DeclareExchange(name, kind string) error {
+	if kind != "direct" && kind != "fanout" && kind != "topic" {
+		return fmt.Errorf("unsupported exchange kind %q", kind)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if declared, ok := b.exchanges[name]; ok && declared != kind {
+		return fmt.Errorf("exchange %s was declared as %s", name, declared)
+	}
+	b.exchanges[name] = kind
+	return nil
+}
+
+func (b *InMemoryBroker) DeclareQueue(name string) error {
+	b.declareQueue(&memoryQueue{name: name})
+	return nil
+}
+
+// DeclareTemporaryQueue declares a queue that is deleted when its last
+// consumer stops.
+func (b *InMemoryBroker) DeclareTemporaryQueue(name string) error {
+	b.declareQueue(&memoryQueue{name: name, autoDelete: true})
+	return nil
+}
+
+// declareQueue adds a queue unless one of the same name exists.
+func (b *InMemoryBroker) declareQueue(q *memoryQueue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.queues[q.name]; ok {
+		return
+	}
+	q.unacked = make(map[uint64]*memoryMessage)
+	q.available = make(chan struct{})
+	b.queues[q.name] = q
+}
+
+func (b *InMemoryBroker) BindQueue(queueName, exchange, routingKey string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.queues[queueName]; !ok {
+		return fmt.Errorf("%w: queue %s", ErrNotFound, queueName)
+	}
+	if _, ok := b.exchanges[exchange]; !ok {
+		return fmt.Errorf("%w: exchange %s", ErrNotFound, exchange)
+	}
+
+	binding := memoryBinding{queue: queueName, exchange: exchange, routingKey: routingKey}
+	if !slices.Contains(b.bindings, binding) {
+		b.bindings = append(b.bindings, binding)
+	}
+	return nil
+}
+
+// DeclareRetryQueues declares the same delay queues and parking lot as
+// RabbitMQClient.DeclareRetryQueues.
+func (b *InMemoryBroker) DeclareRetryQueues(queue string, policy RetryPolicy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+
+	for _, delay := range policy.Delays {
+		b.declareQueue(&memoryQueue{
+			name:       DelayQueueName(queue, delay),
+			ttl:        delay,
+			deadLetter: &memoryDeadLetter{exchange: "", routingKey: queue},
+		})
+	}
+	b.declareQueue(&memoryQueue{name: ParkingLotName(queue)})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.retryPolicies[queue] = policy
+	return nil
+}
+
+func (b *InMemoryBroker) PublishMessage(exchange, routingKey string, message interface{}) error {
+	return b.publishJSON(exchange, routingKey, message, false)
+}
+
+func (b *InMemoryBroker) PublishMandatory(exchange, routingKey string, message interface{}) error {
+	return b.publishJSON(exchange, routingKey, message, true)
+}
+
+func (b *InMemoryBroker) publishJSON(exchange, routingKey string, message interface{}, mandatory bool) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	messageId, err := newMessageId()
+	if err != nil {
+		return err
+	}
+
+	return b.publish(exchange, routingKey, amqp.Publishing{
+		ContentType:  "application/json",
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    messageId,
+		Timestamp:    b.clock.Now(),
+	}, mandatory)
+}
+
+func (b *InMemoryBroker) PublishBatch(messages []Message) error {
+	failed := make(map[int]error)
+	for i, message := range messages {
+		if err := b.publishJSON(message.Exchange, message.RoutingKey, message.Body, message.Mandatory); err != nil {
+			failed[i] = err
+		}
+	}
+
+	if len(failed) > 0 {
+		return &BatchError{Total: len(messages), Failed: failed}
+	}
+	return nil
+}
+
+// publish routes a message as a publish with confirms would be routed. A
+// mandatory message fails with an *UnroutableError when no queue is bound
+// for it; others are dropped.
+func (b *InMemoryBroker) publish(exchange, routingKey string, publishing amqp.Publishing, mandatory bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.exchanges[exchange]; !ok {
+		return fmt.Errorf("failed to publish message: %w: exchange %s", ErrNotFound, exchange)
+	}
+	if b.route(exchange, routingKey, publishing) == 0 && mandatory {
+		return &UnroutableError{
+			Exchange:   exchange,
+			RoutingKey: routingKey,
+			ReplyCode:  amqp.NoRoute,
+			ReplyText:  "NO_ROUTE",
+		}
+	}
+	return nil
+}
+
+func (b *InMemoryBroker) publishAndWait(exchange, routingKey string, publishing amqp.Publishing) error {
+	return b.publish(exchange, routingKey, publishing, true)
+}
+
+// route adds a message to every queue bound to the exchange for its routing
+// key and returns how many queues it was added to. b.mu must be held.
+func (b *InMemoryBroker) route(exchange, routingKey string, publishing amqp.Publishing) int {
+	var targets []*memoryQueue
+	if exchange == "" {
+		if q, ok := b.queues[routingKey]; ok {
+			targets = append(targets, q)
+		}
+	} else {
+		kind := b.exchanges[exchange]
+		for _, binding := range b.bindings {
+			q := b.queues[binding.queue]
+			if binding.exchange == exchange && bindingMatches(kind, binding.routingKey, routingKey) && !slices.Contains(targets, q) {
+				targets = append(targets, q)
+			}
+		}
+	}
+
+	for _, q := range targets {
+		b.enqueue(q, &memoryMessage{exchange: exchange, routingKey: routingKey, publishing: publishing})
+	}
+	return len(targets)
+}
+
+// enqueue makes a message ready for the consumers of q and starts its TTL.
+// b.mu must be held.
+func (b *InMemoryBroker) enqueue(q *memoryQueue, msg *memoryMessage) {
+	q.ready = append(q.ready, msg)
+	q.signal()
+
+	if q.ttl > 0 {
+		expired := b.clock.After(q.ttl)
+		go func() {
+			<-expired
+			b.expire(q, msg)
+		}()
+	}
+}
+
+func (q *memoryQueue) signal() {
+	close(q.available)
+	q.available = make(chan struct{})
+}
+
+// expire dead-letters a message that is still waiting in q once its TTL
+// elapsed.
+func (b *InMemoryBroker) expire(q *memoryQueue, msg *memoryMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := slices.Index(q.ready, msg)
+	if i < 0 {
+		return
+	}
+	q.ready = slices.Delete(q.ready, i, i+1)
+	b.deadLetter(q, msg, "expired")
+}
+
+// deadLetter republishes a rejected or expired message to the dead-letter
+// exchange of q, recording why as RabbitMQ does. b.mu must be held.
+func (b *InMemoryBroker) deadLetter(q *memoryQueue, msg *memoryMessage, reason string) {
+	if q.deadLetter == nil {
+		return
+	}
+	if _, ok := b.exchanges[q.deadLetter.exchange]; !ok {
+		return
+	}
+
+	headers := amqp.Table{}
+	maps.Copy(headers, msg.publishing.Headers)
+	if _, ok := headers["x-first-death-reason"]; !ok {
+		headers["x-first-death-reason"] = reason
+		headers["x-first-death-queue"] = q.name
+		headers["x-first-death-exchange"] = msg.exchange
+	}
+
+	publishing := msg.publishing
+	publishing.Headers = headers
+	routingKey := q.deadLetter.routingKey
+	if routingKey == "" {
+		routingKey = msg.routingKey
+	}
+	b.route(q.deadLetter.exchange, routingKey, publishing)
+}
+
+// bindingMatches reports whether a binding of an exchange of the given kind
+// matches a routing key.
+func bindingMatches(kind, bindingKey, routingKey string) bool {
+	switch kind {
+	case "fanout":
+		return true
+	case "topic":
+		return topicMatches(strings.Split(bindingKey, "."), strings.Split(routingKey, "."))
+	}
+	return bindingKey == routingKey
+}
+
+// topicMatches matches the words of a routing key against the words of a
+// topic binding, where * matches exactly one word and # zero or more.
+func topicMatches(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if topicMatches(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && topicMatches(pattern[1:], words[1:])
+	}
+	return len(words) > 0 && pattern[0] == words[0] && topicMatches(pattern[1:], words[1:])
+}
+
+func (b *InMemoryBroker) Consume(queueName string, handler MessageHandler) error {
+	_, err := b.ConsumeWithOptions(context.Background(), queueName, func(_ context.Context, delivery *Delivery) error {
+		return handler(delivery.Body)
+	}, ConsumeOptions{})
+	return err
+}
+
+// ConsumeWithOptions handles the messages of a queue as
+// RabbitMQClient.ConsumeWithOptions does, including retries. Each worker
+// takes one message at a time, so Prefetch has no effect.
+func (b *InMemoryBroker) ConsumeWithOptions(ctx context.Context, queueName string, handler DeliveryHandler, options ConsumeOptions) (*Subscription, error) {
+	options.Workers = max(options.Workers, 1)
+
+	b.mu.Lock()
+	q, ok := b.queues[queueName]
+	if !ok {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("failed to register consumer: %w: queue %s", ErrNotFound, queueName)
+	}
+	q.consumers++
+
+	consumer := &consumer{queue: queueName, handler: handler, options: options, publish: b.publishAndWait, log: b.log}
+	if policy, ok := b.retryPolicies[queueName]; ok {
+		consumer.retry = &policy
+	}
+	b.mu.Unlock()
+
+	for range options.Workers {
+		consumer.workers.Add(1)
+		go func() {
+			defer consumer.workers.Done()
+			for {
+				msg, ok := b.next(ctx, q)
+				if !ok {
+					return
+				}
+				consumer.handle(msg)
+			}
+		}()
+	}
+
+	subscription := &Subscription{stopped: make(chan struct{})}
+	go func() {
+		<-ctx.Done()
+		consumer.workers.Wait()
+		consumer.waitForHandlers()
+		b.removeConsumer(q)
+		close(subscription.stopped)
+	}()
+	return subscription, nil
+}
+
+// next waits for a message of q to become ready and delivers it, unless ctx
+// is cancelled first.
+func (b *InMemoryBroker) next(ctx context.Context, q *memoryQueue) (amqp.Delivery, bool) {
+	for {
+		b.mu.Lock()
+		if ctx.Err() != nil {
+			b.mu.Unlock()
+			return amqp.Delivery{}, false
+		}
+
+		if len(q.ready) > 0 {
+			msg := q.ready[0]
+			q.ready = q.ready[1:]
+			b.nextTag++
+			q.unacked[b.nextTag] = msg
+			delivery := amqp.Delivery{
+				Acknowledger:  &memoryAcknowledger{broker: b, queue: q},
+				Headers:       msg.publishing.Headers,
+				ContentType:   msg.publishing.ContentType,
+				DeliveryMode:  msg.publishing.DeliveryMode,
+				CorrelationId: msg.publishing.CorrelationId,
+				MessageId:     msg.publishing.MessageId,
+				Timestamp:     msg.publishing.Timestamp,
+				DeliveryTag:   b.nextTag,
+				Redelivered:   msg.redelivered,
+				Exchange:      msg.exchange,
+				RoutingKey:    msg.routingKey,
+				Body:          msg.publishing.Body,
+			}
+			b.mu.Unlock()
+			return delivery, true
+		}
+
+		available := q.available
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return amqp.Delivery{}, false
+		case <-available:
+		}
+	}
+}
+
+func (b *InMemoryBroker) removeConsumer(q *memoryQueue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q.consumers--
+	if q.autoDelete && q.consumers == 0 && b.queues[q.name] == q {
+		delete(b.queues, q.name)
+		b.bindings = slices.DeleteFunc(b.bindings, func(binding memoryBinding) bool {
+			return binding.queue == q.name
+		})
+	}
+}
+
+// settle acknowledges or rejects a delivered message. Rejected messages are
+// requeued at the front of the queue or dead-lettered.
+func (b *InMemoryBroker) settle(q *memoryQueue, tag uint64, rejected, requeue bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	msg, ok := q.unacked[tag]
+	if !ok {
+		return fmt.Errorf("unknown delivery tag %d", tag)
+	}
+	delete(q.unacked, tag)
+
+	switch {
+	case !rejected:
+	case requeue:
+		msg.redelivered = true
+		q.ready = append([]*memoryMessage{msg}, q.ready...)
+		q.signal()
+	default:
+		b.deadLetter(q, msg, "rejected")
+	}
+	return nil
+}
+
+// memoryAcknowledger settles the deliveries of a queue of an InMemoryBroker.
+// Consumers settle one message at a time, so multiple is ignored.
+type memoryAcknowledger struct {
+	broker *InMemoryBroker
+	queue  *memoryQueue
+}
+
+func (a *memoryAcknowledger) Ack(tag uint64, multiple bool) error {
+	return a.broker.settle(a.queue, tag, false, false)
+}
+
+func (a *memoryAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	return a.broker.settle(a.queue, tag, true, requeue)
+}
+
+func (a *memoryAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.broker.settle(a.queue, tag, true, requeue)
+}
+
+// Messages returns the messages waiting in a queue without removing them.
+func (b *InMemoryBroker) Messages(queueName string) []Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil
+	}
+
+	deliveries := make([]Delivery, len(q.ready))
+	for i, msg := range q.ready {
+		deliveries[i] = Delivery{
+			Queue:         queueName,
+			Exchange:      msg.exchange,
+			RoutingKey:    msg.routingKey,
+			MessageId:     msg.publishing.MessageId,
+			CorrelationId: msg.publishing.CorrelationId,
+			Headers:       msg.publishing.Headers,
+			Timestamp:     msg.publishing.Timestamp,
+			Body:          msg.publishing.Body,
+		}
+	}
+	return deliveries
+}
+
+// Pending returns the number of messages of a queue that are waiting or
+// being handled.
+func (b *InMemoryBroker) Pending(queueName string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return 0
+	}
+	return len(q.ready) + len(q.unacked)
+}
+
+// WaitForQueue waits, in real time, until a queue has no pending messages.
+func (b *InMemoryBroker) WaitForQueue(queueName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		pending := b.Pending(queueName)
+		if pending == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("queue %s still has %d pending messages after %s", queueName, pending, timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,179 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+)
+
+func TestTopicMatches(t *testing.T) {
+	cases := []struct {
+		binding    string
+		routingKey string
+		expected   bool
+	}{
+		{"order.*", "order.created", true},
+		{"order.*", "order.status.updated", false},
+		{"order.#", "order.status.updated", true},
+		{"order.#", "order", true},
+		{"#", "product.imported", true},
+		{"*.created", "order.created", true},
+		{"*.created", "created", false},
+		{"order.#.updated", "order.status.updated", true},
+		{"order.created", "order.cancelled", false},
+	}
+
+	for _, c := range cases {
+		if got := topicMatches(strings.Split(c.binding, "."), strings.Split(c.routingKey, ".")); got != c.expected {
+			t.Errorf("Expected %s matching %s to be %v", c.binding, c.routingKey, c.expected)
+		}
+	}
+}
+
+func newTestBroker(t *testing.T) (*InMemoryBroker, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	broker := NewInMemoryBroker(clk, logger.NewLogger())
+	if err := broker.DeclareExchange("orders", "topic"); err != nil {
+		t.Fatal(err)
+	}
+	return broker, clk
+}
+
+func TestInMemoryBrokerRouting(t *testing.T) {
+	broker, _ := newTestBroker(t)
+	broker.DeclareQueue("all")
+	broker.DeclareQueue("created")
+	broker.BindQueue("all", "orders", "order.#")
+	broker.BindQueue("created", "orders", "order.created")
+
+	if err := broker.PublishMessage("orders", "order.created", map[string]string{"order_id": "o1"}); err != nil {
+		t.Fatalf("Expected the message to be routed, got %v", err)
+	}
+	if err := broker.PublishMessage("orders", "order.status.updated", map[string]string{"order_id": "o1"}); err != nil {
+		t.Fatalf("Expected the message to be routed, got %v", err)
+	}
+
+	if pending := broker.Pending("all"); pending != 2 {
+		t.Errorf("Expected 2 messages bound by order.#, got %d", pending)
+	}
+	messages := broker.Messages("created")
+	if len(messages) != 1 || messages[0].RoutingKey != "order.created" || string(messages[0].Body) != `{"order_id":"o1"}` {
+		t.Errorf("Expected the order.created message, got %+v", messages)
+	}
+
+	if err := broker.PublishMessage("orders", "product.created", nil); err != nil {
+		t.Errorf("Expected an event without subscribers to be dropped, got %v", err)
+	}
+	err := broker.PublishMandatory("orders", "product.created", nil)
+	var unroutable *UnroutableError
+	if !errors.As(err, &unroutable) || unroutable.RoutingKey != "product.created" {
+		t.Errorf("Expected an UnroutableError, got %v", err)
+	}
+	if err := broker.PublishMessage("catalog", "product.created", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an undeclared exchange, got %v", err)
+	}
+}
+
+func TestInMemoryBrokerAcksAndDeadLetters(t *testing.T) {
+	broker, _ := newTestBroker(t)
+	broker.DeclareQueue("notifications")
+	broker.BindQueue("notifications", "orders", "order.*")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := broker.ConsumeWithOptions(ctx, "notifications", func(_ context.Context, delivery *Delivery) error {
+		if delivery.RoutingKey == "order.cancelled" {
+			return errors.New("failed")
+		}
+		return nil
+	}, ConsumeOptions{Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker.PublishMessage("orders", "order.created", nil)
+	broker.PublishMessage("orders", "order.cancelled", nil)
+	// Without a dead-letter exchange or retry queues the failed message is
+	// dropped
+	if err := broker.WaitForQueue("notifications", time.Second); err != nil {
+		t.Fatalf("Expected acks and nacks to settle every message: %v", err)
+	}
+
+	cancel()
+	subscription.Wait()
+
+	broker.PublishMessage("orders", "order.created", nil)
+	if pending := broker.Pending("notifications"); pending != 1 {
+		t.Errorf("Expected messages to wait for a consumer after it stopped, got %d pending", pending)
+	}
+}
+
+func TestInMemoryBrokerRetriesThenParks(t *testing.T) {
+	broker, clk := newTestBroker(t)
+	broker.DeclareQueue("notifications")
+	broker.BindQueue("notifications", "orders", "order.*")
+	policy := RetryPolicy{Delays: []time.Duration{time.Second, 10 * time.Second}}
+	if err := broker.DeclareRetryQueues("notifications", policy); err != nil {
+		t.Fatal(err)
+	}
+
+	handled := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := broker.ConsumeWithOptions(ctx, "notifications", func(context.Context, *Delivery) error {
+		handled <- struct{}{}
+		return errors.New("smtp unavailable")
+	}, ConsumeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker.PublishMessage("orders", "order.created", nil)
+
+	for _, delay := range policy.Delays {
+		<-handled
+		waitFor(t, func() bool { return broker.Pending(DelayQueueName("notifications", delay)) == 1 })
+		clk.Advance(delay)
+	}
+	<-handled
+
+	waitFor(t, func() bool { return broker.Pending(ParkingLotName("notifications")) == 1 })
+	parked := broker.Messages(ParkingLotName("notifications"))[0]
+	if attempts(parked.Headers) != 3 || parked.Headers[LastErrorHeader] != "smtp unavailable" {
+		t.Errorf("Expected the message to be parked after 3 attempts, got headers %v", parked.Headers)
+	}
+}
+
+func TestInMemoryBrokerDeletesTemporaryQueues(t *testing.T) {
+	broker, _ := newTestBroker(t)
+	broker.DeclareTemporaryQueue("cache-invalidation")
+	broker.BindQueue("cache-invalidation", "orders", "#")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := broker.ConsumeWithOptions(ctx, "cache-invalidation", func(context.Context, *Delivery) error { return nil }, ConsumeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	subscription.Wait()
+
+	var unroutable *UnroutableError
+	if err := broker.PublishMandatory("orders", "order.created", nil); !errors.As(err, &unroutable) {
+		t.Errorf("Expected the temporary queue and its binding to be deleted, got %v", err)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the broker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
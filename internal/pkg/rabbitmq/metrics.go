@@ -0,0 +1,105 @@
+package rabbitmq
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	connectionUp = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "rabbitmq_connection_up",
+			Help: "Whether the RabbitMQ connection is currently established (1) or not (0)",
+		},
+	)
+
+	connectionLostTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_connection_lost_total",
+			Help: "Total number of times the RabbitMQ connection or channel was closed unexpectedly",
+		},
+	)
+
+	reconnectAttemptsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_reconnect_attempts_total",
+			Help: "Total number of RabbitMQ reconnection attempts by result (success or failure)",
+		},
+		[]string{"result"},
+	)
+
+	messagesPublishedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_messages_published_total",
+			Help: "Total number of published messages by result (success or failure)",
+		},
+		[]string{"exchange", "routing_key", "result"},
+	)
+
+	publishDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rabbitmq_publish_duration_milliseconds",
+			Help:    "Duration of publishing a message until the broker confirmed it, in milliseconds",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 5000},
+		},
+		[]string{"exchange", "routing_key"},
+	)
+
+	publishConfirmsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_publish_confirms_total",
+			Help: "Total number of published messages by confirmation outcome (ack, nack, unroutable, timeout or interrupted)",
+		},
+		[]string{"exchange", "routing_key", "outcome"},
+	)
+
+	messagesRetriedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_messages_retried_total",
+			Help: "Total number of failed messages sent to a delay queue for another attempt",
+		},
+		[]string{"queue"},
+	)
+
+	messagesParkedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_messages_parked_total",
+			Help: "Total number of failed messages moved to a parking lot",
+		},
+		[]string{"queue"},
+	)
+
+	messagesConsumedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_messages_consumed_total",
+			Help: "Total number of consumed messages by how they were settled (ack, nack, retry or requeue)",
+		},
+		[]string{"queue", "outcome"},
+	)
+
+	consumerLag = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rabbitmq_consumer_lag_milliseconds",
+			Help:    "Time from publishing a message until it was first delivered to a consumer, in milliseconds",
+			Buckets: []float64{1, 5, 10, 50, 100, 500, 1000, 5000, 10000, 60000, 300000},
+		},
+		[]string{"queue"},
+	)
+
+	messagesHandledTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_messages_handled_total",
+			Help: "Total number of consumed messages by handler outcome (success, error or permanent)",
+		},
+		[]string{"queue", "routing_key", "outcome"},
+	)
+
+	messageHandlerDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rabbitmq_message_handler_duration_milliseconds",
+			Help:    "Duration of message handlers in milliseconds",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 5000},
+		},
+		[]string{"queue", "routing_key"},
+	)
+)
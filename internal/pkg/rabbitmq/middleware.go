@@ -0,0 +1,138 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/cache"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+)
+
+// CorrelationIdHeader carries the correlation ID of messages published
+// without the correlation-id property.
+const CorrelationIdHeader = "x-correlation-id"
+
+var ErrHandlerPanic = errors.New("message handler panicked")
+
+// Middleware wraps a DeliveryHandler, as middleware.Middleware wraps HTTP
+// handlers.
+type Middleware func(next DeliveryHandler) DeliveryHandler
+
+// Chain wraps handler in middlewares, the first of which runs outermost.
+func Chain(handler DeliveryHandler, middlewares ...Middleware) DeliveryHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+type correlationIdKey struct{}
+
+// CorrelationId returns the correlation ID that Tracing stored in ctx.
+func CorrelationId(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIdKey{}).(string)
+	return id
+}
+
+// Tracing stores the correlation ID of a delivery in the handler context,
+// taking it from the correlation-id property, the CorrelationIdHeader or the
+// message ID, in that order.
+func Tracing() Middleware {
+	return func(next DeliveryHandler) DeliveryHandler {
+		return func(ctx context.Context, delivery *Delivery) error {
+			id := delivery.CorrelationId
+			if id == "" {
+				id, _ = delivery.Headers[CorrelationIdHeader].(string)
+			}
+			if id == "" {
+				id = delivery.MessageId
+			}
+			return next(context.WithValue(ctx, correlationIdKey{}, id), delivery)
+		}
+	}
+}
+
+func Logging(log logger.Logger) Middleware {
+	return func(next DeliveryHandler) DeliveryHandler {
+		return func(ctx context.Context, delivery *Delivery) error {
+			start := time.Now()
+			err := next(ctx, delivery)
+			args := []any{
+				"queue", delivery.Queue,
+				"routing_key", delivery.RoutingKey,
+				"message_id", delivery.MessageId,
+				"correlation_id", CorrelationId(ctx),
+				"latency_ms", time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				log.Error("Failed to handle message", append(args, "permanent", IsPermanent(err), "error", err)...)
+			} else {
+				log.Info("Handled message", args...)
+			}
+			return err
+		}
+	}
+}
+
+// Metrics records the duration and outcome of handlers by queue and routing
+// key. How the consumer settles the message is recorded by the consumer.
+func Metrics() Middleware {
+	return func(next DeliveryHandler) DeliveryHandler {
+		return func(ctx context.Context, delivery *Delivery) error {
+			start := time.Now()
+			err := next(ctx, delivery)
+
+			outcome := "success"
+			if IsPermanent(err) {
+				outcome = "permanent"
+			} else if err != nil {
+				outcome = "error"
+			}
+			duration := float64(time.Since(start).Nanoseconds()) / 1e6
+			messageHandlerDuration.WithLabelValues(delivery.Queue, delivery.RoutingKey).Observe(duration)
+			messagesHandledTotal.WithLabelValues(delivery.Queue, delivery.RoutingKey, outcome).Inc()
+			return err
+		}
+	}
+}
+
+// Recovery turns a panicking handler into a permanent failure, so that the
+// message is parked rather than crashing the consumer.
+func Recovery(log logger.Logger) Middleware {
+	return func(next DeliveryHandler) DeliveryHandler {
+		return func(ctx context.Context, delivery *Delivery) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error("Message handler panicked", "queue", delivery.Queue, "message_id", delivery.MessageId, "panic", r, "stack", string(debug.Stack()))
+					err = Permanent(fmt.Errorf("%w: %v", ErrHandlerPanic, r))
+				}
+			}()
+			return next(ctx, delivery)
+		}
+	}
+}
+
+// Dedupe acknowledges deliveries whose message ID was handled successfully
+// before without calling the handler again. Handled IDs are kept in seen, so
+// duplicates are only detected while seen still holds them.
+func Dedupe(seen cache.Cache[bool]) Middleware {
+	return func(next DeliveryHandler) DeliveryHandler {
+		return func(ctx context.Context, delivery *Delivery) error {
+			if delivery.MessageId == "" {
+				return next(ctx, delivery)
+			}
+			if _, ok := seen.Get(delivery.MessageId); ok {
+				return nil
+			}
+
+			if err := next(ctx, delivery); err != nil {
+				return err
+			}
+			seen.Set(delivery.MessageId, true)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/cache"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestRecovery(t *testing.T) {
+	handler := Chain(func(context.Context, *Delivery) error {
+		panic("nil map")
+	}, Recovery(logger.NewLogger()))
+
+	err := handler(context.Background(), &Delivery{MessageId: "m1"})
+	if !errors.Is(err, ErrHandlerPanic) || !IsPermanent(err) {
+		t.Errorf("Expected a permanent ErrHandlerPanic, got %v", err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	handler := Chain(func(_ context.Context, delivery *Delivery) error {
+		switch delivery.RoutingKey {
+		case "order.cancelled":
+			return errors.New("temporary failure")
+		case "order.shipped":
+			return Permanent(errors.New("malformed event"))
+		}
+		return nil
+	}, Metrics())
+
+	for _, routingKey := range []string{"order.created", "order.cancelled", "order.shipped"} {
+		handler(context.Background(), &Delivery{Queue: "middleware-metrics", RoutingKey: routingKey})
+	}
+
+	outcomes := map[string]string{"order.created": "success", "order.cancelled": "error", "order.shipped": "permanent"}
+	for routingKey, outcome := range outcomes {
+		if handled := testutil.ToFloat64(messagesHandledTotal.WithLabelValues("middleware-metrics", routingKey, outcome)); handled != 1 {
+			t.Errorf("Expected 1 %s outcome for %s, got %v", outcome, routingKey, handled)
+		}
+	}
+	if observed := testutil.CollectAndCount(messageHandlerDuration, "rabbitmq_message_handler_duration_milliseconds"); observed == 0 {
+		t.Error("Expected the handler duration to be observed")
+	}
+}
+
+func TestTracing(t *testing.T) {
+	var correlationId string
+	handler := Chain(func(ctx context.Context, _ *Delivery) error {
+		correlationId = CorrelationId(ctx)
+		return nil
+	}, Tracing())
+
+	cases := []struct {
+		delivery *Delivery
+		expected string
+	}{
+		{&Delivery{MessageId: "m1", CorrelationId: "c1", Headers: amqp.Table{CorrelationIdHeader: "h1"}}, "c1"},
+		{&Delivery{MessageId: "m1", Headers: amqp.Table{CorrelationIdHeader: "h1"}}, "h1"},
+		{&Delivery{MessageId: "m1"}, "m1"},
+	}
+	for _, c := range cases {
+		handler(context.Background(), c.delivery)
+		if correlationId != c.expected {
+			t.Errorf("Expected correlation ID %s, got %s", c.expected, correlationId)
+		}
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	calls := 0
+	fail := true
+	handler := Chain(func(context.Context, *Delivery) error {
+		calls++
+		if fail {
+			fail = false
+			return errors.New("temporary failure")
+		}
+		return nil
+	}, Dedupe(cache.NewLRU[bool]("test-dedupe", 10, 0)))
+
+	delivery := &Delivery{MessageId: "m1"}
+	if err := handler(context.Background(), delivery); err == nil {
+		t.Fatalf("Expected the first attempt to fail")
+	}
+	for range 2 {
+		if err := handler(context.Background(), delivery); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected the handler to run until it succeeded once, ran %d times", calls)
+	}
+}
@@ -0,0 +1,133 @@
+package rabbitmq
+
+import (
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ParkedMessage is a message that ran out of retry attempts or failed
+// permanently.
+type ParkedMessage struct {
+	MessageId   string    `json:"message_id"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	ParkedAt    string    `json:"parked_at"`
+	PublishedAt time.Time `json:"published_at"`
+	Body        string    `json:"body"`
+}
+
+// InspectParked returns up to limit messages from the front of a queue's
+// parking lot without removing them.
+func (c *RabbitMQClient) InspectParked(queue string, limit int) ([]ParkedMessage, error) {
+	var parked []ParkedMessage
+	err := c.withChannel(func(channel *amqp.Channel) error {
+		// Messages left unacknowledged return to the queue when the
+		// channel closes
+		for len(parked) < limit {
+			msg, ok, err := channel.Get(ParkingLotName(queue), false)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+
+			lastError, _ := msg.Headers[LastErrorHeader].(string)
+			parkedAt, _ := msg.Headers[ParkedAtHeader].(string)
+			parked = append(parked, ParkedMessage{
+				MessageId:   msg.MessageId,
+				Attempts:    attempts(msg.Headers),
+				LastError:   lastError,
+				ParkedAt:    parkedAt,
+				PublishedAt: msg.Timestamp,
+				Body:        string(msg.Body),
+			})
+		}
+		return nil
+	})
+	return parked, err
+}
+
+// ReplayParked moves up to limit messages from a queue's parking lot back to
+// the queue with their attempts reset, and returns how many were moved.
+func (c *RabbitMQClient) ReplayParked(queue string, limit int) (int, error) {
+	var replayed int
+	err := c.withChannel(func(channel *amqp.Channel) error {
+		for replayed < limit {
+			msg, ok, err := channel.Get(ParkingLotName(queue), false)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+
+			err = c.publishAndWait("", queue, replayPublishing(msg))
+			if err != nil {
+				msg.Nack(false, true)
+				return fmt.Errorf("failed to replay message %s: %w", msg.MessageId, err)
+			}
+
+			if err := msg.Ack(false); err != nil {
+				return err
+			}
+			replayed++
+		}
+		return nil
+	})
+	return replayed, err
+}
+
+// replayPublishing copies a parked message for its queue with its attempts
+// reset. The copy keeps the other headers, including the original exchange
+// and routing key, so consumers route it as they did the first time.
+func replayPublishing(msg amqp.Delivery) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	delete(headers, AttemptsHeader)
+	delete(headers, ParkedAtHeader)
+
+	return amqp.Publishing{
+		Headers:      headers,
+		ContentType:  msg.ContentType,
+		Body:         msg.Body,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.MessageId,
+		Timestamp:    msg.Timestamp,
+	}
+}
+
+// PurgeParked deletes all messages in a queue's parking lot and returns how
+// many were deleted.
+func (c *RabbitMQClient) PurgeParked(queue string) (int, error) {
+	var purged int
+	err := c.withChannel(func(channel *amqp.Channel) error {
+		var err error
+		purged, err = channel.QueuePurge(ParkingLotName(queue), false)
+		return err
+	})
+	return purged, err
+}
+
+// withChannel runs f on a short-lived channel, keeping failures such as a
+// missing queue from closing the client's own channel.
+func (c *RabbitMQClient) withChannel(f func(channel *amqp.Channel) error) error {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer channel.Close()
+
+	return f(channel)
+}
@@ -0,0 +1,345 @@
+package rabbitmq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// returnBuffer bounds the returned messages held until their publishers
+// collect them. The broker sends a message's return before its confirmation,
+// so a return is always buffered by the time its publisher is confirmed.
+const returnBuffer = 256
+
+var (
+	// ErrNacked means the broker refused responsibility for a message
+	ErrNacked = errors.New("message was rejected by the broker")
+	// ErrUnconfirmed means no confirmation arrived in time or the channel
+	// closed first, so the message may or may not have been delivered
+	ErrUnconfirmed = errors.New("message was not confirmed by the broker")
+	// ErrUnroutable means no queue was bound for the message's routing key
+	ErrUnroutable = errors.New("message could not be routed to any queue")
+)
+
+// UnroutableError describes a mandatory message the broker returned.
+type UnroutableError struct {
+	Exchange   string
+	RoutingKey string
+	ReplyCode  uint16
+	ReplyText  string
+}
+
+func (e *UnroutableError) Error() string {
+	return fmt.Sprintf("%v: exchange %q, routing key %q: %s", ErrUnroutable, e.Exchange, e.RoutingKey, e.ReplyText)
+}
+
+func (e *UnroutableError) Is(target error) bool {
+	return target == ErrUnroutable
+}
+
+// Message is a message to publish as part of a batch.
+type Message struct {
+	Exchange   string
+	RoutingKey string
+	Body       interface{}
+	// Mandatory fails the message with ErrUnroutable when no queue is bound
+	// for it
+	Mandatory bool
+}
+
+// BatchError lists the messages of a batch that failed to publish by their
+// index in the batch.
+type BatchError struct {
+	Total  int
+	Failed map[int]error
+}
+
+func (e *BatchError) Error() string {
+	errs := make([]string, 0, len(e.Failed))
+	for i := range e.Total {
+		if err, ok := e.Failed[i]; ok {
+			errs = append(errs, fmt.Sprintf("message %d: %v", i, err))
+		}
+	}
+	return fmt.Sprintf("%d of %d messages failed to publish: %s", len(e.Failed), e.Total, strings.Join(errs, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// confirmChannel is a channel in confirm mode that tracks which of its
+// pending messages the broker returned as unroutable.
+type confirmChannel struct {
+	channel  *amqp.Channel
+	returns  chan amqp.Return
+	mu       sync.Mutex
+	pending  map[string]bool
+	returned map[string]amqp.Return
+}
+
+func openConfirmChannel(conn *amqp.Connection) (*confirmChannel, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	cc := &confirmChannel{
+		channel:  channel,
+		returns:  make(chan amqp.Return, returnBuffer),
+		pending:  make(map[string]bool),
+		returned: make(map[string]amqp.Return),
+	}
+	channel.NotifyReturn(cc.returns)
+	return cc, nil
+}
+
+func (cc *confirmChannel) track(messageId string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.pending[messageId] = true
+}
+
+// resolve stops tracking a message and reports whether it was returned.
+func (cc *confirmChannel) resolve(messageId string) (amqp.Return, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	for drained := false; !drained; {
+		select {
+		case ret := <-cc.returns:
+			if cc.pending[ret.MessageId] {
+				cc.returned[ret.MessageId] = ret
+			}
+		default:
+			drained = true
+		}
+	}
+
+	ret, ok := cc.returned[messageId]
+	delete(cc.pending, messageId)
+	delete(cc.returned, messageId)
+	return ret, ok
+}
+
+// Publishing is a message published without waiting for its confirmation.
+type Publishing struct {
+	exchange     string
+	routingKey   string
+	messageId    string
+	start        time.Time
+	err          error
+	channel      *confirmChannel
+	confirmation *amqp.DeferredConfirmation
+	deadline     time.Time
+}
+
+// Wait blocks until the broker confirms the message and returns nil, or
+// returns an error matching ErrNacked, ErrUnconfirmed, ErrUnroutable or
+// ErrNotConnected. Without publisher confirms it only reports whether the
+// message could be sent.
+func (p *Publishing) Wait() error {
+	err := p.wait()
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	} else {
+		publishDuration.WithLabelValues(p.exchange, p.routingKey).Observe(float64(time.Since(p.start).Nanoseconds()) / 1e6)
+	}
+	messagesPublishedTotal.WithLabelValues(p.exchange, p.routingKey, result).Inc()
+	return err
+}
+
+func (p *Publishing) wait() error {
+	if p.err != nil || p.confirmation == nil {
+		return p.err
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), p.deadline)
+	defer cancel()
+
+	acked, err := p.confirmation.WaitContext(ctx)
+	ret, returned := p.channel.resolve(p.messageId)
+
+	switch {
+	case err != nil:
+		p.confirmed("timeout")
+		return fmt.Errorf("%w: no confirmation within the timeout", ErrUnconfirmed)
+	case returned:
+		p.confirmed("unroutable")
+		return &UnroutableError{
+			Exchange:   ret.Exchange,
+			RoutingKey: ret.RoutingKey,
+			ReplyCode:  ret.ReplyCode,
+			ReplyText:  ret.ReplyText,
+		}
+	case !acked && p.channel.channel.IsClosed():
+		p.confirmed("interrupted")
+		return fmt.Errorf("%w: channel closed before confirmation", ErrUnconfirmed)
+	case !acked:
+		p.confirmed("nack")
+		return ErrNacked
+	}
+
+	p.confirmed("ack")
+	return nil
+}
+
+func (p *Publishing) confirmed(outcome string) {
+	publishConfirmsTotal.WithLabelValues(p.exchange, p.routingKey, outcome).Inc()
+}
+
+// PublishMessage publishes a message as JSON and, with publisher confirms,
+// waits for the broker to confirm it. Events are published this way: a
+// message no queue is bound for is dropped by the broker without an error,
+// since having no subscribers is not a failure of the publisher.
+func (c *RabbitMQClient) PublishMessage(exchange, routingKey string, message interface{}) error {
+	return c.publishJSON(exchange, routingKey, message, false).Wait()
+}
+
+// PublishMandatory publishes a message like PublishMessage, but fails with
+// ErrUnroutable when publisher confirms are on and no queue is bound for it.
+// It suits messages that must reach a consumer, such as commands.
+func (c *RabbitMQClient) PublishMandatory(exchange, routingKey string, message interface{}) error {
+	return c.publishJSON(exchange, routingKey, message, true).Wait()
+}
+
+// PublishAsync publishes a message as JSON without waiting for its
+// confirmation, which the returned Publishing waits for.
+func (c *RabbitMQClient) PublishAsync(exchange, routingKey string, message interface{}) *Publishing {
+	return c.publishJSON(exchange, routingKey, message, false)
+}
+
+func (c *RabbitMQClient) publishJSON(exchange, routingKey string, message interface{}, mandatory bool) *Publishing {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return &Publishing{exchange: exchange, routingKey: routingKey, err: fmt.Errorf("failed to marshal message: %w", err)}
+	}
+
+	return c.publish(exchange, routingKey, amqp.Publishing{
+		ContentType:  "application/json",
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+	}, mandatory)
+}
+
+// publish sends a message on the confirm channel, or on the consuming
+// channel without publisher confirms. Mandatory messages that the broker
+// returns as unroutable fail with an *UnroutableError, which is only
+// reported with publisher confirms. Messages without a MessageId are given
+// one.
+func (c *RabbitMQClient) publish(exchange, routingKey string, publishing amqp.Publishing, mandatory bool) *Publishing {
+	p := &Publishing{exchange: exchange, routingKey: routingKey, start: time.Now()}
+
+	if publishing.MessageId == "" {
+		messageId, err := newMessageId()
+		if err != nil {
+			p.err = err
+			return p
+		}
+		publishing.MessageId = messageId
+	}
+	p.messageId = publishing.MessageId
+
+	c.mu.RLock()
+	channel, confirmChannel := c.channel, c.publishing
+	c.mu.RUnlock()
+
+	if !c.confirms {
+		if channel == nil {
+			p.err = fmt.Errorf("failed to publish message: %w", ErrNotConnected)
+			return p
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := channel.PublishWithContext(ctx, exchange, routingKey, mandatory, false, publishing); err != nil {
+			p.err = fmt.Errorf("failed to publish message: %w", err)
+		}
+		return p
+	}
+
+	if confirmChannel == nil {
+		p.err = fmt.Errorf("failed to publish message: %w", ErrNotConnected)
+		return p
+	}
+
+	p.channel = confirmChannel
+	p.deadline = time.Now().Add(c.confirmTimeout)
+	ctx, cancel := context.WithDeadline(context.Background(), p.deadline)
+	defer cancel()
+
+	var err error
+	if mandatory {
+		confirmChannel.track(p.messageId)
+	}
+	p.confirmation, err = confirmChannel.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange,
+		routingKey,
+		mandatory,
+		false, // immediate
+		publishing,
+	)
+	if err != nil {
+		confirmChannel.resolve(p.messageId)
+		p.err = fmt.Errorf("failed to publish message: %w", err)
+		if confirmChannel.channel.IsClosed() {
+			p.err = fmt.Errorf("failed to publish message: %w", ErrNotConnected)
+		}
+	}
+	return p
+}
+
+// publishAndWait publishes a message as mandatory, since the retry queues it
+// publishes to must exist.
+func (c *RabbitMQClient) publishAndWait(exchange, routingKey string, publishing amqp.Publishing) error {
+	return c.publish(exchange, routingKey, publishing, true).Wait()
+}
+
+// PublishBatch publishes all messages before waiting for their
+// confirmations. It returns a *BatchError when any of them failed.
+func (c *RabbitMQClient) PublishBatch(messages []Message) error {
+	publishings := make([]*Publishing, len(messages))
+	for i, message := range messages {
+		publishings[i] = c.publishJSON(message.Exchange, message.RoutingKey, message.Body, message.Mandatory)
+	}
+
+	failed := make(map[int]error)
+	for i, p := range publishings {
+		if err := p.Wait(); err != nil {
+			failed[i] = err
+		}
+	}
+
+	if len(failed) > 0 {
+		return &BatchError{Total: len(messages), Failed: failed}
+	}
+	return nil
+}
+
+func newMessageId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate message id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
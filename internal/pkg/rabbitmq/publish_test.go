@@ -0,0 +1,59 @@
+package rabbitmq
+
+import (
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestResolveMatchesReturnsToPendingMessages(t *testing.T) {
+	cc := &confirmChannel{
+		returns:  make(chan amqp.Return, returnBuffer),
+		pending:  make(map[string]bool),
+		returned: make(map[string]amqp.Return),
+	}
+	cc.track("a")
+	cc.track("b")
+
+	cc.returns <- amqp.Return{MessageId: "b", RoutingKey: "order.unbound", ReplyText: "NO_ROUTE"}
+	cc.returns <- amqp.Return{MessageId: "unknown"}
+
+	if _, returned := cc.resolve("a"); returned {
+		t.Fatalf("Expected message a not to be returned")
+	}
+
+	ret, returned := cc.resolve("b")
+	if !returned || ret.RoutingKey != "order.unbound" {
+		t.Fatalf("Expected message b to be returned, got %+v, %v", ret, returned)
+	}
+
+	if len(cc.pending) != 0 || len(cc.returned) != 0 {
+		t.Fatalf("Expected resolved messages to be forgotten, got %d pending and %d returned", len(cc.pending), len(cc.returned))
+	}
+}
+
+func TestPublishErrorsAreTyped(t *testing.T) {
+	c := &RabbitMQClient{confirms: true, done: make(chan struct{})}
+
+	if err := c.PublishMessage("orders", "order.created", map[string]string{}); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Expected ErrNotConnected, got %v", err)
+	}
+
+	err := c.PublishBatch([]Message{
+		{Exchange: "orders", RoutingKey: "order.created"},
+		{Exchange: "orders", RoutingKey: "order.created", Body: make(chan int)},
+	})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Failed) != 2 {
+		t.Fatalf("Expected a batch error with 2 failed messages, got %v", err)
+	}
+	if !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Expected the batch error to wrap ErrNotConnected, got %v", err)
+	}
+
+	unroutable := error(&UnroutableError{Exchange: "orders", RoutingKey: "order.unbound"})
+	if !errors.Is(unroutable, ErrUnroutable) {
+		t.Fatalf("Expected UnroutableError to match ErrUnroutable")
+	}
+}
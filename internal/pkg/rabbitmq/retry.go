@@ -0,0 +1,212 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// AttemptsHeader counts the failed deliveries of a message
+	AttemptsHeader  = "x-retry-attempts"
+	LastErrorHeader = "x-last-error"
+	ParkedAtHeader  = "x-parked-at"
+	// OriginalExchangeHeader and OriginalRoutingKeyHeader keep where a
+	// message was first published, since retried and replayed copies are
+	// sent to their queue through the default exchange
+	OriginalExchangeHeader   = "x-original-exchange"
+	OriginalRoutingKeyHeader = "x-original-routing-key"
+)
+
+// RetryPolicy retries the messages of a queue whose handler failed after the
+// delay of each tier in turn, repeating the last tier, until MaxAttempts
+// deliveries failed. The message is then moved to the queue's parking lot.
+type RetryPolicy struct {
+	Delays []time.Duration
+	// MaxAttempts defaults to one delivery more than there are delays
+	MaxAttempts int
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	Delays: []time.Duration{time.Second, 10 * time.Second, time.Minute},
+}
+
+func (p RetryPolicy) validate() error {
+	if len(p.Delays) == 0 {
+		return errors.New("retry policy needs at least one delay")
+	}
+	return nil
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return len(p.Delays) + 1
+}
+
+// delay returns the tier a message waits in after its given failed attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	return p.Delays[min(attempt, len(p.Delays))-1]
+}
+
+// handlerError marks a handler error as retryable or permanent.
+type handlerError struct {
+	err       error
+	permanent bool
+}
+
+func (e *handlerError) Error() string {
+	return e.err.Error()
+}
+
+func (e *handlerError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks a handler error that retrying cannot fix, such as a
+// malformed message. Such messages are parked without further attempts.
+func Permanent(err error) error {
+	return &handlerError{err: err, permanent: true}
+}
+
+// Retryable marks a handler error as transient. Unmarked errors are retried
+// as well; Retryable overrides a Permanent error it wraps.
+func Retryable(err error) error {
+	return &handlerError{err: err}
+}
+
+// IsPermanent reports whether the outermost mark on err is Permanent.
+func IsPermanent(err error) bool {
+	var marked *handlerError
+	return errors.As(err, &marked) && marked.permanent
+}
+
+func DelayQueueName(queue string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", queue, delay)
+}
+
+func ParkingLotName(queue string) string {
+	return queue + ".parking-lot"
+}
+
+// DeclareRetryQueues declares the delay queues and parking lot of a queue and
+// applies policy to consumers of the queue registered afterwards. Delay
+// queues dead-letter expired messages back to the queue through the default
+// exchange, so the queue itself needs no arguments.
+func (c *RabbitMQClient) DeclareRetryQueues(queue string, policy RetryPolicy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+
+	for _, delay := range policy.Delays {
+		name := DelayQueueName(queue, delay)
+		err := c.declare("queue "+name, func(channel *amqp.Channel) error {
+			_, err := channel.QueueDeclare(
+				name,
+				true,  // durable
+				false, // delete when unused
+				false, // exclusive
+				false, // no-wait
+				amqp.Table{
+					"x-message-ttl":             delay.Milliseconds(),
+					"x-dead-letter-exchange":    "",
+					"x-dead-letter-routing-key": queue,
+				},
+			)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to declare delay queue %s: %w", name, err)
+		}
+	}
+
+	if err := c.DeclareQueue(ParkingLotName(queue)); err != nil {
+		return fmt.Errorf("failed to declare parking lot: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.retryPolicies == nil {
+		c.retryPolicies = make(map[string]RetryPolicy)
+	}
+	c.retryPolicies[queue] = policy
+	return nil
+}
+
+// publishFunc publishes a message and waits for its confirmation.
+type publishFunc func(exchange, routingKey string, publishing amqp.Publishing) error
+
+// retry sends a failed message to its next delay queue, or to the parking lot
+// once it is out of attempts or failed permanently. The message is only
+// acknowledged once its copy was published.
+func retry(publish publishFunc, log logger.Logger, queue string, policy RetryPolicy, msg amqp.Delivery, handlerErr error) error {
+	attempt := attempts(msg.Headers) + 1
+
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[AttemptsHeader] = int32(attempt)
+	headers[LastErrorHeader] = handlerErr.Error()
+	if _, ok := headers[OriginalRoutingKeyHeader]; !ok {
+		headers[OriginalExchangeHeader] = msg.Exchange
+		headers[OriginalRoutingKeyHeader] = msg.RoutingKey
+	}
+
+	var routingKey string
+	parked := IsPermanent(handlerErr) || attempt >= policy.maxAttempts()
+	if parked {
+		routingKey = ParkingLotName(queue)
+		headers[ParkedAtHeader] = time.Now().UTC().Format(time.RFC3339)
+	} else {
+		routingKey = DelayQueueName(queue, policy.delay(attempt))
+	}
+
+	err := publish("", routingKey, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  msg.ContentType,
+		Body:         msg.Body,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.MessageId,
+		Timestamp:    msg.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+
+	if parked {
+		messagesParkedTotal.WithLabelValues(queue).Inc()
+		log.Warn("Parked message", "queue", queue, "message_id", msg.MessageId, "attempts", attempt, "error", handlerErr)
+	} else {
+		messagesRetriedTotal.WithLabelValues(queue).Inc()
+	}
+	return nil
+}
+
+// attempts reads the failed delivery count of a message. Header integers
+// decode as int32 or int64 depending on how they were encoded.
+func attempts(headers amqp.Table) int {
+	switch n := headers[AttemptsHeader].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+// originalRoute returns the exchange and routing key a message was first
+// published with, which the headers of retried and replayed copies keep.
+func originalRoute(headers amqp.Table, exchange, routingKey string) (string, string) {
+	if original, ok := headers[OriginalRoutingKeyHeader].(string); ok {
+		originalExchange, _ := headers[OriginalExchangeHeader].(string)
+		return originalExchange, original
+	}
+	return exchange, routingKey
+}
@@ -0,0 +1,99 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestRetryPolicyTiers(t *testing.T) {
+	policy := RetryPolicy{Delays: []time.Duration{time.Second, 10 * time.Second}, MaxAttempts: 5}
+
+	expected := []time.Duration{time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		if got := policy.delay(i + 1); got != want {
+			t.Errorf("Expected a delay of %v after attempt %d, got %v", want, i+1, got)
+		}
+	}
+
+	if attempts := DefaultRetryPolicy.maxAttempts(); attempts != 4 {
+		t.Errorf("Expected 4 attempts by default, got %d", attempts)
+	}
+	if name := DelayQueueName("notifications", 10*time.Second); name != "notifications.retry.10s" {
+		t.Errorf("Expected notifications.retry.10s, got %s", name)
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	base := errors.New("malformed event")
+
+	if IsPermanent(base) {
+		t.Errorf("Expected unmarked errors to be retryable")
+	}
+	if !IsPermanent(fmt.Errorf("handling: %w", Permanent(base))) {
+		t.Errorf("Expected a wrapped permanent error to be permanent")
+	}
+	if IsPermanent(Retryable(Permanent(base))) {
+		t.Errorf("Expected Retryable to override the permanent error it wraps")
+	}
+	if !errors.Is(Permanent(base), base) {
+		t.Errorf("Expected marked errors to unwrap to the handler error")
+	}
+}
+
+func TestAttemptsHeader(t *testing.T) {
+	tests := []struct {
+		headers amqp.Table
+		want    int
+	}{
+		{nil, 0},
+		{amqp.Table{AttemptsHeader: int32(2)}, 2},
+		{amqp.Table{AttemptsHeader: int64(3)}, 3},
+		{amqp.Table{AttemptsHeader: "4"}, 0},
+	}
+	for _, test := range tests {
+		if got := attempts(test.headers); got != test.want {
+			t.Errorf("Expected %d attempts for %v, got %d", test.want, test.headers, got)
+		}
+	}
+}
+
+func TestRetryKeepsOriginalRoute(t *testing.T) {
+	var published []amqp.Publishing
+	var routingKeys []string
+	publish := func(exchange, routingKey string, publishing amqp.Publishing) error {
+		routingKeys = append(routingKeys, routingKey)
+		published = append(published, publishing)
+		return nil
+	}
+	policy := RetryPolicy{Delays: []time.Duration{time.Second}, MaxAttempts: 2}
+
+	// The first failure is on the message as published, the second on the
+	// copy that came back from the delay queue through the default exchange
+	msg := amqp.Delivery{Exchange: "orders", RoutingKey: "order.shipped", MessageId: "m1"}
+	if err := retry(publish, logger.NewLogger(), "notifications", policy, msg, errors.New("failed")); err != nil {
+		t.Fatal(err)
+	}
+	msg = amqp.Delivery{Exchange: "", RoutingKey: "notifications", MessageId: "m1", Headers: published[0].Headers}
+	if err := retry(publish, logger.NewLogger(), "notifications", policy, msg, errors.New("failed")); err != nil {
+		t.Fatal(err)
+	}
+	if routingKeys[1] != ParkingLotName("notifications") {
+		t.Fatalf("Expected the second failure to be parked, got %s", routingKeys[1])
+	}
+
+	parked := amqp.Delivery{Exchange: "", RoutingKey: ParkingLotName("notifications"), MessageId: "m1", Headers: published[1].Headers}
+	replayed := replayPublishing(parked)
+	for _, headers := range []amqp.Table{published[0].Headers, published[1].Headers, replayed.Headers} {
+		if headers[OriginalExchangeHeader] != "orders" || headers[OriginalRoutingKeyHeader] != "order.shipped" {
+			t.Errorf("Expected the original route orders/order.shipped, got %v", headers)
+		}
+	}
+	if _, ok := replayed.Headers[AttemptsHeader]; ok {
+		t.Errorf("Expected the replayed message to have its attempts reset")
+	}
+}
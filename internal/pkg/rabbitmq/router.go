@@ -0,0 +1,56 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+)
+
+// Router dispatches deliveries to typed handlers by the routing key they were
+// published with, which retried copies keep in their headers.
+type Router struct {
+	routes      map[string]DeliveryHandler
+	middlewares []Middleware
+	log         logger.Logger
+}
+
+func NewRouter(log logger.Logger) *Router {
+	return &Router{
+		routes:      make(map[string]DeliveryHandler),
+		middlewares: make([]Middleware, 0),
+		log:         log,
+	}
+}
+
+// Handle routes the messages published with routingKey to handler, decoding
+// their JSON body into T. Messages that do not decode fail permanently.
+func Handle[T any](r *Router, routingKey string, handler func(ctx context.Context, event T) error) {
+	r.routes[routingKey] = func(ctx context.Context, delivery *Delivery) error {
+		var event T
+		if err := json.Unmarshal(delivery.Body, &event); err != nil {
+			return Permanent(fmt.Errorf("failed to decode %s message: %w", routingKey, err))
+		}
+		return handler(ctx, event)
+	}
+}
+
+func (r *Router) AddMiddleware(middleware Middleware) {
+	r.middlewares = append(r.middlewares, middleware)
+}
+
+// BuildHandler returns a handler that dispatches deliveries through the
+// middlewares. Messages without a route are acknowledged and ignored.
+func (r *Router) BuildHandler() DeliveryHandler {
+	return Chain(r.dispatch, r.middlewares...)
+}
+
+func (r *Router) dispatch(ctx context.Context, delivery *Delivery) error {
+	handler, ok := r.routes[delivery.RoutingKey]
+	if !ok {
+		r.log.Debug("Ignoring message without a route", "queue", delivery.Queue, "routing_key", delivery.RoutingKey)
+		return nil
+	}
+	return handler(ctx, delivery)
+}
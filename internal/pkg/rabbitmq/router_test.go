@@ -0,0 +1,126 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+)
+
+type testEvent struct {
+	OrderID string `json:"order_id"`
+}
+
+func TestRouterDispatchesByRoutingKey(t *testing.T) {
+	router := NewRouter(logger.NewLogger())
+
+	var created, shipped []string
+	Handle(router, "order.created", func(_ context.Context, event testEvent) error {
+		created = append(created, event.OrderID)
+		return nil
+	})
+	Handle(router, "order.shipped", func(_ context.Context, event testEvent) error {
+		shipped = append(shipped, event.OrderID)
+		return nil
+	})
+	handler := router.BuildHandler()
+
+	deliveries := []*Delivery{
+		{RoutingKey: "order.created", Body: []byte(`{"order_id":"o1"}`)},
+		{RoutingKey: "order.shipped", Body: []byte(`{"order_id":"o2"}`)},
+		{RoutingKey: "order.returned", Body: []byte(`{"order_id":"o3"}`)},
+	}
+	for _, delivery := range deliveries {
+		if err := handler(context.Background(), delivery); err != nil {
+			t.Fatalf("Expected %s to be handled, got %v", delivery.RoutingKey, err)
+		}
+	}
+
+	if len(created) != 1 || created[0] != "o1" || len(shipped) != 1 || shipped[0] != "o2" {
+		t.Errorf("Expected o1 created and o2 shipped, got %v and %v", created, shipped)
+	}
+}
+
+func TestRouterRejectsUndecodableMessages(t *testing.T) {
+	router := NewRouter(logger.NewLogger())
+	Handle(router, "order.created", func(context.Context, testEvent) error {
+		t.Fatal("Expected the handler not to be called")
+		return nil
+	})
+
+	err := router.BuildHandler()(context.Background(), &Delivery{RoutingKey: "order.created", Body: []byte("{")})
+	if !IsPermanent(err) {
+		t.Errorf("Expected a permanent error, got %v", err)
+	}
+}
+
+func TestRouterMiddlewareOrder(t *testing.T) {
+	router := NewRouter(logger.NewLogger())
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next DeliveryHandler) DeliveryHandler {
+			return func(ctx context.Context, delivery *Delivery) error {
+				calls = append(calls, name)
+				return next(ctx, delivery)
+			}
+		}
+	}
+	router.AddMiddleware(record("first"))
+	router.AddMiddleware(record("second"))
+	Handle(router, "order.created", func(context.Context, testEvent) error {
+		calls = append(calls, "handler")
+		return errors.New("failed")
+	})
+
+	if err := router.BuildHandler()(context.Background(), &Delivery{RoutingKey: "order.created", Body: []byte("{}")}); err == nil {
+		t.Errorf("Expected the handler error to be returned")
+	}
+	if len(calls) != 3 || calls[0] != "first" || calls[1] != "second" || calls[2] != "handler" {
+		t.Errorf("Expected first, second, handler, got %v", calls)
+	}
+}
+
+func TestRouterRetriesByOriginalRoutingKey(t *testing.T) {
+	broker, clk := newTestBroker(t)
+	broker.DeclareQueue("notifications")
+	broker.BindQueue("notifications", "orders", "order.#")
+	if err := broker.DeclareRetryQueues("notifications", RetryPolicy{Delays: []time.Duration{time.Second}}); err != nil {
+		t.Fatal(err)
+	}
+
+	router := NewRouter(logger.NewLogger())
+	handled := make(chan string, 10)
+	Handle(router, "order.shipped", func(_ context.Context, event testEvent) error {
+		handled <- event.OrderID
+		if len(handled) == 1 {
+			return errors.New("smtp unavailable")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := broker.ConsumeWithOptions(ctx, "notifications", router.BuildHandler(), ConsumeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker.PublishMessage("orders", "order.shipped", testEvent{OrderID: "o1"})
+	waitFor(t, func() bool { return broker.Pending(DelayQueueName("notifications", time.Second)) == 1 })
+	clk.Advance(time.Second)
+	waitFor(t, func() bool { return len(handled) == 2 })
+	if err := broker.WaitForQueue("notifications", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	subscription.Wait()
+
+	if calls := len(handled); calls != 2 {
+		t.Errorf("Expected the handler to be called again for the retried message, got %d calls", calls)
+	}
+	if parked := broker.Pending(ParkingLotName("notifications")); parked != 0 {
+		t.Errorf("Expected no parked messages, got %d", parked)
+	}
+}
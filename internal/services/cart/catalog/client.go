@@ -0,0 +1,76 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrProductNotFound = errors.New("product not found in catalog")
+
+// Product is a sellable catalog entry. For variants, SKU and Attributes are
+// set and Price and Stock are those of the variant.
+type Product struct {
+	ProductCode string         `json:"product_code"`
+	SKU         string         `json:"sku"`
+	Name        string         `json:"name"`
+	Category    string         `json:"category"`
+	Attributes  map[string]any `json:"attributes"`
+	Price       float64        `json:"price"`
+	Stock       int            `json:"stock"`
+	MaxOrderQty int            `json:"max_order_quantity"`
+}
+
+type ICatalogClient interface {
+	GetProduct(productCode string) (*Product, error)
+	GetVariant(sku string) (*Product, error)
+}
+
+type HttpCatalogClient struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+func NewHttpCatalogClient(baseUrl string) *HttpCatalogClient {
+	return &HttpCatalogClient{
+		baseUrl: strings.TrimRight(baseUrl, "/"),
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+func (c *HttpCatalogClient) GetProduct(productCode string) (*Product, error) {
+	return c.get("/products/" + url.PathEscape(productCode))
+}
+
+func (c *HttpCatalogClient) GetVariant(sku string) (*Product, error) {
+	return c.get("/variants/" + url.PathEscape(sku))
+}
+
+func (c *HttpCatalogClient) get(path string) (*Product, error) {
+	resp, err := c.httpClient.Get(c.baseUrl + path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach catalog service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrProductNotFound
+	default:
+		return nil, fmt.Errorf("catalog service returned status %d", resp.StatusCode)
+	}
+
+	var product Product
+	if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+		return nil, fmt.Errorf("failed to decode catalog product: %w", err)
+	}
+
+	return &product, nil
+}
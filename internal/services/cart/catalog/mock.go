@@ -0,0 +1,58 @@
+package catalog
+
+import "sync"
+
+type MockCatalogClient struct {
+	products map[string]*Product
+	variants map[string]*Product
+	mu       sync.RWMutex
+}
+
+func NewMockCatalogClient(products ...*Product) *MockCatalogClient {
+	client := &MockCatalogClient{
+		products: make(map[string]*Product),
+		variants: make(map[string]*Product),
+	}
+	for _, product := range products {
+		client.SetProduct(product)
+	}
+	return client
+}
+
+func (c *MockCatalogClient) GetProduct(productCode string) (*Product, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	product, ok := c.products[productCode]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+
+	productCopy := *product
+	return &productCopy, nil
+}
+
+func (c *MockCatalogClient) GetVariant(sku string) (*Product, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	variant, ok := c.variants[sku]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+
+	variantCopy := *variant
+	return &variantCopy, nil
+}
+
+// SetProduct registers a product, or a variant when SKU is set.
+func (c *MockCatalogClient) SetProduct(product *Product) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if product.SKU != "" {
+		c.variants[product.SKU] = product
+		return
+	}
+	c.products[product.ProductCode] = product
+}
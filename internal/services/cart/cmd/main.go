@@ -4,23 +4,76 @@ import (
 	"os"
 
 	confighelper "github.com/dinosgnk/agora-project/internal/pkg/config"
+	"github.com/dinosgnk/agora-project/internal/pkg/httpx"
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
 	"github.com/dinosgnk/agora-project/internal/pkg/server"
+	"github.com/dinosgnk/agora-project/internal/services/cart/catalog"
 	"github.com/dinosgnk/agora-project/internal/services/cart/config"
+	"github.com/dinosgnk/agora-project/internal/services/cart/enums"
 	"github.com/dinosgnk/agora-project/internal/services/cart/handler"
+	"github.com/dinosgnk/agora-project/internal/services/cart/messaging"
 	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
 	"github.com/dinosgnk/agora-project/internal/services/cart/service"
+	"github.com/dinosgnk/agora-project/internal/services/cart/token"
 )
 
 func main() {
 	log := logger.NewLogger()
 	cfg := confighelper.LoadConfig[config.AppConfig](log)
 
+	mergeStrategy := enums.MergeStrategy(cfg.MergeStrategy)
+	if !service.IsValidMergeStrategy(mergeStrategy) {
+		log.Error("Invalid CART_MERGE_STRATEGY, expected sum, max or newest", "strategy", cfg.MergeStrategy)
+		os.Exit(1)
+	}
+
 	cartRepository := repository.NewInMemoryRepository()
-	cartService := service.NewCartService(cartRepository)
-	cartHandler := handler.NewCartHandler(cartService, log)
+	promotionRepository := repository.NewPostgresPromotionRepository(log)
+	if promotionRepository == nil {
+		log.Error("Failed to initialize promotion repository")
+		os.Exit(1)
+	}
+
+	catalogClient := catalog.NewHttpCatalogClient(cfg.CatalogServiceUrl)
+	cartService := service.NewCartService(cartRepository, promotionRepository, catalogClient)
+	cartHandler := handler.NewCartHandler(
+		cartService,
+		token.NewSigner(cfg.CartTokenSecret),
+		mergeStrategy,
+		log,
+	)
+
+	wishlistHandler := handler.NewWishlistHandler(cartService, log)
+
+	if cfg.WishlistPriceTracking {
+		rabbitClient, err := rabbitmq.NewRabbitMQClient(log)
+		if err != nil {
+			log.Error("Failed to connect to RabbitMQ", "error", err)
+			os.Exit(1)
+		}
+		defer rabbitClient.Close()
+
+		publisher, err := messaging.NewPublisher(rabbitClient)
+		if err != nil {
+			log.Error("Failed to initialize event publisher", "error", err)
+			os.Exit(1)
+		}
+
+		priceDropTracker := service.NewPriceDropTracker(cartRepository, catalogClient, publisher)
+		priceChangeConsumer, err := messaging.NewPriceChangeConsumer(rabbitClient, priceDropTracker, log)
+		if err != nil {
+			log.Error("Failed to initialize wishlist price change consumer", "error", err)
+			os.Exit(1)
+		}
+
+		if err := priceChangeConsumer.Start(); err != nil {
+			log.Error("Failed to start wishlist price change consumer", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	server := server.NewServer(cfg.Port, cartHandler, log, cfg.Service)
+	server := server.NewServer(cfg.Port, httpx.Handlers{cartHandler, wishlistHandler}, log, cfg.Service)
 	if err := server.Run(); err != nil {
 		os.Exit(1)
 	}
@@ -4,4 +4,13 @@ type AppConfig struct {
 	Environment string `env:"ENVIRONMENT"`
 	Port        string `env:"PORT"`
 	Service     string `env:"SERVICE_NAME"`
+
+	CartTokenSecret string `env:"CART_TOKEN_SECRET,required"`
+	MergeStrategy   string `env:"CART_MERGE_STRATEGY" envDefault:"sum"`
+
+	CatalogServiceUrl string `env:"CATALOG_SERVICE_URL" envDefault:"http://localhost:8081"`
+
+	// WishlistPriceTracking consumes catalog events to refresh wishlist
+	// prices and publish price drops. It requires RabbitMQ.
+	WishlistPriceTracking bool `env:"WISHLIST_PRICE_TRACKING" envDefault:"false"`
 }
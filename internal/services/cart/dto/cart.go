@@ -1,15 +1,33 @@
 package dto
 
+import "github.com/dinosgnk/agora-project/internal/services/cart/enums"
+
 type Item struct {
-	ProductCode string  `json:"product_code"`
-	Name        string  `json:"name"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
+	ProductCode  string         `json:"product_code"`
+	SKU          string         `json:"sku,omitempty"`
+	Name         string         `json:"name"`
+	Category     string         `json:"category"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Quantity     int            `json:"quantity"`
+	Price        float64        `json:"price"`
+	PriceChanged bool           `json:"price_changed,omitempty"`
+	CurrentPrice float64        `json:"current_price,omitempty"`
+}
+
+type AppliedDiscount struct {
+	CouponCode  string  `json:"coupon_code"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
 }
 
 type CartResponse struct {
-	UserId string `json:"user_id"`
-	Items  []Item `json:"items"`
+	UserId        string            `json:"user_id"`
+	Items         []Item            `json:"items"`
+	CouponCodes   []string          `json:"coupon_codes"`
+	Discounts     []AppliedDiscount `json:"discounts"`
+	Subtotal      float64           `json:"subtotal"`
+	DiscountTotal float64           `json:"discount_total"`
+	Total         float64           `json:"total"`
 }
 
 type AddItemRequest struct {
@@ -20,8 +38,11 @@ type AddItemRequest struct {
 type RemoveItemRequest struct {
 	UserId      string `json:"user_id"`
 	ProductCode string `json:"product_code"`
+	SKU         string `json:"sku"`
 }
 
+// UpdateCartRequest maps line keys (the SKU for variants, otherwise the
+// product code) to their new quantities.
 type UpdateCartRequest struct {
 	UserId string         `json:"user_id"`
 	Items  map[string]int `json:"items"`
@@ -30,3 +51,17 @@ type UpdateCartRequest struct {
 type ClearCartRequest struct {
 	UserId string `json:"user_id"`
 }
+
+type ApplyCouponRequest struct {
+	CouponCode string `json:"coupon_code"`
+}
+
+type GuestCartResponse struct {
+	CartToken string `json:"cart_token"`
+}
+
+type MergeCartRequest struct {
+	UserId    string              `json:"user_id"`
+	CartToken string              `json:"cart_token"`
+	Strategy  enums.MergeStrategy `json:"strategy"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+type CreateWishlistRequest struct {
+	Name string `json:"name"`
+}
+
+// WishlistItemRequest adds a product, or a variant when SKU is set, to a
+// wishlist. TrackPrice opts into price drop events for the item.
+type WishlistItemRequest struct {
+	ProductCode string `json:"product_code"`
+	SKU         string `json:"sku"`
+	TrackPrice  bool   `json:"track_price"`
+}
+
+type MoveToCartRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+type WishlistItem struct {
+	ProductCode string         `json:"product_code"`
+	SKU         string         `json:"sku,omitempty"`
+	Name        string         `json:"name"`
+	Category    string         `json:"category"`
+	Attributes  map[string]any `json:"attributes,omitempty"`
+	Price       float64        `json:"price"`
+	TrackPrice  bool           `json:"track_price"`
+	AddedAt     time.Time      `json:"added_at"`
+}
+
+type WishlistResponse struct {
+	UserId    string         `json:"user_id"`
+	Name      string         `json:"name"`
+	Items     []WishlistItem `json:"items"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// MoveResponse holds the cart and wishlist after an item moved between them.
+type MoveResponse struct {
+	Cart     *CartResponse     `json:"cart"`
+	Wishlist *WishlistResponse `json:"wishlist"`
+}
@@ -0,0 +1,9 @@
+package enums
+
+type MergeStrategy string
+
+const (
+	MergeStrategySum    MergeStrategy = "sum"
+	MergeStrategyMax    MergeStrategy = "max"
+	MergeStrategyNewest MergeStrategy = "newest"
+)
@@ -2,36 +2,95 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
 	"github.com/dinosgnk/agora-project/internal/services/cart/dto"
+	"github.com/dinosgnk/agora-project/internal/services/cart/enums"
 	"github.com/dinosgnk/agora-project/internal/services/cart/service"
+	"github.com/dinosgnk/agora-project/internal/services/cart/token"
 )
 
+const CartTokenHeader = "X-Cart-Token"
+
 type CartHandler struct {
-	service service.ICartService
-	log     logger.Logger
+	service       service.ICartService
+	signer        *token.Signer
+	mergeStrategy enums.MergeStrategy
+	log           logger.Logger
 }
 
-func NewCartHandler(s service.ICartService, l logger.Logger) *CartHandler {
+func NewCartHandler(s service.ICartService, signer *token.Signer, mergeStrategy enums.MergeStrategy, l logger.Logger) *CartHandler {
 	return &CartHandler{
-		service: s,
-		log:     l,
+		service:       s,
+		signer:        signer,
+		mergeStrategy: mergeStrategy,
+		log:           l,
 	}
 }
 
 func (h *CartHandler) RegisterRoutes(mux *http.ServeMux) http.Handler {
-	mux.HandleFunc("/cart/{userId}", h.GetCart)
-	mux.HandleFunc("/cart/item/add/{userId}", h.AddItem)
-	mux.HandleFunc("/cart/item/delete/{userId}", h.RemoveItem)
-	mux.HandleFunc("/cart/update/{userId}", h.UpdateCart)
-	mux.HandleFunc("/cart/clear/{userId}", h.ClearCart)
+	mux.HandleFunc("GET /cart/{userId}", h.GetCart)
+	mux.HandleFunc("POST /cart/item/add/{userId}", h.AddItem)
+	mux.HandleFunc("DELETE /cart/item/delete/{userId}", h.RemoveItem)
+	mux.HandleFunc("PUT /cart/update/{userId}", h.UpdateCart)
+	mux.HandleFunc("DELETE /cart/clear/{userId}", h.ClearCart)
+	mux.HandleFunc("POST /cart/{userId}/coupons", h.ApplyCoupon)
+	mux.HandleFunc("DELETE /cart/{userId}/coupons/{couponCode}", h.RemoveCoupon)
+
+	// Guest carts are addressed by the signed token in the X-Cart-Token header
+	mux.HandleFunc("POST /cart/guest", h.CreateGuestCart)
+	mux.HandleFunc("GET /cart/guest", h.GetCart)
+	mux.HandleFunc("POST /cart/guest/item/add", h.AddItem)
+	mux.HandleFunc("DELETE /cart/guest/item/delete", h.RemoveItem)
+	mux.HandleFunc("PUT /cart/guest/update", h.UpdateCart)
+	mux.HandleFunc("DELETE /cart/guest/clear", h.ClearCart)
+	mux.HandleFunc("POST /cart/guest/coupons", h.ApplyCoupon)
+	mux.HandleFunc("DELETE /cart/guest/coupons/{couponCode}", h.RemoveCoupon)
+	mux.HandleFunc("POST /cart/merge", h.MergeCarts)
 	return mux
 }
 
+// cartKey resolves the cart a request addresses: the userId path value on
+// user routes, or the cart id carried by a valid guest token otherwise.
+func (h *CartHandler) cartKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if userId := r.PathValue("userId"); userId != "" {
+		if strings.HasPrefix(userId, service.GuestCartPrefix) {
+			http.Error(w, "Guest carts require a cart token", http.StatusUnauthorized)
+			return "", false
+		}
+		return userId, true
+	}
+
+	cartId, err := h.signer.Verify(r.Header.Get(CartTokenHeader))
+	if err != nil {
+		h.log.Warn("Invalid guest cart token", "path", r.URL.Path)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return "", false
+	}
+	return cartId, true
+}
+
+// itemErrorStatus maps catalog validation errors on item changes to a status code.
+func itemErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUnknownProduct):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrOutOfStock),
+		errors.Is(err, service.ErrQuantityExceeded),
+		errors.Is(err, service.ErrInvalidQuantity):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
 func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
-	userId := r.PathValue("userId")
+	userId, ok := h.cartKey(w, r)
+	if !ok {
+		return
+	}
 	basket, err := h.service.GetCartByUserId(userId)
 	if err != nil {
 		h.log.Error("Failed to get cart", "user_id", userId, "error", err.Error())
@@ -44,7 +103,10 @@ func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
-	userId := r.PathValue("userId")
+	userId, ok := h.cartKey(w, r)
+	if !ok {
+		return
+	}
 
 	var req dto.AddItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -55,14 +117,16 @@ func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 
 	itemToAdd := &dto.Item{
 		ProductCode: req.Item.ProductCode,
+		SKU:         req.Item.SKU,
 		Name:        req.Item.Name,
+		Category:    req.Item.Category,
 		Quantity:    req.Item.Quantity,
 		Price:       req.Item.Price,
 	}
 
 	if err := h.service.AddItem(userId, itemToAdd); err != nil {
-		h.log.Error("Failed to add item to cart", "user_id", userId, "product_code", req.Item.ProductCode, "error", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.log.Error("Failed to add item to cart", "user_id", userId, "product_code", req.Item.ProductCode, "sku", req.Item.SKU, "error", err.Error())
+		http.Error(w, err.Error(), itemErrorStatus(err))
 		return
 	}
 
@@ -70,7 +134,10 @@ func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
-	userId := r.PathValue("userId")
+	userId, ok := h.cartKey(w, r)
+	if !ok {
+		return
+	}
 
 	var req dto.RemoveItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -79,8 +146,13 @@ func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.RemoveItem(userId, req.ProductCode); err != nil {
-		h.log.Error("Failed to remove item from cart", "user_id", userId, "product_code", req.ProductCode, "error", err.Error())
+	itemKey := req.ProductCode
+	if req.SKU != "" {
+		itemKey = req.SKU
+	}
+
+	if err := h.service.RemoveItem(userId, itemKey); err != nil {
+		h.log.Error("Failed to remove item from cart", "user_id", userId, "item", itemKey, "error", err.Error())
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
@@ -89,7 +161,10 @@ func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CartHandler) UpdateCart(w http.ResponseWriter, r *http.Request) {
-	userId := r.PathValue("userId")
+	userId, ok := h.cartKey(w, r)
+	if !ok {
+		return
+	}
 
 	var req dto.UpdateCartRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -100,14 +175,17 @@ func (h *CartHandler) UpdateCart(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.service.UpdateCart(userId, req.Items); err != nil {
 		h.log.Error("Failed to update cart", "user_id", userId, "error", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), itemErrorStatus(err))
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
-	userId := r.PathValue("userId")
+	userId, ok := h.cartKey(w, r)
+	if !ok {
+		return
+	}
 
 	if err := h.service.ClearCart(userId); err != nil {
 		h.log.Error("Failed to clear cart", "user_id", userId, "error", err.Error())
@@ -117,3 +195,104 @@ func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *CartHandler) ApplyCoupon(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.cartKey(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ApplyCouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CouponCode == "" {
+		h.log.Warn("Invalid request body for apply coupon", "user_id", userId)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.ApplyCoupon(userId, req.CouponCode); err != nil {
+		h.log.Warn("Failed to apply coupon", "user_id", userId, "coupon_code", req.CouponCode, "error", err.Error())
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidCoupon):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, service.ErrCouponsUnavailable):
+			status = http.StatusNotImplemented
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	cart, err := h.service.GetCartByUserId(userId)
+	if err != nil {
+		h.log.Error("Failed to get cart", "user_id", userId, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cart)
+}
+
+func (h *CartHandler) RemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.cartKey(w, r)
+	if !ok {
+		return
+	}
+	couponCode := r.PathValue("couponCode")
+
+	if err := h.service.RemoveCoupon(userId, couponCode); err != nil {
+		h.log.Error("Failed to remove coupon", "user_id", userId, "coupon_code", couponCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CartHandler) CreateGuestCart(w http.ResponseWriter, r *http.Request) {
+	cartId, err := h.service.CreateGuestCart()
+	if err != nil {
+		h.log.Error("Failed to create guest cart", "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.GuestCartResponse{CartToken: h.signer.Sign(cartId)})
+}
+
+func (h *CartHandler) MergeCarts(w http.ResponseWriter, r *http.Request) {
+	var req dto.MergeCartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserId == "" {
+		h.log.Warn("Invalid request body for merge carts")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	guestCartId, err := h.signer.Verify(req.CartToken)
+	if err != nil {
+		h.log.Warn("Invalid guest cart token for merge", "user_id", req.UserId)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	strategy := h.mergeStrategy
+	if req.Strategy != "" {
+		strategy = req.Strategy
+	}
+
+	cart, err := h.service.MergeCarts(guestCartId, req.UserId, strategy)
+	if err != nil {
+		h.log.Error("Failed to merge carts", "user_id", req.UserId, "strategy", strategy, "error", err.Error())
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidMergeStrategy) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cart)
+}
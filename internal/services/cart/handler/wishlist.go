@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/cart/dto"
+	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
+	"github.com/dinosgnk/agora-project/internal/services/cart/service"
+)
+
+type WishlistHandler struct {
+	service service.IWishlistService
+	log     logger.Logger
+}
+
+func NewWishlistHandler(s service.IWishlistService, l logger.Logger) *WishlistHandler {
+	return &WishlistHandler{
+		service: s,
+		log:     l,
+	}
+}
+
+func (h *WishlistHandler) RegisterRoutes(mux *http.ServeMux) http.Handler {
+	mux.HandleFunc("GET /wishlists/{userId}", h.GetWishlists)
+	mux.HandleFunc("POST /wishlists/{userId}", h.CreateWishlist)
+	mux.HandleFunc("GET /wishlists/{userId}/{listName}", h.GetWishlist)
+	mux.HandleFunc("DELETE /wishlists/{userId}/{listName}", h.DeleteWishlist)
+	mux.HandleFunc("POST /wishlists/{userId}/{listName}/items", h.AddItem)
+	mux.HandleFunc("DELETE /wishlists/{userId}/{listName}/items/{itemKey}", h.RemoveItem)
+	mux.HandleFunc("POST /wishlists/{userId}/{listName}/items/{itemKey}/move-to-cart", h.MoveToCart)
+	mux.HandleFunc("POST /wishlists/{userId}/{listName}/save-for-later", h.SaveForLater)
+	return mux
+}
+
+// userId returns the user a wishlist route addresses. Wishlists belong to
+// registered users, so guest cart ids are rejected.
+func (h *WishlistHandler) userId(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userId := r.PathValue("userId")
+	if strings.HasPrefix(userId, service.GuestCartPrefix) {
+		http.Error(w, "Wishlists are not available for guest carts", http.StatusForbidden)
+		return "", false
+	}
+	return userId, true
+}
+
+// wishlistErrorStatus maps wishlist and cart item errors to a status code.
+func wishlistErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidWishlistName):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrWishlistNotFound),
+		errors.Is(err, service.ErrWishlistItemNotFound),
+		errors.Is(err, service.ErrCartItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrWishlistExists):
+		return http.StatusConflict
+	}
+	return itemErrorStatus(err)
+}
+
+func (h *WishlistHandler) GetWishlists(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.userId(w, r)
+	if !ok {
+		return
+	}
+
+	wishlists, err := h.service.GetWishlists(userId)
+	if err != nil {
+		h.log.Error("Failed to get wishlists", "user_id", userId, "error", err.Error())
+		http.Error(w, err.Error(), wishlistErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(wishlists)
+}
+
+func (h *WishlistHandler) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.userId(w, r)
+	if !ok {
+		return
+	}
+	listName := r.PathValue("listName")
+
+	wishlist, err := h.service.GetWishlist(userId, listName)
+	if err != nil {
+		h.log.Error("Failed to get wishlist", "user_id", userId, "wishlist", listName, "error", err.Error())
+		http.Error(w, err.Error(), wishlistErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(wishlist)
+}
+
+func (h *WishlistHandler) CreateWishlist(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.userId(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateWishlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for create wishlist", "error", err.Error())
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	wishlist, err := h.service.CreateWishlist(userId, req.Name)
+	if err != nil {
+		h.log.Error("Failed to create wishlist", "user_id", userId, "wishlist", req.Name, "error", err.Error())
+		http.Error(w, err.Error(), wishlistErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(wishlist)
+}
+
+func (h *WishlistHandler) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.userId(w, r)
+	if !ok {
+		return
+	}
+	listName := r.PathValue("listName")
+
+	if err := h.service.DeleteWishlist(userId, listName); err != nil {
+		h.log.Error("Failed to delete wishlist", "user_id", userId, "wishlist", listName, "error", err.Error())
+		http.Error(w, err.Error(), wishlistErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WishlistHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.userId(w, r)
+	if !ok {
+		return
+	}
+	listName := r.PathValue("listName")
+
+	var req dto.WishlistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for add wishlist item", "error", err.Error())
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	wishlist, err := h.service.AddWishlistItem(userId, listName, &req)
+	if err != nil {
+		h.log.Error("Failed to add item to wishlist", "user_id", userId, "wishlist", listName, "product_code", req.ProductCode, "sku", req.SKU, "error", err.Error())
+		http.Error(w, err.Error(), wishlistErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(wishlist)
+}
+
+func (h *WishlistHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.userId(w, r)
+	if !ok {
+		return
+	}
+	listName := r.PathValue("listName")
+	itemKey := r.PathValue("itemKey")
+
+	if _, err := h.service.RemoveWishlistItem(userId, listName, itemKey); err != nil {
+		h.log.Error("Failed to remove item from wishlist", "user_id", userId, "wishlist", listName, "item", itemKey, "error", err.Error())
+		http.Error(w, err.Error(), wishlistErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WishlistHandler) MoveToCart(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.userId(w, r)
+	if !ok {
+		return
+	}
+	listName := r.PathValue("listName")
+	itemKey := r.PathValue("itemKey")
+
+	// The body is optional and defaults to a quantity of one
+	var req dto.MoveToCartRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.log.Warn("Invalid request body for move to cart", "error", err.Error())
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	moved, err := h.service.MoveToCart(userId, listName, itemKey, req.Quantity)
+	if err != nil {
+		h.log.Error("Failed to move wishlist item to cart", "user_id", userId, "wishlist", listName, "item", itemKey, "error", err.Error())
+		http.Error(w, err.Error(), wishlistErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(moved)
+}
+
+func (h *WishlistHandler) SaveForLater(w http.ResponseWriter, r *http.Request) {
+	userId, ok := h.userId(w, r)
+	if !ok {
+		return
+	}
+	listName := r.PathValue("listName")
+
+	var req dto.WishlistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.ProductCode == "" && req.SKU == "") {
+		h.log.Warn("Invalid request body for save for later", "user_id", userId)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	moved, err := h.service.SaveForLater(userId, listName, &req)
+	if err != nil {
+		h.log.Error("Failed to save cart item for later", "user_id", userId, "wishlist", listName, "product_code", req.ProductCode, "sku", req.SKU, "error", err.Error())
+		http.Error(w, err.Error(), wishlistErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(moved)
+}
@@ -0,0 +1,86 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+)
+
+const (
+	priceDropQueue = "cart.wishlist-price-drops"
+)
+
+type PriceChangeHandler interface {
+	HandlePriceChange(productCode string, price *float64) error
+}
+
+// PriceChangeConsumer forwards catalog events that may have changed the price
+// of a product or of its variants to wishlist price tracking.
+type PriceChangeConsumer struct {
+	client  rabbitmq.Broker
+	handler PriceChangeHandler
+	log     logger.Logger
+}
+
+func NewPriceChangeConsumer(client rabbitmq.Broker, handler PriceChangeHandler, log logger.Logger) (*PriceChangeConsumer, error) {
+	if err := client.DeclareExchange(events.CatalogExchange, "topic"); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	if err := client.DeclareQueue(priceDropQueue); err != nil {
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	for _, routingKey := range []string{events.ProductUpdated, events.ProductImported} {
+		if err := client.BindQueue(priceDropQueue, events.CatalogExchange, routingKey); err != nil {
+			return nil, fmt.Errorf("failed to bind queue: %w", err)
+		}
+	}
+
+	if err := client.DeclareRetryQueues(priceDropQueue, rabbitmq.DefaultRetryPolicy); err != nil {
+		return nil, fmt.Errorf("failed to declare retry queues: %w", err)
+	}
+
+	return &PriceChangeConsumer{
+		client:  client,
+		handler: handler,
+		log:     log,
+	}, nil
+}
+
+func (c *PriceChangeConsumer) Start() error {
+	c.log.Info("Starting wishlist price change consumer", "queue", priceDropQueue)
+
+	router := rabbitmq.NewRouter(c.log)
+	router.AddMiddleware(rabbitmq.Tracing())
+	router.AddMiddleware(rabbitmq.Logging(c.log))
+	router.AddMiddleware(rabbitmq.Metrics())
+	router.AddMiddleware(rabbitmq.Recovery(c.log))
+	rabbitmq.Handle(router, events.ProductUpdated, c.handleProductUpdated)
+	rabbitmq.Handle(router, events.ProductImported, c.handleProductImported)
+
+	_, err := c.client.ConsumeWithOptions(context.Background(), priceDropQueue, router.BuildHandler(), rabbitmq.ConsumeOptions{})
+	return err
+}
+
+// handleProductUpdated passes on the new price of price changes. Variant
+// changes carry no variant prices, so those are looked up.
+func (c *PriceChangeConsumer) handleProductUpdated(_ context.Context, event events.Envelope[events.ProductUpdatedEvent]) error {
+	if slices.Contains(event.Data.ChangedFields, "price") {
+		return c.handler.HandlePriceChange(event.Data.ProductCode, &event.Data.NewPrice)
+	}
+	if slices.Contains(event.Data.ChangedFields, "variants") {
+		return c.handler.HandlePriceChange(event.Data.ProductCode, nil)
+	}
+	return nil
+}
+
+// handleProductImported tracks every import, since imports carry no changed
+// fields and may change any price.
+func (c *PriceChangeConsumer) handleProductImported(_ context.Context, event events.Envelope[events.ProductImportedEvent]) error {
+	return c.handler.HandlePriceChange(event.Data.ProductCode, &event.Data.Price)
+}
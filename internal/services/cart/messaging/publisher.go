@@ -0,0 +1,29 @@
+package messaging
+
+import (
+	"fmt"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+)
+
+// producer identifies the service in the envelope of its events
+const producer = "cart-service"
+
+type Publisher struct {
+	client rabbitmq.Broker
+}
+
+func NewPublisher(client rabbitmq.Broker) (*Publisher, error) {
+	if err := client.DeclareExchange(events.CartExchange, "topic"); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	return &Publisher{
+		client: client,
+	}, nil
+}
+
+func (p *Publisher) PublishWishlistPriceDropped(event *events.WishlistPriceDroppedEvent) error {
+	return p.client.PublishMessage(events.CartExchange, events.WishlistPriceDropped, events.New(producer, *event))
+}
@@ -1,13 +1,34 @@
 package model
 
+import "time"
+
+// Item is a cart line. Lines for product variants carry the variant SKU and
+// its attributes; ProductCode is always the parent product.
 type Item struct {
-	ProductCode string  `json:"product_code"`
-	Name        string  `json:"name"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
+	ProductCode string         `json:"product_code"`
+	SKU         string         `json:"sku,omitempty"`
+	Name        string         `json:"name"`
+	Category    string         `json:"category"`
+	Attributes  map[string]any `json:"attributes,omitempty"`
+	Quantity    int            `json:"quantity"`
+	Price       float64        `json:"price"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Key identifies the line within a cart: the SKU for variants, otherwise
+// the product code.
+func (i *Item) Key() string {
+	if i.SKU != "" {
+		return i.SKU
+	}
+	return i.ProductCode
 }
 
+// Cart is keyed by UserId. Guest carts use a generated id in the same field
+// until they are merged into a user's cart.
 type Cart struct {
-	UserId string  `json:"user_id"`
-	Items  []*Item `json:"items"`
+	UserId      string   `json:"user_id"`
+	Guest       bool     `json:"guest"`
+	Items       []*Item  `json:"items"`
+	CouponCodes []string `json:"coupon_codes"`
 }
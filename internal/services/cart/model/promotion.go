@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// CouponRedemption is written by order-service when an order is placed with a
+// coupon and read here to enforce per-user usage limits.
+type CouponRedemption struct {
+	ID         string    `gorm:"primaryKey;column:id"`
+	CouponCode string    `gorm:"column:coupon_code"`
+	UserID     string    `gorm:"column:user_id"`
+	OrderID    string    `gorm:"column:order_id"`
+	Amount     float64   `gorm:"column:amount"`
+	RedeemedAt time.Time `gorm:"column:redeemed_at;autoCreateTime"`
+}
+
+func (CouponRedemption) TableName() string {
+	return "promotions.t_coupon_redemption"
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// WishlistItem is a product saved to a wishlist. Price is the catalog price
+// last seen for it; when TrackPrice is set, a fall below it is reported as a
+// price drop.
+type WishlistItem struct {
+	ProductCode string         `json:"product_code"`
+	SKU         string         `json:"sku,omitempty"`
+	Name        string         `json:"name"`
+	Category    string         `json:"category"`
+	Attributes  map[string]any `json:"attributes,omitempty"`
+	Price       float64        `json:"price"`
+	TrackPrice  bool           `json:"track_price"`
+	AddedAt     time.Time      `json:"added_at"`
+}
+
+// Key identifies the item within a wishlist, like Item.Key does for cart lines.
+func (i *WishlistItem) Key() string {
+	if i.SKU != "" {
+		return i.SKU
+	}
+	return i.ProductCode
+}
+
+// Wishlist is a named list of a user's saved items. Names are unique per user.
+type Wishlist struct {
+	UserId    string          `json:"user_id"`
+	Name      string          `json:"name"`
+	Items     []*WishlistItem `json:"items"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
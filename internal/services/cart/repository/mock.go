@@ -2,19 +2,23 @@ package repository
 
 import (
 	"errors"
+	"slices"
+	"sort"
 	"sync"
 
 	"github.com/dinosgnk/agora-project/internal/services/cart/model"
 )
 
 type MockCartRepository struct {
-	data map[string]*model.Cart
-	mu   sync.RWMutex
+	data      map[string]*model.Cart
+	wishlists map[string]map[string]*model.Wishlist
+	mu        sync.RWMutex
 }
 
 func NewMockCartRepository() *MockCartRepository {
 	return &MockCartRepository{
-		data: make(map[string]*model.Cart),
+		data:      make(map[string]*model.Cart),
+		wishlists: make(map[string]map[string]*model.Wishlist),
 	}
 }
 
@@ -43,3 +47,116 @@ func (cm *MockCartRepository) Clear(userId string) error {
 	delete(cm.data, userId)
 	return nil
 }
+
+func (cm *MockCartRepository) MergeCarts(sourceId string, targetId string, merge MergeFunc) (*model.Cart, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	source, ok := cm.data[sourceId]
+	if !ok {
+		return nil, errors.New("Cart not found")
+	}
+
+	merged := merge(source, cm.data[targetId])
+	merged.UserId = targetId
+	cm.data[targetId] = merged
+	delete(cm.data, sourceId)
+	return merged, nil
+}
+
+func (cm *MockCartRepository) GetWishlists(userId string) ([]*model.Wishlist, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	wishlists := make([]*model.Wishlist, 0, len(cm.wishlists[userId]))
+	for _, wishlist := range cm.wishlists[userId] {
+		wishlists = append(wishlists, cloneWishlist(wishlist))
+	}
+	sort.Slice(wishlists, func(i, j int) bool { return wishlists[i].Name < wishlists[j].Name })
+	return wishlists, nil
+}
+
+func (cm *MockCartRepository) GetWishlist(userId string, name string) (*model.Wishlist, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if wishlist, ok := cm.wishlists[userId][name]; ok {
+		return cloneWishlist(wishlist), nil
+	}
+	return nil, ErrWishlistNotFound
+}
+
+func (cm *MockCartRepository) ModifyWishlist(userId string, name string, modify WishlistFunc) (*model.Wishlist, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	wishlist := cm.wishlistCopy(userId, name)
+	if err := modify(wishlist); err != nil {
+		return nil, err
+	}
+
+	cm.storeWishlist(wishlist)
+	return cloneWishlist(wishlist), nil
+}
+
+func (cm *MockCartRepository) DeleteWishlist(userId string, name string) (bool, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if _, ok := cm.wishlists[userId][name]; !ok {
+		return false, nil
+	}
+	delete(cm.wishlists[userId], name)
+	return true, nil
+}
+
+func (cm *MockCartRepository) GetWishlistsByProduct(productCode string) ([]*model.Wishlist, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	var wishlists []*model.Wishlist
+	for _, userWishlists := range cm.wishlists {
+		for _, wishlist := range userWishlists {
+			if slices.ContainsFunc(wishlist.Items, func(item *model.WishlistItem) bool {
+				return item.ProductCode == productCode
+			}) {
+				wishlists = append(wishlists, cloneWishlist(wishlist))
+			}
+		}
+	}
+	return wishlists, nil
+}
+
+func (cm *MockCartRepository) MoveItems(userId string, wishlistName string, move MoveFunc) (*model.Cart, *model.Wishlist, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cart := &model.Cart{UserId: userId, Items: []*model.Item{}}
+	if existing, ok := cm.data[userId]; ok {
+		cart = cloneCart(existing)
+	}
+	wishlist := cm.wishlistCopy(userId, wishlistName)
+
+	if err := move(cart, wishlist); err != nil {
+		return nil, nil, err
+	}
+
+	cm.data[userId] = cart
+	cm.storeWishlist(wishlist)
+	return cloneCart(cart), cloneWishlist(wishlist), nil
+}
+
+// wishlistCopy returns a copy of the stored wishlist, or an empty one.
+func (cm *MockCartRepository) wishlistCopy(userId string, name string) *model.Wishlist {
+	if existing, ok := cm.wishlists[userId][name]; ok {
+		return cloneWishlist(existing)
+	}
+	return &model.Wishlist{UserId: userId, Name: name, Items: []*model.WishlistItem{}}
+}
+
+func (cm *MockCartRepository) storeWishlist(wishlist *model.Wishlist) {
+	if cm.wishlists[wishlist.UserId] == nil {
+		cm.wishlists[wishlist.UserId] = make(map[string]*model.Wishlist)
+	}
+	cm.wishlists[wishlist.UserId][wishlist.Name] = wishlist
+}
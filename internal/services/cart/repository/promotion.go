@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+)
+
+type IPromotionRepository interface {
+	GetPromotionByCode(code string) (*promotions.Promotion, error)
+	CreatePromotion(promotion *promotions.Promotion) (*promotions.Promotion, error)
+	CountRedemptions(couponCode string, userId string) (int, error)
+}
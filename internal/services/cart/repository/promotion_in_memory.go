@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+	"github.com/dinosgnk/agora-project/internal/services/cart/model"
+)
+
+type InMemoryPromotionRepository struct {
+	promotions  map[string]*promotions.Promotion
+	redemptions []*model.CouponRedemption
+	mu          sync.RWMutex
+}
+
+func NewInMemoryPromotionRepository() *InMemoryPromotionRepository {
+	return &InMemoryPromotionRepository{
+		promotions: make(map[string]*promotions.Promotion),
+	}
+}
+
+func (repo *InMemoryPromotionRepository) GetPromotionByCode(code string) (*promotions.Promotion, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	if promotion, ok := repo.promotions[strings.ToUpper(code)]; ok {
+		return promotion, nil
+	}
+	return nil, errors.New("promotion not found")
+}
+
+func (repo *InMemoryPromotionRepository) CreatePromotion(promotion *promotions.Promotion) (*promotions.Promotion, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key := strings.ToUpper(promotion.Code)
+	if _, exists := repo.promotions[key]; exists {
+		return nil, errors.New("promotion already exists")
+	}
+	repo.promotions[key] = promotion
+	return promotion, nil
+}
+
+func (repo *InMemoryPromotionRepository) CountRedemptions(couponCode string, userId string) (int, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	count := 0
+	for _, redemption := range repo.redemptions {
+		if strings.EqualFold(redemption.CouponCode, couponCode) && redemption.UserID == userId {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// RecordRedemption stands in for order-service writing to the redemption table.
+func (repo *InMemoryPromotionRepository) RecordRedemption(redemption *model.CouponRedemption) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.redemptions = append(repo.redemptions, redemption)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+	"github.com/dinosgnk/agora-project/internal/services/cart/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type PostgresPromotionRepository struct {
+	gormDb *postgres.GormDatabase
+}
+
+func NewPostgresPromotionRepository(logger logger.Logger) *PostgresPromotionRepository {
+	gormDb, err := postgres.NewGormDatabase(
+		logger,
+		&gorm.Config{
+			NamingStrategy: schema.NamingStrategy{
+				TablePrefix:   "promotions.t_",
+				SingularTable: true,
+			},
+		},
+	)
+
+	if err != nil {
+		return nil
+	}
+
+	return &PostgresPromotionRepository{
+		gormDb: gormDb,
+	}
+}
+
+func (repo *PostgresPromotionRepository) GetPromotionByCode(code string) (*promotions.Promotion, error) {
+	var promotion promotions.Promotion
+	result := repo.gormDb.Where("UPPER(code) = UPPER(?)", code).First(&promotion)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &promotion, nil
+}
+
+func (repo *PostgresPromotionRepository) CreatePromotion(promotion *promotions.Promotion) (*promotions.Promotion, error) {
+	result := repo.gormDb.Create(promotion)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return promotion, nil
+}
+
+func (repo *PostgresPromotionRepository) CountRedemptions(couponCode string, userId string) (int, error) {
+	var count int64
+	result := repo.gormDb.Model(&model.CouponRedemption{}).
+		Where("UPPER(coupon_code) = UPPER(?) AND user_id = ?", couponCode, userId).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return int(count), nil
+}
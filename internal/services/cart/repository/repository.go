@@ -1,11 +1,48 @@
 package repository
 
 import (
+	"errors"
+
 	"github.com/dinosgnk/agora-project/internal/services/cart/model"
 )
 
+var ErrWishlistNotFound = errors.New("wishlist not found")
+
+// MergeFunc combines a source cart into a target cart. target is nil when the
+// target cart does not exist yet.
+type MergeFunc func(source *model.Cart, target *model.Cart) *model.Cart
+
+// WishlistFunc modifies a wishlist in place. A wishlist that does not exist
+// yet is passed empty with a zero CreatedAt. When WishlistFunc returns an
+// error the wishlist is not stored.
+type WishlistFunc func(wishlist *model.Wishlist) error
+
+// MoveFunc moves items between a user's cart and one of their wishlists by
+// modifying them in place. A cart or wishlist that does not exist yet is
+// passed empty, with a zero CreatedAt for the wishlist. When MoveFunc returns
+// an error neither is stored.
+type MoveFunc func(cart *model.Cart, wishlist *model.Wishlist) error
+
 type ICartRepository interface {
 	GetCartByUserId(userId string) (*model.Cart, error)
 	UpdateCart(cart *model.Cart) error
 	Clear(userId string) error
+	// MergeCarts atomically stores merge(source, target) under targetId and
+	// removes the source cart.
+	MergeCarts(sourceId string, targetId string, merge MergeFunc) (*model.Cart, error)
+
+	// Wishlists are returned as copies and changed through ModifyWishlist or
+	// MoveItems only
+	GetWishlists(userId string) ([]*model.Wishlist, error)
+	GetWishlist(userId string, name string) (*model.Wishlist, error)
+	// ModifyWishlist atomically applies modify to a copy of the wishlist and
+	// stores it
+	ModifyWishlist(userId string, name string, modify WishlistFunc) (*model.Wishlist, error)
+	DeleteWishlist(userId string, name string) (bool, error)
+	// GetWishlistsByProduct returns the wishlists of all users that contain
+	// the product or one of its variants
+	GetWishlistsByProduct(productCode string) ([]*model.Wishlist, error)
+	// MoveItems atomically applies move to copies of the user's cart and
+	// wishlist and stores both.
+	MoveItems(userId string, wishlistName string, move MoveFunc) (*model.Cart, *model.Wishlist, error)
 }
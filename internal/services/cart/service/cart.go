@@ -1,9 +1,18 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+	"github.com/dinosgnk/agora-project/internal/services/cart/catalog"
 	"github.com/dinosgnk/agora-project/internal/services/cart/dto"
+	"github.com/dinosgnk/agora-project/internal/services/cart/enums"
 	"github.com/dinosgnk/agora-project/internal/services/cart/model"
 	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
 )
@@ -11,18 +20,40 @@ import (
 type ICartService interface {
 	GetCartByUserId(userId string) (*dto.CartResponse, error)
 	AddItem(userId string, itemToAdd *dto.Item) error
-	RemoveItem(userId string, productCode string) error
+	RemoveItem(userId string, itemKey string) error
 	UpdateCart(userId string, updatedCart map[string]int) error
 	ClearCart(userId string) error
+	ApplyCoupon(userId string, couponCode string) error
+	RemoveCoupon(userId string, couponCode string) error
+	CreateGuestCart() (string, error)
+	MergeCarts(guestCartId string, userId string, strategy enums.MergeStrategy) (*dto.CartResponse, error)
 }
 
+// GuestCartPrefix marks cart ids generated for anonymous carts so they cannot
+// be addressed through the user routes.
+const GuestCartPrefix = "guest-"
+
+var (
+	ErrInvalidMergeStrategy = errors.New("invalid merge strategy")
+	ErrInvalidQuantity      = errors.New("quantity must be positive")
+	ErrUnknownProduct       = errors.New("unknown product")
+	ErrOutOfStock           = errors.New("insufficient stock")
+	ErrQuantityExceeded     = errors.New("quantity exceeds the per-order limit")
+)
+
 type CartService struct {
-	repo repository.ICartRepository
+	repo          repository.ICartRepository
+	promotionRepo repository.IPromotionRepository
+	catalogClient catalog.ICatalogClient
 }
 
-func NewCartService(repo repository.ICartRepository) *CartService {
+// NewCartService creates a cart service. When catalogClient is nil, item
+// details are taken from the request as-is.
+func NewCartService(repo repository.ICartRepository, promotionRepo repository.IPromotionRepository, catalogClient catalog.ICatalogClient) *CartService {
 	return &CartService{
-		repo: repo,
+		repo:          repo,
+		promotionRepo: promotionRepo,
+		catalogClient: catalogClient,
 	}
 }
 
@@ -32,10 +63,25 @@ func (cs *CartService) GetCartByUserId(userId string) (*dto.CartResponse, error)
 		return nil, err
 	}
 
-	return cs.mapCartModelToDto(cart), nil
+	discounts, err := cs.applicableDiscounts(cart)
+	if err != nil {
+		return nil, err
+	}
+
+	cartResponse := cs.mapCartModelToDto(cart, discounts)
+	cs.flagPriceChanges(cartResponse.Items)
+	return cartResponse, nil
 }
 
 func (cs *CartService) AddItem(userId string, itemToAdd *dto.Item) error {
+	quantity := itemToAdd.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		return ErrInvalidQuantity
+	}
+
 	cart, err := cs.repo.GetCartByUserId(userId)
 	if err != nil {
 		// Cart doesn't exist, create a new one
@@ -45,19 +91,38 @@ func (cs *CartService) AddItem(userId string, itemToAdd *dto.Item) error {
 		}
 	}
 
-	for i, item := range cart.Items {
-		if item.ProductCode == itemToAdd.ProductCode {
-			cart.Items[i].Quantity += 1
-			return cs.repo.UpdateCart(cart)
+	newItem := cs.mapItemDtoToModel(itemToAdd)
+	idx := slices.IndexFunc(cart.Items, func(item *model.Item) bool {
+		return item.Key() == newItem.Key()
+	})
+	if idx >= 0 {
+		quantity += cart.Items[idx].Quantity
+	}
+
+	if cs.catalogClient != nil {
+		product, err := cs.checkAvailability(newItem, quantity)
+		if err != nil {
+			return err
 		}
+
+		newItem.ProductCode = product.ProductCode
+		newItem.Name = product.Name
+		newItem.Category = product.Category
+		newItem.Attributes = product.Attributes
+		newItem.Price = product.Price
 	}
+	newItem.Quantity = quantity
+	newItem.UpdatedAt = time.Now()
 
-	newItem := cs.mapItemDtoToModel(itemToAdd)
-	cart.Items = append(cart.Items, newItem)
+	if idx >= 0 {
+		cart.Items[idx] = newItem
+	} else {
+		cart.Items = append(cart.Items, newItem)
+	}
 	return cs.repo.UpdateCart(cart)
 }
 
-func (cs *CartService) RemoveItem(userId string, productCode string) error {
+func (cs *CartService) RemoveItem(userId string, itemKey string) error {
 	cart, err := cs.repo.GetCartByUserId(userId)
 	if err != nil {
 		return errors.New("cart not found")
@@ -66,7 +131,7 @@ func (cs *CartService) RemoveItem(userId string, productCode string) error {
 	// Filter out the item
 	newItems := make([]*model.Item, 0, len(cart.Items))
 	for _, item := range cart.Items {
-		if item.ProductCode != productCode {
+		if item.Key() != itemKey {
 			newItems = append(newItems, item)
 		}
 	}
@@ -81,16 +146,40 @@ func (cs *CartService) UpdateCart(userId string, updatedCart map[string]int) err
 		return errors.New("cart not found")
 	}
 
-	for i, item := range cart.Items {
-		if newQuantity, exists := updatedCart[item.ProductCode]; exists {
-			if newQuantity == 0 {
-				cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
-				i--
+	// Validate every change before touching the cart so a rejected update
+	// leaves it unchanged
+	for _, item := range cart.Items {
+		newQuantity, exists := updatedCart[item.Key()]
+		if !exists || newQuantity == 0 {
+			continue
+		}
+		if newQuantity < 0 {
+			return ErrInvalidQuantity
+		}
+		if cs.catalogClient != nil {
+			if _, err := cs.checkAvailability(item, newQuantity); err != nil {
+				return err
 			}
-			cart.Items[i].Quantity = newQuantity
 		}
 	}
 
+	items := make([]*model.Item, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		newQuantity, exists := updatedCart[item.Key()]
+		if !exists {
+			items = append(items, item)
+			continue
+		}
+		if newQuantity == 0 {
+			continue
+		}
+
+		item.Quantity = newQuantity
+		item.UpdatedAt = time.Now()
+		items = append(items, item)
+	}
+
+	cart.Items = items
 	return cs.repo.UpdateCart(cart)
 }
 
@@ -103,23 +192,223 @@ func (cs *CartService) ClearCart(userId string) error {
 	return nil
 }
 
+func (cs *CartService) ApplyCoupon(userId string, couponCode string) error {
+	if cs.promotionRepo == nil {
+		return ErrCouponsUnavailable
+	}
+
+	cart, err := cs.repo.GetCartByUserId(userId)
+	if err != nil {
+		return errors.New("cart not found")
+	}
+
+	for _, code := range cart.CouponCodes {
+		if strings.EqualFold(code, couponCode) {
+			return promotions.Invalid("coupon %s is already applied", couponCode)
+		}
+	}
+
+	promotion, err := cs.promotionRepo.GetPromotionByCode(couponCode)
+	if err != nil {
+		return promotions.Invalid("coupon %s does not exist", couponCode)
+	}
+
+	if err := cs.validatePromotion(promotion, cart, time.Now()); err != nil {
+		return err
+	}
+
+	cart.CouponCodes = append(cart.CouponCodes, promotion.Code)
+	return cs.repo.UpdateCart(cart)
+}
+
+func (cs *CartService) RemoveCoupon(userId string, couponCode string) error {
+	cart, err := cs.repo.GetCartByUserId(userId)
+	if err != nil {
+		return errors.New("cart not found")
+	}
+
+	cart.CouponCodes = slices.DeleteFunc(cart.CouponCodes, func(code string) bool {
+		return strings.EqualFold(code, couponCode)
+	})
+	return cs.repo.UpdateCart(cart)
+}
+
+func (cs *CartService) CreateGuestCart() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate guest cart id: %w", err)
+	}
+
+	cart := &model.Cart{
+		UserId:      GuestCartPrefix + hex.EncodeToString(id),
+		Guest:       true,
+		Items:       []*model.Item{},
+		CouponCodes: []string{},
+	}
+	if err := cs.repo.UpdateCart(cart); err != nil {
+		return "", err
+	}
+
+	return cart.UserId, nil
+}
+
+func (cs *CartService) MergeCarts(guestCartId string, userId string, strategy enums.MergeStrategy) (*dto.CartResponse, error) {
+	if !IsValidMergeStrategy(strategy) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMergeStrategy, strategy)
+	}
+
+	if !strings.HasPrefix(guestCartId, GuestCartPrefix) || strings.HasPrefix(userId, GuestCartPrefix) {
+		return nil, errors.New("only guest carts can be merged into user carts")
+	}
+
+	merged, err := cs.repo.MergeCarts(guestCartId, userId, mergeCartsWith(strategy))
+	if err != nil {
+		return nil, err
+	}
+
+	discounts, err := cs.applicableDiscounts(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	cartResponse := cs.mapCartModelToDto(merged, discounts)
+	cs.flagPriceChanges(cartResponse.Items)
+	return cartResponse, nil
+}
+
+// lookupProduct resolves a cart line through the catalog, by SKU for
+// variants and by product code otherwise.
+func (cs *CartService) lookupProduct(item *model.Item) (*catalog.Product, error) {
+	if item.SKU != "" {
+		return cs.catalogClient.GetVariant(item.SKU)
+	}
+	return cs.catalogClient.GetProduct(item.ProductCode)
+}
+
+// checkAvailability resolves a cart line through the catalog and checks that
+// the requested total quantity can be ordered.
+func (cs *CartService) checkAvailability(item *model.Item, quantity int) (*catalog.Product, error) {
+	productCode := item.Key()
+	product, err := cs.lookupProduct(item)
+	if errors.Is(err, catalog.ErrProductNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProduct, productCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkQuantity(product, productCode, quantity); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// checkQuantity checks a requested total quantity of a line against the
+// catalog limits of its product.
+func checkQuantity(product *catalog.Product, productCode string, quantity int) error {
+	if product.MaxOrderQty > 0 && quantity > product.MaxOrderQty {
+		return fmt.Errorf("%w: at most %d of %s per order", ErrQuantityExceeded, product.MaxOrderQty, productCode)
+	}
+
+	if product.Stock <= 0 {
+		return fmt.Errorf("%w: %s is out of stock", ErrOutOfStock, productCode)
+	}
+	if quantity > product.Stock {
+		return fmt.Errorf("%w: only %d of %s available", ErrOutOfStock, product.Stock, productCode)
+	}
+	return nil
+}
+
+// flagPriceChanges marks items whose catalog price differs from the price
+// stored when they were added. Catalog errors are ignored so that the cart
+// can still be viewed when the catalog is unavailable.
+func (cs *CartService) flagPriceChanges(items []dto.Item) {
+	if cs.catalogClient == nil {
+		return
+	}
+
+	for i := range items {
+		product, err := cs.lookupProduct(&model.Item{ProductCode: items[i].ProductCode, SKU: items[i].SKU})
+		if err != nil {
+			continue
+		}
+		if product.Price != items[i].Price {
+			items[i].PriceChanged = true
+			items[i].CurrentPrice = product.Price
+		}
+	}
+}
+
+// applicableDiscounts re-evaluates the coupons stored on the cart against its
+// current contents. Coupons that no longer qualify are left on the cart but
+// contribute no discount.
+func (cs *CartService) applicableDiscounts(cart *model.Cart) ([]dto.AppliedDiscount, error) {
+	discounts := make([]dto.AppliedDiscount, 0, len(cart.CouponCodes))
+	if cs.promotionRepo == nil {
+		return discounts, nil
+	}
+
+	now := time.Now()
+	for _, code := range cart.CouponCodes {
+		promotion, err := cs.promotionRepo.GetPromotionByCode(code)
+		if err != nil {
+			continue
+		}
+
+		if err := cs.validatePromotion(promotion, cart, now); err != nil {
+			if errors.Is(err, ErrInvalidCoupon) {
+				continue
+			}
+			return nil, err
+		}
+
+		discounts = append(discounts, dto.AppliedDiscount{
+			CouponCode:  promotion.Code,
+			Description: promotion.Description,
+			Amount:      promotions.Discount(promotion, promotionItems(cart.Items)),
+		})
+	}
+
+	return discounts, nil
+}
+
 // Helper functions to map between DTOs and Models
-func (cs *CartService) mapCartModelToDto(cart *model.Cart) *dto.CartResponse {
+func (cs *CartService) mapCartModelToDto(cart *model.Cart, discounts []dto.AppliedDiscount) *dto.CartResponse {
 	items := make([]dto.Item, len(cart.Items))
 	for i, item := range cart.Items {
 		items[i] = cs.mapItemModelToDto(item)
 	}
 
+	subtotal := promotions.Subtotal(promotionItems(cart.Items))
+	var discountTotal float64
+	for _, discount := range discounts {
+		discountTotal += discount.Amount
+	}
+	discountTotal = promotions.RoundAmount(min(discountTotal, subtotal))
+
+	couponCodes := cart.CouponCodes
+	if couponCodes == nil {
+		couponCodes = []string{}
+	}
+
 	return &dto.CartResponse{
-		UserId: cart.UserId,
-		Items:  items,
+		UserId:        cart.UserId,
+		Items:         items,
+		CouponCodes:   couponCodes,
+		Discounts:     discounts,
+		Subtotal:      subtotal,
+		DiscountTotal: discountTotal,
+		Total:         promotions.RoundAmount(subtotal - discountTotal),
 	}
 }
 
 func (cs *CartService) mapItemModelToDto(item *model.Item) dto.Item {
 	return dto.Item{
 		ProductCode: item.ProductCode,
+		SKU:         item.SKU,
 		Name:        item.Name,
+		Attributes:  item.Attributes,
+		Category:    item.Category,
 		Quantity:    item.Quantity,
 		Price:       item.Price,
 	}
@@ -128,7 +417,10 @@ func (cs *CartService) mapItemModelToDto(item *model.Item) dto.Item {
 func (cs *CartService) mapItemDtoToModel(item *dto.Item) *model.Item {
 	return &model.Item{
 		ProductCode: item.ProductCode,
+		SKU:         item.SKU,
 		Name:        item.Name,
+		Attributes:  item.Attributes,
+		Category:    item.Category,
 		Quantity:    item.Quantity,
 		Price:       item.Price,
 	}
@@ -9,7 +9,7 @@ import (
 
 func TestGetCartByUserIdSuccessfully(t *testing.T) {
 	repo := repository.NewMockCartRepository()
-	svc := NewCartService(repo)
+	svc := NewCartService(repo, nil, nil)
 
 	userId := "10"
 	itemToAdd := &dto.Item{
@@ -35,7 +35,7 @@ func TestGetCartByUserIdSuccessfully(t *testing.T) {
 
 func TestAddItemToCartSuccessfully(t *testing.T) {
 	repo := repository.NewMockCartRepository()
-	svc := NewCartService(repo)
+	svc := NewCartService(repo, nil, nil)
 
 	userId := "10"
 	itemToAdd := &dto.Item{
@@ -62,7 +62,7 @@ func TestAddItemToCartSuccessfully(t *testing.T) {
 
 func TestRemoveItemFromCartSuccessfully(t *testing.T) {
 	repo := repository.NewMockCartRepository()
-	svc := NewCartService(repo)
+	svc := NewCartService(repo, nil, nil)
 
 	userID := "user123"
 	itemToAdd := &dto.Item{ProductCode: "p1", Name: "Product", Price: 10.0, Quantity: 1}
@@ -81,7 +81,7 @@ func TestRemoveItemFromCartSuccessfully(t *testing.T) {
 
 func TestClearCart(t *testing.T) {
 	repo := repository.NewMockCartRepository()
-	svc := NewCartService(repo)
+	svc := NewCartService(repo, nil, nil)
 
 	userId := "10"
 	itemToAdd := &dto.Item{
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/services/cart/catalog"
+	"github.com/dinosgnk/agora-project/internal/services/cart/dto"
+	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
+)
+
+func newCatalogTestService() (*CartService, *catalog.MockCatalogClient) {
+	catalogClient := catalog.NewMockCatalogClient(
+		&catalog.Product{ProductCode: "p1", Name: "Catalog Name", Category: "Books", Price: 12.5, Stock: 10, MaxOrderQty: 5},
+		&catalog.Product{ProductCode: "p2", Name: "Sold Out", Category: "Toys", Price: 3.0, Stock: 0},
+	)
+
+	return NewCartService(repository.NewMockCartRepository(), nil, catalogClient), catalogClient
+}
+
+func TestAddItemUsesCatalogDetailsAndRequestedQuantity(t *testing.T) {
+	svc, _ := newCatalogTestService()
+
+	userId := "10"
+	err := svc.AddItem(userId, &dto.Item{ProductCode: "p1", Name: "Client Name", Price: 0.01, Quantity: 3})
+	if err != nil {
+		t.Fatalf("Expected no error while adding item to cart, got %v", err)
+	}
+
+	cart, _ := svc.GetCartByUserId(userId)
+	item := cart.Items[0]
+	if item.Name != "Catalog Name" || item.Price != 12.5 || item.Category != "Books" {
+		t.Fatalf("Expected catalog details, got name %s, price %.2f, category %s", item.Name, item.Price, item.Category)
+	}
+
+	if item.Quantity != 3 {
+		t.Fatalf("Expected quantity 3, got %d", item.Quantity)
+	}
+}
+
+func TestAddUnknownProduct(t *testing.T) {
+	svc, _ := newCatalogTestService()
+
+	err := svc.AddItem("10", &dto.Item{ProductCode: "missing", Quantity: 1})
+	if !errors.Is(err, ErrUnknownProduct) {
+		t.Fatalf("Expected unknown product error, got %v", err)
+	}
+}
+
+func TestAddOutOfStockProduct(t *testing.T) {
+	svc, _ := newCatalogTestService()
+
+	err := svc.AddItem("10", &dto.Item{ProductCode: "p2", Quantity: 1})
+	if !errors.Is(err, ErrOutOfStock) {
+		t.Fatalf("Expected out of stock error, got %v", err)
+	}
+}
+
+func TestAddItemBeyondMaxOrderQuantity(t *testing.T) {
+	svc, _ := newCatalogTestService()
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Quantity: 4})
+
+	err := svc.AddItem(userId, &dto.Item{ProductCode: "p1", Quantity: 2})
+	if !errors.Is(err, ErrQuantityExceeded) {
+		t.Fatalf("Expected quantity exceeded error, got %v", err)
+	}
+}
+
+func TestUpdateCartRejectsQuantityAboveLimitWithoutChangingCart(t *testing.T) {
+	svc, _ := newCatalogTestService()
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Quantity: 2})
+
+	err := svc.UpdateCart(userId, map[string]int{"p1": 6})
+	if !errors.Is(err, ErrQuantityExceeded) {
+		t.Fatalf("Expected quantity exceeded error, got %v", err)
+	}
+
+	cart, _ := svc.GetCartByUserId(userId)
+	if cart.Items[0].Quantity != 2 {
+		t.Fatalf("Expected quantity to stay 2, got %d", cart.Items[0].Quantity)
+	}
+}
+
+func TestUpdateCartRemovesItemsSetToZero(t *testing.T) {
+	svc, _ := newCatalogTestService()
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Quantity: 2})
+
+	if err := svc.UpdateCart(userId, map[string]int{"p1": 0}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cart, _ := svc.GetCartByUserId(userId)
+	if len(cart.Items) != 0 {
+		t.Fatalf("Expected empty cart, got %d items", len(cart.Items))
+	}
+}
+
+func TestGetCartFlagsCatalogPriceChanges(t *testing.T) {
+	svc, catalogClient := newCatalogTestService()
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Quantity: 1})
+	catalogClient.SetProduct(&catalog.Product{ProductCode: "p1", Name: "Catalog Name", Price: 10.0, Stock: 10})
+
+	cart, _ := svc.GetCartByUserId(userId)
+	item := cart.Items[0]
+	if !item.PriceChanged || item.CurrentPrice != 10.0 || item.Price != 12.5 {
+		t.Fatalf("Expected price change from 12.50 to 10.00 to be flagged, got %+v", item)
+	}
+}
+
+func TestAddVariantsKeepsSeparateLinesPerSKU(t *testing.T) {
+	svc, catalogClient := newCatalogTestService()
+	catalogClient.SetProduct(&catalog.Product{ProductCode: "shirt", SKU: "shirt-m", Name: "Shirt", Category: "Clothing", Price: 20, Stock: 5, Attributes: map[string]any{"size": "M"}})
+	catalogClient.SetProduct(&catalog.Product{ProductCode: "shirt", SKU: "shirt-l", Name: "Shirt", Category: "Clothing", Price: 22, Stock: 1, Attributes: map[string]any{"size": "L"}})
+
+	userId := "10"
+	if err := svc.AddItem(userId, &dto.Item{SKU: "shirt-m", Quantity: 2}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.AddItem(userId, &dto.Item{SKU: "shirt-l", Quantity: 1}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.AddItem(userId, &dto.Item{SKU: "shirt-l", Quantity: 1}); !errors.Is(err, ErrOutOfStock) {
+		t.Fatalf("Expected variant stock to be enforced, got %v", err)
+	}
+
+	cart, _ := svc.GetCartByUserId(userId)
+	if len(cart.Items) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(cart.Items))
+	}
+	if cart.Items[0].ProductCode != "shirt" || cart.Items[0].Attributes["size"] != "M" || cart.Items[0].Price != 20 {
+		t.Errorf("Expected variant details from the catalog, got %+v", cart.Items[0])
+	}
+
+	if err := svc.UpdateCart(userId, map[string]int{"shirt-m": 0}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.RemoveItem(userId, "shirt-l"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cart, _ = svc.GetCartByUserId(userId)
+	if len(cart.Items) != 0 {
+		t.Errorf("Expected empty cart, got %+v", cart.Items)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/dinosgnk/agora-project/internal/services/cart/enums"
+	"github.com/dinosgnk/agora-project/internal/services/cart/model"
+	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
+)
+
+// mergeCartsWith builds the merge applied by the repository when a guest cart
+// is folded into a user's cart. Items present in only one cart are kept as
+// they are; items present in both are resolved with the given strategy.
+func mergeCartsWith(strategy enums.MergeStrategy) repository.MergeFunc {
+	return func(source *model.Cart, target *model.Cart) *model.Cart {
+		merged := &model.Cart{
+			Items:       []*model.Item{},
+			CouponCodes: []string{},
+		}
+
+		if target != nil {
+			for _, item := range target.Items {
+				itemCopy := *item
+				merged.Items = append(merged.Items, &itemCopy)
+			}
+			merged.CouponCodes = append(merged.CouponCodes, target.CouponCodes...)
+		}
+
+		for _, sourceItem := range source.Items {
+			idx := slices.IndexFunc(merged.Items, func(item *model.Item) bool {
+				return item.Key() == sourceItem.Key()
+			})
+			if idx < 0 {
+				itemCopy := *sourceItem
+				merged.Items = append(merged.Items, &itemCopy)
+				continue
+			}
+
+			targetItem := merged.Items[idx]
+			switch strategy {
+			case enums.MergeStrategySum:
+				targetItem.Quantity += sourceItem.Quantity
+			case enums.MergeStrategyMax:
+				targetItem.Quantity = max(targetItem.Quantity, sourceItem.Quantity)
+			case enums.MergeStrategyNewest:
+				if sourceItem.UpdatedAt.After(targetItem.UpdatedAt) {
+					*targetItem = *sourceItem
+				}
+			}
+			if sourceItem.UpdatedAt.After(targetItem.UpdatedAt) {
+				targetItem.UpdatedAt = sourceItem.UpdatedAt
+			}
+		}
+
+		for _, code := range source.CouponCodes {
+			if !slices.ContainsFunc(merged.CouponCodes, func(c string) bool { return strings.EqualFold(c, code) }) {
+				merged.CouponCodes = append(merged.CouponCodes, code)
+			}
+		}
+
+		return merged
+	}
+}
+
+// IsValidMergeStrategy reports whether strategy is one of the supported
+// merge strategies.
+func IsValidMergeStrategy(strategy enums.MergeStrategy) bool {
+	switch strategy {
+	case enums.MergeStrategySum, enums.MergeStrategyMax, enums.MergeStrategyNewest:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/cart/dto"
+	"github.com/dinosgnk/agora-project/internal/services/cart/enums"
+	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
+)
+
+func setupCartsForMerge(t *testing.T) (*CartService, *repository.MockCartRepository, string, string) {
+	t.Helper()
+
+	repo := repository.NewMockCartRepository()
+	svc := NewCartService(repo, nil, nil)
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Name: "Shared", Price: 10.0, Quantity: 3})
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p2", Name: "User only", Price: 5.0, Quantity: 1})
+
+	guestCartId, err := svc.CreateGuestCart()
+	if err != nil {
+		t.Fatalf("Expected no error while creating guest cart, got %v", err)
+	}
+
+	time.Sleep(time.Millisecond)
+	_ = svc.AddItem(guestCartId, &dto.Item{ProductCode: "p1", Name: "Shared", Price: 9.0, Quantity: 2})
+	_ = svc.AddItem(guestCartId, &dto.Item{ProductCode: "p3", Name: "Guest only", Price: 1.0, Quantity: 1})
+
+	return svc, repo, guestCartId, userId
+}
+
+func quantityOf(cart *dto.CartResponse, productCode string) int {
+	for _, item := range cart.Items {
+		if item.ProductCode == productCode {
+			return item.Quantity
+		}
+	}
+	return 0
+}
+
+func TestMergeGuestCartSumsQuantities(t *testing.T) {
+	svc, repo, guestCartId, userId := setupCartsForMerge(t)
+
+	cart, err := svc.MergeCarts(guestCartId, userId, enums.MergeStrategySum)
+	if err != nil {
+		t.Fatalf("Expected no error while merging carts, got %v", err)
+	}
+
+	if len(cart.Items) != 3 {
+		t.Fatalf("Expected 3 items after merge, got %d", len(cart.Items))
+	}
+
+	if quantity := quantityOf(cart, "p1"); quantity != 5 {
+		t.Fatalf("Expected quantity 5 for shared item, got %d", quantity)
+	}
+
+	if _, err := repo.GetCartByUserId(guestCartId); err == nil {
+		t.Fatalf("Expected guest cart to be removed after merge")
+	}
+}
+
+func TestMergeGuestCartKeepsMaxQuantity(t *testing.T) {
+	svc, _, guestCartId, userId := setupCartsForMerge(t)
+
+	cart, err := svc.MergeCarts(guestCartId, userId, enums.MergeStrategyMax)
+	if err != nil {
+		t.Fatalf("Expected no error while merging carts, got %v", err)
+	}
+
+	if quantity := quantityOf(cart, "p1"); quantity != 3 {
+		t.Fatalf("Expected quantity 3 for shared item, got %d", quantity)
+	}
+}
+
+func TestMergeGuestCartPrefersNewestItem(t *testing.T) {
+	svc, _, guestCartId, userId := setupCartsForMerge(t)
+
+	cart, err := svc.MergeCarts(guestCartId, userId, enums.MergeStrategyNewest)
+	if err != nil {
+		t.Fatalf("Expected no error while merging carts, got %v", err)
+	}
+
+	if quantity := quantityOf(cart, "p1"); quantity != 2 {
+		t.Fatalf("Expected quantity 2 from the newer guest item, got %d", quantity)
+	}
+}
+
+func TestMergeGuestCartIntoMissingUserCart(t *testing.T) {
+	svc, _, guestCartId, _ := setupCartsForMerge(t)
+
+	cart, err := svc.MergeCarts(guestCartId, "20", enums.MergeStrategySum)
+	if err != nil {
+		t.Fatalf("Expected no error while merging carts, got %v", err)
+	}
+
+	if cart.UserId != "20" || len(cart.Items) != 2 {
+		t.Fatalf("Expected guest items to move to user 20, got user %s with %d items", cart.UserId, len(cart.Items))
+	}
+}
+
+func TestMergeWithInvalidStrategy(t *testing.T) {
+	svc, _, guestCartId, userId := setupCartsForMerge(t)
+
+	if _, err := svc.MergeCarts(guestCartId, userId, "random"); err == nil {
+		t.Fatalf("Expected error for invalid merge strategy, got none")
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/services/cart/catalog"
+	"github.com/dinosgnk/agora-project/internal/services/cart/model"
+	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
+)
+
+type PriceDropPublisher interface {
+	PublishWishlistPriceDropped(event *events.WishlistPriceDroppedEvent) error
+}
+
+// PriceDropTracker refreshes the prices saved on wishlists when a product's
+// catalog price changes, and reports a drop below the saved price for items
+// that track their price.
+type PriceDropTracker struct {
+	repo          repository.ICartRepository
+	catalogClient catalog.ICatalogClient
+	publisher     PriceDropPublisher
+}
+
+func NewPriceDropTracker(repo repository.ICartRepository, catalogClient catalog.ICatalogClient, publisher PriceDropPublisher) *PriceDropTracker {
+	return &PriceDropTracker{
+		repo:          repo,
+		catalogClient: catalogClient,
+		publisher:     publisher,
+	}
+}
+
+// HandlePriceChange compares the wishlisted items of a product, including its
+// variants, with their current catalog prices. price is the product's new
+// price carried by the event, if any; it is used for items of the product
+// itself, since the catalog API may still serve a cached price. Variants, and
+// products without an event price, are looked up. Items of products that are
+// no longer in the catalog are left as they are.
+func (t *PriceDropTracker) HandlePriceChange(productCode string, price *float64) error {
+	wishlists, err := t.repo.GetWishlistsByProduct(productCode)
+	if err != nil {
+		return err
+	}
+
+	prices := make(map[string]float64)
+	for _, wishlist := range wishlists {
+		for _, item := range wishlist.Items {
+			if item.ProductCode != productCode {
+				continue
+			}
+			if _, seen := prices[item.Key()]; seen {
+				continue
+			}
+			if item.SKU == "" && price != nil {
+				prices[item.Key()] = *price
+				continue
+			}
+
+			product, err := t.lookupProduct(item)
+			if errors.Is(err, catalog.ErrProductNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			prices[item.Key()] = product.Price
+		}
+	}
+
+	for _, wishlist := range wishlists {
+		if err := t.refreshPrices(wishlist.UserId, wishlist.Name, productCode, prices); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *PriceDropTracker) refreshPrices(userId string, name string, productCode string, prices map[string]float64) error {
+	var drops []*events.WishlistPriceDroppedEvent
+	_, err := t.repo.ModifyWishlist(userId, name, func(wishlist *model.Wishlist) error {
+		// The wishlist was deleted since it was read
+		if wishlist.CreatedAt.IsZero() {
+			return repository.ErrWishlistNotFound
+		}
+
+		drops = nil
+		for _, item := range wishlist.Items {
+			price, ok := prices[item.Key()]
+			if item.ProductCode != productCode || !ok || price == item.Price {
+				continue
+			}
+
+			if item.TrackPrice && price < item.Price {
+				drops = append(drops, &events.WishlistPriceDroppedEvent{
+					UserId:       wishlist.UserId,
+					WishlistName: wishlist.Name,
+					ProductCode:  item.ProductCode,
+					SKU:          item.SKU,
+					Name:         item.Name,
+					OldPrice:     item.Price,
+					NewPrice:     price,
+				})
+			}
+			item.Price = price
+		}
+		return nil
+	})
+	if errors.Is(err, repository.ErrWishlistNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if t.publisher == nil {
+		return nil
+	}
+	for _, drop := range drops {
+		if err := t.publisher.PublishWishlistPriceDropped(drop); err != nil {
+			fmt.Printf("Failed to publish wishlist price drop event: %v\n", err)
+		}
+	}
+	return nil
+}
+
+func (t *PriceDropTracker) lookupProduct(item *model.WishlistItem) (*catalog.Product, error) {
+	if item.SKU != "" {
+		return t.catalogClient.GetVariant(item.SKU)
+	}
+	return t.catalogClient.GetProduct(item.ProductCode)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+	"github.com/dinosgnk/agora-project/internal/services/cart/model"
+)
+
+var (
+	ErrCouponsUnavailable = errors.New("coupons are not available")
+	ErrInvalidCoupon      = promotions.ErrInvalidCoupon
+)
+
+// validatePromotion checks that a promotion can currently be used on the cart
+// by the given user.
+func (cs *CartService) validatePromotion(promotion *promotions.Promotion, cart *model.Cart, now time.Time) error {
+	var used int
+	if promotion.MaxUsesPerUser > 0 {
+		var err error
+		used, err = cs.promotionRepo.CountRedemptions(promotion.Code, cart.UserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return promotions.Validate(promotion, promotionItems(cart.Items), used, now)
+}
+
+func promotionItems(items []*model.Item) []promotions.Item {
+	promotionItems := make([]promotions.Item, 0, len(items))
+	for _, item := range items {
+		promotionItems = append(promotionItems, promotions.Item{
+			ProductCode: item.ProductCode,
+			Category:    item.Category,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+		})
+	}
+	return promotionItems
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+	"github.com/dinosgnk/agora-project/internal/services/cart/dto"
+	"github.com/dinosgnk/agora-project/internal/services/cart/model"
+	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
+)
+
+func newPromotionTestService(t *testing.T, promotions ...*promotions.Promotion) (*CartService, *repository.InMemoryPromotionRepository) {
+	t.Helper()
+
+	promotionRepo := repository.NewInMemoryPromotionRepository()
+	for _, promotion := range promotions {
+		if _, err := promotionRepo.CreatePromotion(promotion); err != nil {
+			t.Fatalf("Expected no error while creating promotion, got %v", err)
+		}
+	}
+
+	return NewCartService(repository.NewMockCartRepository(), promotionRepo, nil), promotionRepo
+}
+
+func TestApplyPercentageCouponSuccessfully(t *testing.T) {
+	svc, _ := newPromotionTestService(t, &promotions.Promotion{
+		Code:   "WELCOME10",
+		Type:   promotions.TypePercentage,
+		Value:  10,
+		Active: true,
+	})
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Name: "Product", Price: 50.0, Quantity: 2})
+
+	if err := svc.ApplyCoupon(userId, "welcome10"); err != nil {
+		t.Fatalf("Expected no error while applying coupon, got %v", err)
+	}
+
+	cart, err := svc.GetCartByUserId(userId)
+	if err != nil {
+		t.Fatalf("Expected cart, got error %v", err)
+	}
+
+	if len(cart.Discounts) != 1 {
+		t.Fatalf("Expected 1 discount, got %d", len(cart.Discounts))
+	}
+
+	if cart.Subtotal != 100.0 || cart.DiscountTotal != 10.0 || cart.Total != 90.0 {
+		t.Fatalf("Expected subtotal 100, discount 10, total 90, got %.2f, %.2f, %.2f", cart.Subtotal, cart.DiscountTotal, cart.Total)
+	}
+}
+
+func TestApplyCategoryCouponOnlyDiscountsMatchingItems(t *testing.T) {
+	svc, _ := newPromotionTestService(t, &promotions.Promotion{
+		Code:     "BOOKS15",
+		Type:     promotions.TypePercentage,
+		Value:    15,
+		Category: "Books",
+		Active:   true,
+	})
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "b1", Name: "Book", Category: "books", Price: 30.0, Quantity: 1})
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "t1", Name: "Toy", Category: "Toys", Price: 70.0, Quantity: 1})
+
+	if err := svc.ApplyCoupon(userId, "BOOKS15"); err != nil {
+		t.Fatalf("Expected no error while applying coupon, got %v", err)
+	}
+
+	cart, _ := svc.GetCartByUserId(userId)
+	if cart.DiscountTotal != 4.5 {
+		t.Fatalf("Expected discount 4.50, got %.2f", cart.DiscountTotal)
+	}
+}
+
+func TestApplyBuyXGetYCouponFreesCheapestUnits(t *testing.T) {
+	svc, _ := newPromotionTestService(t, &promotions.Promotion{
+		Code:        "3FOR2",
+		Type:        promotions.TypeBuyXGetY,
+		BuyQuantity: 2,
+		GetQuantity: 1,
+		Active:      true,
+	})
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Name: "Cheap", Price: 5.0, Quantity: 1})
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p2", Name: "Expensive", Price: 20.0, Quantity: 2})
+
+	if err := svc.ApplyCoupon(userId, "3FOR2"); err != nil {
+		t.Fatalf("Expected no error while applying coupon, got %v", err)
+	}
+
+	cart, _ := svc.GetCartByUserId(userId)
+	if cart.DiscountTotal != 5.0 {
+		t.Fatalf("Expected discount 5.00, got %.2f", cart.DiscountTotal)
+	}
+}
+
+func TestApplyCouponBelowMinimumSpend(t *testing.T) {
+	svc, _ := newPromotionTestService(t, &promotions.Promotion{
+		Code:     "SAVE20",
+		Type:     promotions.TypeFixed,
+		Value:    20,
+		MinSpend: 100,
+		Active:   true,
+	})
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Name: "Product", Price: 50.0, Quantity: 1})
+
+	err := svc.ApplyCoupon(userId, "SAVE20")
+	if !errors.Is(err, ErrInvalidCoupon) {
+		t.Fatalf("Expected invalid coupon error, got %v", err)
+	}
+}
+
+func TestApplyExpiredCoupon(t *testing.T) {
+	expiredAt := time.Now().Add(-time.Hour)
+	svc, _ := newPromotionTestService(t, &promotions.Promotion{
+		Code:      "OLD",
+		Type:      promotions.TypeFixed,
+		Value:     5,
+		ExpiresAt: &expiredAt,
+		Active:    true,
+	})
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Name: "Product", Price: 50.0, Quantity: 1})
+
+	err := svc.ApplyCoupon(userId, "OLD")
+	if !errors.Is(err, ErrInvalidCoupon) {
+		t.Fatalf("Expected invalid coupon error, got %v", err)
+	}
+}
+
+func TestApplyCouponAfterUsageLimitReached(t *testing.T) {
+	svc, promotionRepo := newPromotionTestService(t, &promotions.Promotion{
+		Code:           "ONCE",
+		Type:           promotions.TypeFixed,
+		Value:          5,
+		MaxUsesPerUser: 1,
+		Active:         true,
+	})
+
+	userId := "10"
+	promotionRepo.RecordRedemption(&model.CouponRedemption{CouponCode: "ONCE", UserID: userId, OrderID: "o1", Amount: 5})
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Name: "Product", Price: 50.0, Quantity: 1})
+
+	err := svc.ApplyCoupon(userId, "ONCE")
+	if !errors.Is(err, ErrInvalidCoupon) {
+		t.Fatalf("Expected invalid coupon error, got %v", err)
+	}
+}
+
+func TestCouponStopsApplyingWhenCartDropsBelowMinimumSpend(t *testing.T) {
+	svc, _ := newPromotionTestService(t, &promotions.Promotion{
+		Code:     "SAVE20",
+		Type:     promotions.TypeFixed,
+		Value:    20,
+		MinSpend: 100,
+		Active:   true,
+	})
+
+	userId := "10"
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Name: "Product", Price: 60.0, Quantity: 1})
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p2", Name: "Product", Price: 60.0, Quantity: 1})
+	if err := svc.ApplyCoupon(userId, "SAVE20"); err != nil {
+		t.Fatalf("Expected no error while applying coupon, got %v", err)
+	}
+
+	_ = svc.RemoveItem(userId, "p2")
+
+	cart, _ := svc.GetCartByUserId(userId)
+	if len(cart.CouponCodes) != 1 {
+		t.Fatalf("Expected coupon to remain on the cart, got %d coupons", len(cart.CouponCodes))
+	}
+
+	if cart.DiscountTotal != 0 {
+		t.Fatalf("Expected no discount, got %.2f", cart.DiscountTotal)
+	}
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/cart/catalog"
+	"github.com/dinosgnk/agora-project/internal/services/cart/dto"
+	"github.com/dinosgnk/agora-project/internal/services/cart/model"
+	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
+)
+
+type IWishlistService interface {
+	GetWishlists(userId string) ([]*dto.WishlistResponse, error)
+	GetWishlist(userId string, name string) (*dto.WishlistResponse, error)
+	CreateWishlist(userId string, name string) (*dto.WishlistResponse, error)
+	DeleteWishlist(userId string, name string) error
+	AddWishlistItem(userId string, name string, itemReq *dto.WishlistItemRequest) (*dto.WishlistResponse, error)
+	RemoveWishlistItem(userId string, name string, itemKey string) (*dto.WishlistResponse, error)
+	MoveToCart(userId string, name string, itemKey string, quantity int) (*dto.MoveResponse, error)
+	SaveForLater(userId string, name string, itemReq *dto.WishlistItemRequest) (*dto.MoveResponse, error)
+}
+
+const MaxWishlistNameLength = 50
+
+var (
+	ErrInvalidWishlistName  = errors.New("invalid wishlist name")
+	ErrWishlistExists       = errors.New("wishlist already exists")
+	ErrWishlistItemNotFound = errors.New("item not found in wishlist")
+	ErrCartItemNotFound     = errors.New("item not found in cart")
+)
+
+func (cs *CartService) GetWishlists(userId string) ([]*dto.WishlistResponse, error) {
+	wishlists, err := cs.repo.GetWishlists(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.WishlistResponse, len(wishlists))
+	for i, wishlist := range wishlists {
+		responses[i] = mapWishlistModelToDto(wishlist)
+	}
+	return responses, nil
+}
+
+func (cs *CartService) GetWishlist(userId string, name string) (*dto.WishlistResponse, error) {
+	wishlist, err := cs.repo.GetWishlist(userId, name)
+	if err != nil {
+		return nil, err
+	}
+	return mapWishlistModelToDto(wishlist), nil
+}
+
+func (cs *CartService) CreateWishlist(userId string, name string) (*dto.WishlistResponse, error) {
+	name, err := wishlistName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	wishlist, err := cs.repo.ModifyWishlist(userId, name, func(wishlist *model.Wishlist) error {
+		if !wishlist.CreatedAt.IsZero() {
+			return fmt.Errorf("%w: %s", ErrWishlistExists, name)
+		}
+		touchWishlist(wishlist)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapWishlistModelToDto(wishlist), nil
+}
+
+func (cs *CartService) DeleteWishlist(userId string, name string) error {
+	deleted, err := cs.repo.DeleteWishlist(userId, name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return repository.ErrWishlistNotFound
+	}
+	return nil
+}
+
+// AddWishlistItem saves a product to a wishlist, creating the wishlist if it
+// does not exist. Adding an item that is already saved updates its details
+// and price tracking but keeps the time it was first added.
+func (cs *CartService) AddWishlistItem(userId string, name string, itemReq *dto.WishlistItemRequest) (*dto.WishlistResponse, error) {
+	name, err := wishlistName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	newItem := &model.WishlistItem{
+		ProductCode: itemReq.ProductCode,
+		SKU:         itemReq.SKU,
+		TrackPrice:  itemReq.TrackPrice,
+	}
+	if cs.catalogClient != nil {
+		product, err := cs.lookupProduct(&model.Item{ProductCode: itemReq.ProductCode, SKU: itemReq.SKU})
+		if errors.Is(err, catalog.ErrProductNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProduct, newItem.Key())
+		}
+		if err != nil {
+			return nil, err
+		}
+		setWishlistItemDetails(newItem, product)
+	}
+	if newItem.Key() == "" {
+		return nil, fmt.Errorf("%w: product_code or sku is required", ErrUnknownProduct)
+	}
+
+	wishlist, err := cs.repo.ModifyWishlist(userId, name, func(wishlist *model.Wishlist) error {
+		saveWishlistItem(wishlist, newItem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapWishlistModelToDto(wishlist), nil
+}
+
+func (cs *CartService) RemoveWishlistItem(userId string, name string, itemKey string) (*dto.WishlistResponse, error) {
+	wishlist, err := cs.repo.ModifyWishlist(userId, name, func(wishlist *model.Wishlist) error {
+		if wishlist.CreatedAt.IsZero() {
+			return repository.ErrWishlistNotFound
+		}
+		if _, ok := takeWishlistItem(wishlist, itemKey); !ok {
+			return fmt.Errorf("%w: %s", ErrWishlistItemNotFound, itemKey)
+		}
+		touchWishlist(wishlist)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapWishlistModelToDto(wishlist), nil
+}
+
+// MoveToCart moves a wishlist item into the cart, adding to the quantity of a
+// matching cart line. The item is taken off the wishlist and added to the cart
+// in one step, so a move that fails leaves both unchanged.
+func (cs *CartService) MoveToCart(userId string, name string, itemKey string, quantity int) (*dto.MoveResponse, error) {
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	saved, err := cs.repo.GetWishlist(userId, name)
+	if err != nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(saved.Items, func(item *model.WishlistItem) bool {
+		return item.Key() == itemKey
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrWishlistItemNotFound, itemKey)
+	}
+
+	var product *catalog.Product
+	if cs.catalogClient != nil {
+		product, err = cs.lookupProduct(&model.Item{ProductCode: saved.Items[idx].ProductCode, SKU: saved.Items[idx].SKU})
+		if errors.Is(err, catalog.ErrProductNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProduct, itemKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cart, wishlist, err := cs.repo.MoveItems(userId, name, func(cart *model.Cart, wishlist *model.Wishlist) error {
+		if wishlist.CreatedAt.IsZero() {
+			return repository.ErrWishlistNotFound
+		}
+		wishlistItem, ok := takeWishlistItem(wishlist, itemKey)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrWishlistItemNotFound, itemKey)
+		}
+
+		newItem := &model.Item{
+			ProductCode: wishlistItem.ProductCode,
+			SKU:         wishlistItem.SKU,
+			Name:        wishlistItem.Name,
+			Category:    wishlistItem.Category,
+			Attributes:  wishlistItem.Attributes,
+			Price:       wishlistItem.Price,
+			Quantity:    quantity,
+			UpdatedAt:   time.Now(),
+		}
+		idx := slices.IndexFunc(cart.Items, func(item *model.Item) bool {
+			return item.Key() == itemKey
+		})
+		if idx >= 0 {
+			newItem.Quantity += cart.Items[idx].Quantity
+		}
+
+		if product != nil {
+			if err := checkQuantity(product, itemKey, newItem.Quantity); err != nil {
+				return err
+			}
+			newItem.ProductCode = product.ProductCode
+			newItem.Name = product.Name
+			newItem.Category = product.Category
+			newItem.Attributes = product.Attributes
+			newItem.Price = product.Price
+		}
+
+		if idx >= 0 {
+			cart.Items[idx] = newItem
+		} else {
+			cart.Items = append(cart.Items, newItem)
+		}
+		touchWishlist(wishlist)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cs.mapMoveToDto(cart, wishlist)
+}
+
+// SaveForLater moves a cart line to a wishlist, creating the wishlist if it
+// does not exist. The current catalog price becomes the baseline for price
+// tracking, falling back to the price in the cart.
+func (cs *CartService) SaveForLater(userId string, name string, itemReq *dto.WishlistItemRequest) (*dto.MoveResponse, error) {
+	name, err := wishlistName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	itemKey := itemReq.ProductCode
+	if itemReq.SKU != "" {
+		itemKey = itemReq.SKU
+	}
+
+	var product *catalog.Product
+	if cs.catalogClient != nil {
+		product, err = cs.lookupProduct(&model.Item{ProductCode: itemReq.ProductCode, SKU: itemReq.SKU})
+		if err != nil && !errors.Is(err, catalog.ErrProductNotFound) {
+			return nil, err
+		}
+	}
+
+	cart, wishlist, err := cs.repo.MoveItems(userId, name, func(cart *model.Cart, wishlist *model.Wishlist) error {
+		idx := slices.IndexFunc(cart.Items, func(item *model.Item) bool {
+			return item.Key() == itemKey
+		})
+		if idx < 0 {
+			return fmt.Errorf("%w: %s", ErrCartItemNotFound, itemKey)
+		}
+		cartItem := cart.Items[idx]
+		cart.Items = slices.Delete(cart.Items, idx, idx+1)
+
+		newItem := &model.WishlistItem{
+			ProductCode: cartItem.ProductCode,
+			SKU:         cartItem.SKU,
+			Name:        cartItem.Name,
+			Category:    cartItem.Category,
+			Attributes:  cartItem.Attributes,
+			Price:       cartItem.Price,
+			TrackPrice:  itemReq.TrackPrice,
+		}
+		if product != nil {
+			setWishlistItemDetails(newItem, product)
+		}
+		saveWishlistItem(wishlist, newItem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cs.mapMoveToDto(cart, wishlist)
+}
+
+func (cs *CartService) mapMoveToDto(cart *model.Cart, wishlist *model.Wishlist) (*dto.MoveResponse, error) {
+	discounts, err := cs.applicableDiscounts(cart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MoveResponse{
+		Cart:     cs.mapCartModelToDto(cart, discounts),
+		Wishlist: mapWishlistModelToDto(wishlist),
+	}, nil
+}
+
+// wishlistName trims a wishlist name and checks that it can be used as a
+// path segment.
+func wishlistName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", fmt.Errorf("%w: name is required", ErrInvalidWishlistName)
+	case len(name) > MaxWishlistNameLength:
+		return "", fmt.Errorf("%w: name must be at most %d characters", ErrInvalidWishlistName, MaxWishlistNameLength)
+	case strings.Contains(name, "/"):
+		return "", fmt.Errorf("%w: name must not contain '/'", ErrInvalidWishlistName)
+	}
+	return name, nil
+}
+
+// saveWishlistItem adds an item to a wishlist or replaces the saved item with
+// the same key, keeping its AddedAt.
+func saveWishlistItem(wishlist *model.Wishlist, newItem *model.WishlistItem) {
+	newItem.AddedAt = time.Now()
+	idx := slices.IndexFunc(wishlist.Items, func(item *model.WishlistItem) bool {
+		return item.Key() == newItem.Key()
+	})
+	if idx >= 0 {
+		newItem.AddedAt = wishlist.Items[idx].AddedAt
+		wishlist.Items[idx] = newItem
+	} else {
+		wishlist.Items = append(wishlist.Items, newItem)
+	}
+	touchWishlist(wishlist)
+}
+
+func takeWishlistItem(wishlist *model.Wishlist, itemKey string) (*model.WishlistItem, bool) {
+	idx := slices.IndexFunc(wishlist.Items, func(item *model.WishlistItem) bool {
+		return item.Key() == itemKey
+	})
+	if idx < 0 {
+		return nil, false
+	}
+
+	item := wishlist.Items[idx]
+	wishlist.Items = slices.Delete(wishlist.Items, idx, idx+1)
+	return item, true
+}
+
+// touchWishlist sets the timestamps of a changed wishlist, including
+// CreatedAt when it is new.
+func touchWishlist(wishlist *model.Wishlist) {
+	wishlist.UpdatedAt = time.Now()
+	if wishlist.CreatedAt.IsZero() {
+		wishlist.CreatedAt = wishlist.UpdatedAt
+	}
+}
+
+func setWishlistItemDetails(item *model.WishlistItem, product *catalog.Product) {
+	item.ProductCode = product.ProductCode
+	item.Name = product.Name
+	item.Category = product.Category
+	item.Attributes = product.Attributes
+	item.Price = product.Price
+}
+
+func mapWishlistModelToDto(wishlist *model.Wishlist) *dto.WishlistResponse {
+	items := make([]dto.WishlistItem, len(wishlist.Items))
+	for i, item := range wishlist.Items {
+		items[i] = dto.WishlistItem{
+			ProductCode: item.ProductCode,
+			SKU:         item.SKU,
+			Name:        item.Name,
+			Category:    item.Category,
+			Attributes:  item.Attributes,
+			Price:       item.Price,
+			TrackPrice:  item.TrackPrice,
+			AddedAt:     item.AddedAt,
+		}
+	}
+
+	return &dto.WishlistResponse{
+		UserId:    wishlist.UserId,
+		Name:      wishlist.Name,
+		Items:     items,
+		CreatedAt: wishlist.CreatedAt,
+		UpdatedAt: wishlist.UpdatedAt,
+	}
+}
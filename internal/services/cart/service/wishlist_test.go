@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/services/cart/catalog"
+	"github.com/dinosgnk/agora-project/internal/services/cart/dto"
+	"github.com/dinosgnk/agora-project/internal/services/cart/repository"
+)
+
+type recordingPublisher struct {
+	events []*events.WishlistPriceDroppedEvent
+}
+
+func (p *recordingPublisher) PublishWishlistPriceDropped(event *events.WishlistPriceDroppedEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestWishlistLifecycle(t *testing.T) {
+	svc, _ := newCatalogTestService()
+	userId := "10"
+
+	if _, err := svc.CreateWishlist(userId, " Birthday "); err != nil {
+		t.Fatalf("Expected no error while creating wishlist, got %v", err)
+	}
+	if _, err := svc.CreateWishlist(userId, "Birthday"); !errors.Is(err, ErrWishlistExists) {
+		t.Fatalf("Expected wishlist exists error, got %v", err)
+	}
+	if _, err := svc.CreateWishlist(userId, "a/b"); !errors.Is(err, ErrInvalidWishlistName) {
+		t.Fatalf("Expected invalid wishlist name error, got %v", err)
+	}
+
+	wishlist, err := svc.AddWishlistItem(userId, "Later", &dto.WishlistItemRequest{ProductCode: "p1"})
+	if err != nil {
+		t.Fatalf("Expected no error while adding wishlist item, got %v", err)
+	}
+	if len(wishlist.Items) != 1 || wishlist.Items[0].Name != "Catalog Name" || wishlist.Items[0].Price != 12.5 {
+		t.Fatalf("Expected the catalog details of p1, got %+v", wishlist.Items)
+	}
+
+	if _, err := svc.AddWishlistItem(userId, "Later", &dto.WishlistItemRequest{ProductCode: "missing"}); !errors.Is(err, ErrUnknownProduct) {
+		t.Fatalf("Expected unknown product error, got %v", err)
+	}
+
+	wishlists, _ := svc.GetWishlists(userId)
+	if len(wishlists) != 2 || wishlists[0].Name != "Birthday" || wishlists[1].Name != "Later" {
+		t.Fatalf("Expected wishlists Birthday and Later, got %+v", wishlists)
+	}
+
+	wishlist, err = svc.RemoveWishlistItem(userId, "Later", "p1")
+	if err != nil || len(wishlist.Items) != 0 {
+		t.Fatalf("Expected an empty wishlist after removing the item, got %+v and %v", wishlist, err)
+	}
+	if _, err := svc.RemoveWishlistItem(userId, "Missing", "p1"); !errors.Is(err, repository.ErrWishlistNotFound) {
+		t.Fatalf("Expected wishlist not found error, got %v", err)
+	}
+
+	if err := svc.DeleteWishlist(userId, "Birthday"); err != nil {
+		t.Fatalf("Expected no error while deleting wishlist, got %v", err)
+	}
+	if _, err := svc.GetWishlist(userId, "Birthday"); !errors.Is(err, repository.ErrWishlistNotFound) {
+		t.Fatalf("Expected wishlist not found error, got %v", err)
+	}
+}
+
+func TestMoveToCartIsAtomic(t *testing.T) {
+	svc, catalogClient := newCatalogTestService()
+	userId := "10"
+
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Quantity: 4})
+	_, _ = svc.AddWishlistItem(userId, "Later", &dto.WishlistItemRequest{ProductCode: "p1"})
+
+	// 4 in the cart plus 2 exceeds the limit of 5 per order
+	if _, err := svc.MoveToCart(userId, "Later", "p1", 2); !errors.Is(err, ErrQuantityExceeded) {
+		t.Fatalf("Expected quantity exceeded error, got %v", err)
+	}
+
+	cart, _ := svc.GetCartByUserId(userId)
+	wishlist, _ := svc.GetWishlist(userId, "Later")
+	if quantityOf(cart, "p1") != 4 || len(wishlist.Items) != 1 {
+		t.Fatalf("Expected a failed move to leave cart and wishlist unchanged, got quantity %d and %d wishlist items", quantityOf(cart, "p1"), len(wishlist.Items))
+	}
+
+	catalogClient.SetProduct(&catalog.Product{ProductCode: "p1", Name: "Catalog Name", Price: 11.0, Stock: 10, MaxOrderQty: 6})
+	moved, err := svc.MoveToCart(userId, "Later", "p1", 2)
+	if err != nil {
+		t.Fatalf("Expected no error while moving item to cart, got %v", err)
+	}
+	if quantityOf(moved.Cart, "p1") != 6 || len(moved.Wishlist.Items) != 0 {
+		t.Fatalf("Expected quantity 6 in the cart and an empty wishlist, got %+v", moved)
+	}
+	if moved.Cart.Items[0].Price != 11.0 {
+		t.Fatalf("Expected the current catalog price 11.00, got %.2f", moved.Cart.Items[0].Price)
+	}
+
+	if _, err := svc.MoveToCart(userId, "Later", "p1", 1); !errors.Is(err, ErrWishlistItemNotFound) {
+		t.Fatalf("Expected wishlist item not found error, got %v", err)
+	}
+}
+
+func TestSaveForLater(t *testing.T) {
+	svc, _ := newCatalogTestService()
+	userId := "10"
+
+	_ = svc.AddItem(userId, &dto.Item{ProductCode: "p1", Quantity: 2})
+
+	moved, err := svc.SaveForLater(userId, "Later", &dto.WishlistItemRequest{ProductCode: "p1", TrackPrice: true})
+	if err != nil {
+		t.Fatalf("Expected no error while saving item for later, got %v", err)
+	}
+	if len(moved.Cart.Items) != 0 {
+		t.Fatalf("Expected the item to leave the cart, got %+v", moved.Cart.Items)
+	}
+	if len(moved.Wishlist.Items) != 1 || !moved.Wishlist.Items[0].TrackPrice || moved.Wishlist.Items[0].Price != 12.5 {
+		t.Fatalf("Expected a tracked wishlist item at 12.50, got %+v", moved.Wishlist.Items)
+	}
+
+	if _, err := svc.SaveForLater(userId, "Later", &dto.WishlistItemRequest{ProductCode: "p1"}); !errors.Is(err, ErrCartItemNotFound) {
+		t.Fatalf("Expected cart item not found error, got %v", err)
+	}
+}
+
+func TestPriceDropEvents(t *testing.T) {
+	repo := repository.NewMockCartRepository()
+	catalogClient := catalog.NewMockCatalogClient(
+		&catalog.Product{ProductCode: "p1", Name: "Shirt", Price: 20.0, Stock: 10},
+		&catalog.Product{ProductCode: "p1", SKU: "p1-red", Name: "Shirt", Price: 25.0, Stock: 10},
+	)
+	svc := NewCartService(repo, nil, catalogClient)
+	publisher := &recordingPublisher{}
+	tracker := NewPriceDropTracker(repo, catalogClient, publisher)
+
+	_, _ = svc.AddWishlistItem("10", "Later", &dto.WishlistItemRequest{ProductCode: "p1", TrackPrice: true})
+	_, _ = svc.AddWishlistItem("10", "Later", &dto.WishlistItemRequest{ProductCode: "p1", SKU: "p1-red", TrackPrice: true})
+	_, _ = svc.AddWishlistItem("20", "Gifts", &dto.WishlistItemRequest{ProductCode: "p1"})
+
+	catalogClient.SetProduct(&catalog.Product{ProductCode: "p1", Name: "Shirt", Price: 15.0, Stock: 10})
+	catalogClient.SetProduct(&catalog.Product{ProductCode: "p1", SKU: "p1-red", Name: "Shirt", Price: 30.0, Stock: 10})
+
+	if err := tracker.HandlePriceChange("p1", nil); err != nil {
+		t.Fatalf("Expected no error while handling price change, got %v", err)
+	}
+
+	if len(publisher.events) != 1 {
+		t.Fatalf("Expected 1 price drop event, got %d", len(publisher.events))
+	}
+	event := publisher.events[0]
+	if event.UserId != "10" || event.SKU != "" || event.OldPrice != 20.0 || event.NewPrice != 15.0 {
+		t.Fatalf("Expected a drop from 20.00 to 15.00 for user 10, got %+v", event)
+	}
+
+	untracked, _ := svc.GetWishlist("20", "Gifts")
+	if untracked.Items[0].Price != 15.0 {
+		t.Fatalf("Expected untracked items to follow the catalog price, got %.2f", untracked.Items[0].Price)
+	}
+
+	// Prices already seen are not reported again
+	if err := tracker.HandlePriceChange("p1", nil); err != nil || len(publisher.events) != 1 {
+		t.Fatalf("Expected no new events, got %d and %v", len(publisher.events), err)
+	}
+}
+
+func TestPriceDropUsesEventPrice(t *testing.T) {
+	repo := repository.NewMockCartRepository()
+	catalogClient := catalog.NewMockCatalogClient(
+		&catalog.Product{ProductCode: "p1", Name: "Shirt", Price: 20.0, Stock: 10},
+	)
+	svc := NewCartService(repo, nil, catalogClient)
+	publisher := &recordingPublisher{}
+	tracker := NewPriceDropTracker(repo, catalogClient, publisher)
+
+	_, _ = svc.AddWishlistItem("10", "Later", &dto.WishlistItemRequest{ProductCode: "p1", TrackPrice: true})
+
+	// The catalog API still serves the old price
+	newPrice := 15.0
+	if err := tracker.HandlePriceChange("p1", &newPrice); err != nil {
+		t.Fatalf("Expected no error while handling price change, got %v", err)
+	}
+
+	if len(publisher.events) != 1 || publisher.events[0].NewPrice != 15.0 {
+		t.Fatalf("Expected a drop to the event price 15.00, got %+v", publisher.events)
+	}
+	wishlist, _ := svc.GetWishlist("10", "Later")
+	if wishlist.Items[0].Price != 15.0 {
+		t.Errorf("Expected the saved price to follow the event, got %.2f", wishlist.Items[0].Price)
+	}
+}
@@ -0,0 +1,48 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("invalid cart token")
+
+// Signer issues and verifies guest cart tokens of the form
+// "<cartId>.<base64url(HMAC-SHA256(cartId))>".
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret string) *Signer {
+	return &Signer{
+		secret: []byte(secret),
+	}
+}
+
+func (s *Signer) Sign(cartId string) string {
+	return cartId + "." + base64.RawURLEncoding.EncodeToString(s.mac(cartId))
+}
+
+func (s *Signer) Verify(token string) (string, error) {
+	idx := strings.LastIndex(token, ".")
+	if idx <= 0 {
+		return "", ErrInvalidToken
+	}
+
+	cartId := token[:idx]
+	signature, err := base64.RawURLEncoding.DecodeString(token[idx+1:])
+	if err != nil || !hmac.Equal(signature, s.mac(cartId)) {
+		return "", ErrInvalidToken
+	}
+
+	return cartId, nil
+}
+
+func (s *Signer) mac(cartId string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(cartId))
+	return h.Sum(nil)
+}
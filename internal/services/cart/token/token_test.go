@@ -0,0 +1,29 @@
+package token
+
+import "testing"
+
+func TestVerifySignedToken(t *testing.T) {
+	signer := NewSigner("secret")
+
+	cartId, err := signer.Verify(signer.Sign("guest-123"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cartId != "guest-123" {
+		t.Fatalf("Expected cart id guest-123, got %s", cartId)
+	}
+}
+
+func TestVerifyRejectsTamperedToken(t *testing.T) {
+	signer := NewSigner("secret")
+	token := signer.Sign("guest-123")
+
+	if _, err := signer.Verify("guest-456" + token[len("guest-123"):]); err != ErrInvalidToken {
+		t.Fatalf("Expected invalid token error, got %v", err)
+	}
+
+	if _, err := NewSigner("other").Verify(token); err != ErrInvalidToken {
+		t.Fatalf("Expected invalid token error for a different secret, got %v", err)
+	}
+}
@@ -2,25 +2,86 @@ package main
 
 import (
 	"os"
+	"time"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/cache"
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
 	confighelper "github.com/dinosgnk/agora-project/internal/pkg/config"
+	"github.com/dinosgnk/agora-project/internal/pkg/httpx"
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
 	"github.com/dinosgnk/agora-project/internal/pkg/server"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/config"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/handler"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/orders"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/service"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/storage"
 )
 
 func main() {
 	log := logger.NewLogger()
 	cfg := confighelper.LoadConfig[config.AppConfig](log)
 
-	productRepository := repository.NewPostgresProductRepository(log)
-	productService := service.NewProductService(productRepository)
-	productHandler := handler.NewProductHandler(productService, log)
+	rabbitClient, err := rabbitmq.NewRabbitMQClient(log)
+	if err != nil {
+		log.Error("Failed to connect to RabbitMQ", "error", err)
+		os.Exit(1)
+	}
+	defer rabbitClient.Close()
+
+	publisher, err := messaging.NewPublisher(rabbitClient)
+	if err != nil {
+		log.Error("Failed to initialize event publisher", "error", err)
+		os.Exit(1)
+	}
+
+	productCache := cache.NewLRU[*model.Product]("catalog_products", cfg.ProductCacheSize, cfg.ProductCacheTTL)
+	productRepository := repository.NewCachedProductRepository(repository.NewPostgresProductRepository(log), productCache)
+
+	cacheInvalidator, err := messaging.NewCacheInvalidationConsumer(rabbitClient, productRepository, log)
+	if err != nil {
+		log.Error("Failed to initialize cache invalidation consumer", "error", err)
+		os.Exit(1)
+	}
+
+	if err := cacheInvalidator.Start(); err != nil {
+		log.Error("Failed to start cache invalidation consumer", "error", err)
+		os.Exit(1)
+	}
+
+	categoryRepository := repository.NewPostgresCategoryRepository(log)
+	productService := service.NewProductService(productRepository, categoryRepository, publisher)
+	productHandler := handler.NewProductHandler(productService, cfg.AdminToken, log)
+
+	categoryService := service.NewCategoryService(categoryRepository, productRepository, publisher)
+	categoryHandler := handler.NewCategoryHandler(categoryService, log)
+
+	mediaStorage, err := storage.NewLocalFileStorage(cfg.MediaStoragePath)
+	if err != nil {
+		log.Error("Failed to initialize media storage", "error", err)
+		os.Exit(1)
+	}
+
+	mediaService := service.NewMediaService(productRepository, mediaStorage, publisher, cfg.MediaMaxUpload)
+	mediaHandler := handler.NewMediaHandler(mediaService, log)
+
+	purger := service.NewProductPurger(productRepository, mediaStorage, time.Duration(cfg.PurgeAfterDays)*24*time.Hour, cfg.PurgeInterval, log)
+	purger.Start()
+	defer purger.Stop()
+
+	priceScheduler := service.NewPriceScheduler(productRepository, publisher, clock.Real(), cfg.PricePollInterval, log)
+	priceScheduler.Start()
+	defer priceScheduler.Stop()
+
+	reviewRepository := repository.NewPostgresReviewRepository(log)
+	reviewService := service.NewReviewService(reviewRepository, productRepository, orders.NewHttpOrderClient(cfg.OrderServiceUrl), publisher)
+	reviewHandler := handler.NewReviewHandler(reviewService, log)
+	productHandler.HandleResource("reviews", reviewHandler.GetProductReviews)
 
-	server := server.NewServer(cfg.Port, productHandler, log, cfg.Service)
+	server := server.NewServer(cfg.Port, httpx.Handlers{productHandler, categoryHandler, mediaHandler, reviewHandler}, log, cfg.Service)
 	if err := server.Run(); err != nil {
 		os.Exit(1)
 	}
@@ -1,7 +1,18 @@
 package config
 
+import "time"
+
 type AppConfig struct {
-	Environment string `env:"ENVIRONMENT"`
-	Port        string `env:"PORT"`
-	Service     string `env:"SERVICE_NAME"`
+	Environment       string        `env:"ENVIRONMENT"`
+	Port              string        `env:"PORT"`
+	Service           string        `env:"SERVICE_NAME"`
+	ProductCacheSize  int           `env:"PRODUCT_CACHE_SIZE" envDefault:"10000"`
+	ProductCacheTTL   time.Duration `env:"PRODUCT_CACHE_TTL" envDefault:"5m"`
+	MediaStoragePath  string        `env:"MEDIA_STORAGE_PATH" envDefault:"/var/lib/agora/media"`
+	MediaMaxUpload    int64         `env:"MEDIA_MAX_UPLOAD_BYTES" envDefault:"10485760"`
+	PurgeAfterDays    int           `env:"PRODUCT_PURGE_AFTER_DAYS" envDefault:"30"`
+	PurgeInterval     time.Duration `env:"PRODUCT_PURGE_INTERVAL" envDefault:"1h"`
+	PricePollInterval time.Duration `env:"PRICE_SCHEDULER_POLL_INTERVAL" envDefault:"1m"`
+	OrderServiceUrl   string        `env:"ORDER_SERVICE_URL" envDefault:"http://localhost:8083"`
+	AdminToken        string        `env:"CATALOG_ADMIN_TOKEN"`
 }
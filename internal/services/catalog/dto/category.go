@@ -0,0 +1,16 @@
+package dto
+
+type CategoryRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Slug is derived from the name when empty
+	Slug     string `json:"slug"`
+	ParentId *int   `json:"parent_id"`
+}
+
+type CategoryResponse struct {
+	CategoryId int                 `json:"category_id"`
+	ParentId   *int                `json:"parent_id"`
+	Name       string              `json:"name"`
+	Slug       string              `json:"slug"`
+	Children   []*CategoryResponse `json:"children,omitempty"`
+}
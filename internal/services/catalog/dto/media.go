@@ -0,0 +1,17 @@
+package dto
+
+// MediaResponse describes a gallery image. URLs are relative to the catalog
+// service; Thumbnails maps each thumbnail size name to its URL.
+type MediaResponse struct {
+	MediaId     int               `json:"media_id"`
+	Position    int               `json:"position"`
+	ContentType string            `json:"content_type"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+}
+
+type ReorderMediaRequest struct {
+	MediaIds []int `json:"media_ids" binding:"required"`
+}
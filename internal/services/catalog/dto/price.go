@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// PriceScheduleRequest schedules a price change. When EndsAt is set, a second
+// change back to the price the first one replaces is scheduled, e.g. for a
+// sale. Cancelling the first change cancels the second.
+type PriceScheduleRequest struct {
+	Price       float64    `json:"price" binding:"required,gt=0"`
+	EffectiveAt time.Time  `json:"effective_at" binding:"required"`
+	EndsAt      *time.Time `json:"ends_at"`
+}
+
+type PriceScheduleResponse struct {
+	ScheduleId  int        `json:"schedule_id"`
+	ParentId    *int       `json:"parent_id,omitempty"`
+	Price       float64    `json:"price"`
+	EffectiveAt time.Time  `json:"effective_at"`
+	Status      string     `json:"status"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
+
+type PriceChangeResponse struct {
+	OldPrice   float64   `json:"old_price"`
+	NewPrice   float64   `json:"new_price"`
+	Source     string    `json:"source"`
+	ScheduleId *int      `json:"schedule_id,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
@@ -1,25 +1,88 @@
 package dto
 
+import "time"
+
+type VariantRequest struct {
+	SKU        string         `json:"sku" binding:"required"`
+	Price      *float64       `json:"price" binding:"omitempty,gte=0"`
+	Stock      int            `json:"stock" binding:"gte=0"`
+	Attributes map[string]any `json:"attributes"`
+}
+
 type CreateProductRequest struct {
-	ProductCode string  `json:"product_code" binding:"required"`
-	Name        string  `json:"name" binding:"required"`
-	Category    string  `json:"category" binding:"required"`
-	Description string  `json:"description" binding:"required"`
-	Price       float64 `json:"price" binding:"required,gte=0"`
+	ProductCode string            `json:"product_code" binding:"required"`
+	Name        string            `json:"name" binding:"required"`
+	Category    string            `json:"category"`
+	CategoryId  *int              `json:"category_id"`
+	Description string            `json:"description" binding:"required"`
+	Price       float64           `json:"price" binding:"required,gte=0"`
+	Stock       int               `json:"stock" binding:"gte=0"`
+	MaxOrderQty int               `json:"max_order_quantity" binding:"gte=0"`
+	Attributes  map[string]any    `json:"attributes"`
+	Variants    []*VariantRequest `json:"variants"`
 }
 
+// UpdateProductRequest holds every editable field of a product. PUT replaces
+// the product with it, so omitted fields are reset; it is also the document
+// that PATCH merge patches are applied to. ProductCode may be omitted and
+// otherwise must match the path.
 type UpdateProductRequest struct {
-	ProductCode string  `json:"product_code" binding:"required"`
-	Name        string  `json:"name" binding:"omitempty"`
-	Category    string  `json:"category" binding:"omitempty"`
-	Description string  `json:"description" binding:"omitempty"`
-	Price       float64 `json:"price" binding:"omitempty,gte=0"`
+	ProductCode string         `json:"product_code,omitempty"`
+	Name        string         `json:"name" binding:"required"`
+	Category    string         `json:"category"`
+	CategoryId  *int           `json:"category_id"`
+	Description string         `json:"description"`
+	Price       float64        `json:"price" binding:"gte=0"`
+	Stock       int            `json:"stock" binding:"gte=0"`
+	MaxOrderQty int            `json:"max_order_quantity" binding:"gte=0"`
+	Attributes  map[string]any `json:"attributes"`
 }
 
 type ProductResponse struct {
-	ProductCode string  `json:"product_code"`
-	Name        string  `json:"name"`
-	Category    string  `json:"category"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ProductCode string             `json:"product_code"`
+	Name        string             `json:"name"`
+	Category    string             `json:"category"`
+	CategoryId  *int               `json:"category_id"`
+	Description string             `json:"description"`
+	Price       float64            `json:"price"`
+	Stock       int                `json:"stock"`
+	MaxOrderQty int                `json:"max_order_quantity"`
+	Attributes  map[string]any     `json:"attributes"`
+	Variants    []*VariantResponse `json:"variants"`
+	Media       []*MediaResponse   `json:"media"`
+	Rating      RatingResponse     `json:"rating"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// VariantResponse carries the effective price: the override when set,
+// otherwise the parent product's price.
+type VariantResponse struct {
+	SKU        string         `json:"sku"`
+	Price      float64        `json:"price"`
+	Stock      int            `json:"stock"`
+	Attributes map[string]any `json:"attributes"`
+}
+
+// VariantDetailResponse describes a variant looked up by SKU together with
+// the parent product fields needed to sell it.
+type VariantDetailResponse struct {
+	ProductCode string `json:"product_code"`
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	MaxOrderQty int    `json:"max_order_quantity"`
+	VariantResponse
+}
+
+type ImportRowError struct {
+	Line        int    `json:"line"`
+	ProductCode string `json:"product_code,omitempty"`
+	Error       string `json:"error"`
+}
+
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Total    int               `json:"total"`
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	Errors   []*ImportRowError `json:"errors"`
 }
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+type CreateReviewRequest struct {
+	UserId string `json:"user_id" binding:"required"`
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Title  string `json:"title"`
+	Body   string `json:"body" binding:"required"`
+}
+
+type ModerateReviewRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Note   string `json:"note"`
+}
+
+type ReviewResponse struct {
+	ReviewId       int        `json:"review_id"`
+	ProductCode    string     `json:"product_code"`
+	UserId         string     `json:"user_id"`
+	Rating         int        `json:"rating"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	Status         string     `json:"status"`
+	ModerationNote string     `json:"moderation_note,omitempty"`
+	HelpfulCount   int        `json:"helpful_count"`
+	ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ReviewPage is one page of a review listing. Page is 1-based and Total
+// counts the matching reviews across all pages.
+type ReviewPage struct {
+	Reviews  []*ReviewResponse `json:"reviews"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+	Total    int64             `json:"total"`
+}
+
+// RatingResponse aggregates the approved reviews of a product.
+type RatingResponse struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
@@ -0,0 +1,8 @@
+package enums
+
+type FileFormat string
+
+const (
+	FileFormatCSV    FileFormat = "csv"
+	FileFormatNDJSON FileFormat = "ndjson"
+)
@@ -0,0 +1,16 @@
+package enums
+
+type PriceChangeSource string
+
+const (
+	PriceChangeSourceManual   PriceChangeSource = "manual"
+	PriceChangeSourceSchedule PriceChangeSource = "schedule"
+)
+
+type PriceScheduleStatus string
+
+const (
+	PriceScheduleStatusPending   PriceScheduleStatus = "pending"
+	PriceScheduleStatusApplied   PriceScheduleStatus = "applied"
+	PriceScheduleStatusCancelled PriceScheduleStatus = "cancelled"
+)
@@ -0,0 +1,18 @@
+package enums
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusRejected ReviewStatus = "rejected"
+)
+
+type ReviewSort string
+
+const (
+	ReviewSortNewest  ReviewSort = "newest"
+	ReviewSortHelpful ReviewSort = "helpful"
+	ReviewSortHighest ReviewSort = "highest"
+	ReviewSortLowest  ReviewSort = "lowest"
+)
@@ -6,7 +6,9 @@ replace github.com/dinosgnk/agora-project/internal/pkg => ../../pkg
 
 require (
 	github.com/dinosgnk/agora-project/internal/pkg v1.0.0
-	github.com/prometheus/client_golang v1.22.0
+	github.com/google/uuid v1.6.0
+	golang.org/x/sync v0.16.0
+	gorm.io/driver/postgres v1.5.11
 	gorm.io/gorm v1.30.0
 )
 
@@ -22,14 +24,13 @@ require (
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_golang v1.22.0 // indirect
 	github.com/prometheus/client_model v0.6.1 // indirect
 	github.com/prometheus/common v0.62.0 // indirect
 	github.com/prometheus/procfs v0.15.1 // indirect
-	github.com/stretchr/testify v1.11.1 // indirect
+	github.com/rabbitmq/amqp091-go v1.10.0 // indirect
 	golang.org/x/crypto v0.40.0 // indirect
-	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/text v0.27.0 // indirect
 	google.golang.org/protobuf v1.36.9 // indirect
-	gorm.io/driver/postgres v1.5.11 // indirect
 )
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/service"
+)
+
+type CategoryHandler struct {
+	service service.ICategoryService
+	log     logger.Logger
+}
+
+func NewCategoryHandler(s service.ICategoryService, l logger.Logger) *CategoryHandler {
+	return &CategoryHandler{
+		service: s,
+		log:     l,
+	}
+}
+
+func (h *CategoryHandler) RegisterRoutes(mux *http.ServeMux) http.Handler {
+	mux.HandleFunc("GET /categories", h.GetCategoryTree)
+	mux.HandleFunc("GET /categories/{categoryId}", h.GetCategory)
+	mux.HandleFunc("POST /categories", h.CreateCategory)
+	mux.HandleFunc("PUT /categories/{categoryId}", h.UpdateCategory)
+	mux.HandleFunc("DELETE /categories/{categoryId}", h.DeleteCategory)
+
+	return mux
+}
+
+func (h *CategoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.service.GetCategoryTree()
+	if err != nil {
+		h.log.Error("Failed to get category tree", "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(categories)
+}
+
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	categoryId, ok := h.categoryId(w, r)
+	if !ok {
+		return
+	}
+
+	category, err := h.service.GetCategory(categoryId)
+	if err != nil {
+		h.log.Error("Failed to get category", "category_id", categoryId, "error", err.Error())
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req dto.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for create category", "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.service.CreateCategory(&req)
+	if err != nil {
+		h.log.Error("Failed to create category", "name", req.Name, "error", err.Error())
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	categoryId, ok := h.categoryId(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for update category", "category_id", categoryId, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.service.UpdateCategory(categoryId, &req)
+	if err != nil {
+		h.log.Error("Failed to update category", "category_id", categoryId, "error", err.Error())
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	categoryId, ok := h.categoryId(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.service.DeleteCategory(categoryId)
+	if err != nil {
+		h.log.Error("Failed to delete category", "category_id", categoryId, "error", err.Error())
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	if !deleted {
+		h.log.Warn("Category not found for deletion", "category_id", categoryId)
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CategoryHandler) categoryId(w http.ResponseWriter, r *http.Request) (int, bool) {
+	categoryId, err := strconv.Atoi(r.PathValue("categoryId"))
+	if err != nil {
+		http.Error(w, "category id must be a number", http.StatusBadRequest)
+		return 0, false
+	}
+	return categoryId, true
+}
+
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidCategory):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrDuplicateSlug), errors.Is(err, repository.ErrCategoryInUse):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/httpx"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/service"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/storage"
+)
+
+// maxMediaRequestSize bounds the whole multipart request. The service applies
+// the configured limit to the file itself.
+const maxMediaRequestSize = 64 << 20
+
+type MediaHandler struct {
+	service service.IMediaService
+	log     logger.Logger
+}
+
+func NewMediaHandler(s service.IMediaService, l logger.Logger) *MediaHandler {
+	return &MediaHandler{
+		service: s,
+		log:     l,
+	}
+}
+
+func (h *MediaHandler) RegisterRoutes(mux *http.ServeMux) http.Handler {
+	mux.HandleFunc("POST /products/{productCode}/media", h.UploadMedia)
+	mux.HandleFunc("PUT /products/{productCode}/media/order", h.ReorderMedia)
+	mux.HandleFunc("DELETE /products/{productCode}/media/{mediaId}", h.DeleteMedia)
+	mux.HandleFunc("GET /media/{key...}", h.ServeMedia)
+
+	return mux
+}
+
+// UploadMedia accepts a multipart/form-data request with the image in the
+// "file" field and appends it to the product's gallery.
+func (h *MediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxMediaRequestSize)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "request must be multipart/form-data", http.StatusBadRequest)
+		return
+	}
+
+	var media *dto.MediaResponse
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			http.Error(w, `missing "file" field`, http.StatusBadRequest)
+			return
+		}
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		media, err = h.service.UploadMedia(productCode, part)
+		part.Close()
+		if err != nil {
+			h.log.Warn("Failed to upload media", "product_code", productCode, "error", err.Error())
+			http.Error(w, err.Error(), mediaErrorStatus(err))
+			return
+		}
+		break
+	}
+
+	h.log.Info("Uploaded media", "product_code", productCode, "media_id", media.MediaId)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", media.URL)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(media)
+}
+
+func (h *MediaHandler) ReorderMedia(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+
+	var req dto.ReorderMediaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for reorder media", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	media, err := h.service.ReorderMedia(productCode, req.MediaIds)
+	if err != nil {
+		h.log.Error("Failed to reorder media", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), mediaErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(media)
+}
+
+func (h *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+	mediaId, err := strconv.Atoi(r.PathValue("mediaId"))
+	if err != nil {
+		http.Error(w, "media id must be a number", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.service.DeleteMedia(productCode, mediaId)
+	if err != nil {
+		h.log.Error("Failed to delete media", "product_code", productCode, "media_id", mediaId, "error", err.Error())
+		http.Error(w, err.Error(), mediaErrorStatus(err))
+		return
+	}
+
+	if !deleted {
+		h.log.Warn("Media not found for deletion", "product_code", productCode, "media_id", mediaId)
+		http.Error(w, "Media not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeMedia serves stored images and thumbnails. Keys are unique per upload
+// and never rewritten, so responses can be cached indefinitely.
+func (h *MediaHandler) ServeMedia(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+
+	blob, contentType, err := h.service.OpenMedia(key)
+	if errors.Is(err, storage.ErrBlobNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to open media", "key", key, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", httpx.ETag([]byte(key)))
+	http.ServeContent(w, r, "", blob.ModTime(), blob)
+}
+
+func mediaErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr), errors.Is(err, service.ErrMediaTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, service.ErrInvalidMedia):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrProductNotFound), errors.Is(err, repository.ErrMediaNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -1,79 +1,140 @@
 package handler
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/httpx"
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/mergepatch"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/service"
 )
 
+const (
+	maxImportSize = 64 << 20
+	maxPatchSize  = 1 << 20
+
+	// AdminTokenHeader carries the admin token that unlocks admin-only
+	// listing options
+	AdminTokenHeader = "X-Admin-Token"
+)
+
 type ProductHandler struct {
-	service *service.ProductService
-	log     logger.Logger
+	service    *service.ProductService
+	adminToken string
+	log        logger.Logger
+	resources  map[string]http.HandlerFunc
 }
 
-func NewProductHandler(s *service.ProductService, l logger.Logger) *ProductHandler {
+// NewProductHandler creates the product handler. An empty adminToken
+// disables the admin-only listing options.
+func NewProductHandler(s *service.ProductService, adminToken string, l logger.Logger) *ProductHandler {
 	return &ProductHandler{
-		service: s,
-		log:     l,
+		service:    s,
+		adminToken: adminToken,
+		log:        l,
+		resources:  make(map[string]http.HandlerFunc),
 	}
 }
 
+// HandleResource serves GET /products/{productCode}/{resource} with a handler
+// of another component, such as the reviews of the product.
+func (h *ProductHandler) HandleResource(resource string, handler http.HandlerFunc) {
+	h.resources[resource] = handler
+}
+
 func (h *ProductHandler) RegisterRoutes(mux *http.ServeMux) http.Handler {
 	mux.HandleFunc("GET /products", h.GetAllProducts)
 	mux.HandleFunc("GET /products/category/{category}", h.GetProductsByCategory)
 	mux.HandleFunc("GET /products/{productCode}", h.GetProductByCode)
 	mux.HandleFunc("POST /products", h.CreateProduct)
+	mux.HandleFunc("POST /products/import", h.ImportProducts)
+	mux.HandleFunc("GET /products/export", h.ExportProducts)
 	mux.HandleFunc("PUT /products/{productCode}", h.UpdateProduct)
+	mux.HandleFunc("PATCH /products/{productCode}", h.PatchProduct)
 	mux.HandleFunc("DELETE /products/{productCode}", h.DeleteProduct)
+	mux.HandleFunc("POST /products/{productCode}/restore", h.RestoreProduct)
+	mux.HandleFunc("GET /products/{productCode}/{resource}", h.GetProductResource)
+	mux.HandleFunc("POST /products/{productCode}/price-schedules", h.SchedulePriceChange)
+	mux.HandleFunc("DELETE /products/{productCode}/price-schedules/{scheduleId}", h.CancelPriceSchedule)
+	mux.HandleFunc("POST /products/{productCode}/variants", h.CreateVariant)
+	mux.HandleFunc("GET /variants/{sku}", h.GetVariantBySKU)
+	mux.HandleFunc("PUT /variants/{sku}", h.UpdateVariant)
+	mux.HandleFunc("DELETE /variants/{sku}", h.DeleteVariant)
 
 	return mux
 }
 
 func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
-	products, err := h.service.GetAllProducts()
+	var products []*model.Product
+	var err error
+	if filter := h.listingFilter(r); len(filter.Attributes) > 0 || filter.IncludeDeleted {
+		products, err = h.service.FilterProducts(filter)
+	} else {
+		products, err = h.service.GetAllProducts()
+	}
 	if err != nil {
 		h.log.Error("Failed to get all products", "error", err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(products)
+	if err := httpx.WriteConditionalListJSON(w, r, products); err != nil {
+		h.log.Error("Failed to write products response", "error", err.Error())
+	}
 }
 
 func (h *ProductHandler) GetProductsByCategory(w http.ResponseWriter, r *http.Request) {
 	category := r.PathValue("category")
+	includeDescendants, _ := strconv.ParseBool(r.URL.Query().Get("include_descendants"))
 
-	products, err := h.service.GetProductsByCategory(category)
+	products, err := h.service.GetProductsByCategory(category, includeDescendants, h.listingFilter(r))
+	if errors.Is(err, service.ErrUnknownCategory) {
+		h.log.Warn("Category not found", "category", category)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to get products by category", "category", category, "error", err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(products)
+	if err := httpx.WriteConditionalListJSON(w, r, products); err != nil {
+		h.log.Error("Failed to write products response", "error", err.Error())
+	}
 }
 
 func (h *ProductHandler) GetProductByCode(w http.ResponseWriter, r *http.Request) {
 	productCode := r.PathValue("productCode")
 
 	product, err := h.service.GetProductByCode(productCode)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		h.log.Warn("Product not found", "product_code", productCode)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to get product by code", "product_code", productCode, "error", err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(product)
+	if err := httpx.WriteConditionalJSON(w, r, product, product.UpdatedAt); err != nil {
+		h.log.Error("Failed to write product response", "product_code", productCode, "error", err.Error())
+	}
 }
 
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
@@ -85,6 +146,10 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	createdProduct, err := h.service.CreateProduct(&reqProduct)
+	if errors.Is(err, service.ErrInvalidVariant) || errors.Is(err, service.ErrUnknownCategory) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to create product", "product_code", reqProduct.ProductCode, "error", err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -96,6 +161,7 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(createdProduct)
 }
 
+// UpdateProduct replaces the product; fields omitted from the body are reset.
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	productCode := r.PathValue("productCode")
 
@@ -106,32 +172,98 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	product := &model.Product{
-		ProductCode: req.ProductCode,
-		Name:        req.Name,
-		Category:    req.Category,
-		Description: req.Description,
-		Price:       req.Price,
+	version, ok := h.checkPreconditions(w, r, productCode)
+	if !ok {
+		return
 	}
 
-	updatedProduct, err := h.service.UpdateProduct(productCode, product)
+	updatedProduct, err := h.service.UpdateProduct(productCode, &req, version)
 	if err != nil {
-		h.log.Error("Failed to update product", "product_code", productCode, "error", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		status := productErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			h.log.Error("Failed to update product", "product_code", productCode, "error", err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedProduct)
+}
+
+// PatchProduct applies a JSON Merge Patch to the product. Only the members
+// present in the patch change; null clears a member.
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergepatch.ContentType && mediaType != "application/json" {
+		w.Header().Set("Accept-Patch", mergepatch.ContentType)
+		http.Error(w, fmt.Sprintf("Content-Type must be %s", mergepatch.ContentType), http.StatusUnsupportedMediaType)
 		return
 	}
 
-	resp := dto.ProductResponse{
-		ProductCode: updatedProduct.ProductCode,
-		Name:        updatedProduct.Name,
-		Category:    updatedProduct.Category,
-		Description: updatedProduct.Description,
-		Price:       updatedProduct.Price,
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+	if err != nil {
+		h.log.Warn("Invalid request body for patch product", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, ok := h.checkPreconditions(w, r, productCode)
+	if !ok {
+		return
+	}
+
+	patchedProduct, err := h.service.PatchProduct(productCode, patch, version)
+	if err != nil {
+		status := productErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			h.log.Error("Failed to patch product", "product_code", productCode, "error", err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(patchedProduct)
+}
+
+// checkPreconditions evaluates If-Match and If-Unmodified-Since against the
+// current product and writes the error response when the write must not
+// proceed. It returns the version of the product the preconditions held for,
+// which the write is made conditional on, or nil without preconditions.
+func (h *ProductHandler) checkPreconditions(w http.ResponseWriter, r *http.Request, productCode string) (*time.Time, bool) {
+	if r.Header.Get("If-Match") == "" && r.Header.Get("If-Unmodified-Since") == "" {
+		return nil, true
+	}
+
+	current, err := h.service.GetProductByCode(productCode)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		h.log.Error("Failed to get product for precondition check", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	etag, err := httpx.JSONETag(current)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if httpx.PreconditionFailed(r, etag, current.UpdatedAt) {
+		h.log.Warn("Product was modified since it was read", "product_code", productCode)
+		httpx.SetValidators(w, etag, current.UpdatedAt)
+		http.Error(w, repository.ErrProductModified.Error(), http.StatusPreconditionFailed)
+		return nil, false
+	}
+	return &current.UpdatedAt, true
 }
 
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
@@ -152,3 +284,344 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *ProductHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+
+	product, err := h.service.RestoreProduct(productCode)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		h.log.Warn("Product not found for restore", "product_code", productCode)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to restore product", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+// GetProductResource serves the read-only sub-resources of a product. They
+// share one pattern because ServeMux rejects /products/{productCode}/prices
+// next to /products/category/{category}, which both match
+// /products/category/prices. The category listing remains the more specific
+// pattern and takes precedence.
+func (h *ProductHandler) GetProductResource(w http.ResponseWriter, r *http.Request) {
+	switch r.PathValue("resource") {
+	case "prices":
+		h.GetPriceHistory(w, r)
+	case "price-schedules":
+		h.GetPriceSchedules(w, r)
+	default:
+		if handler, ok := h.resources[r.PathValue("resource")]; ok {
+			handler(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}
+}
+
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	history, err := h.service.GetPriceHistory(productCode, limit)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		h.log.Warn("Product not found", "product_code", productCode)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to get price history", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *ProductHandler) GetPriceSchedules(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+
+	schedules, err := h.service.GetPriceSchedules(productCode)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		h.log.Warn("Product not found", "product_code", productCode)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to get price schedules", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedules)
+}
+
+func (h *ProductHandler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+
+	var req dto.PriceScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for schedule price change", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedules, err := h.service.SchedulePriceChange(productCode, &req)
+	if errors.Is(err, service.ErrInvalidPriceSchedule) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, repository.ErrProductNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to schedule price change", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(schedules)
+}
+
+func (h *ProductHandler) CancelPriceSchedule(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+	scheduleId, err := strconv.Atoi(r.PathValue("scheduleId"))
+	if err != nil {
+		http.Error(w, "schedule id must be a number", http.StatusBadRequest)
+		return
+	}
+
+	cancelled, err := h.service.CancelPriceSchedule(productCode, scheduleId)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to cancel price schedule", "product_code", productCode, "schedule_id", scheduleId, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !cancelled {
+		h.log.Warn("Pending price schedule not found", "product_code", productCode, "schedule_id", scheduleId)
+		http.Error(w, "Pending price schedule not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProductHandler) GetVariantBySKU(w http.ResponseWriter, r *http.Request) {
+	sku := r.PathValue("sku")
+
+	variant, err := h.service.GetVariantBySKU(sku)
+	if errors.Is(err, repository.ErrVariantNotFound) {
+		h.log.Warn("Variant not found", "sku", sku)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to get variant by sku", "sku", sku, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := httpx.WriteConditionalJSON(w, r, variant, time.Time{}); err != nil {
+		h.log.Error("Failed to write variant response", "sku", sku, "error", err.Error())
+	}
+}
+
+func (h *ProductHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+
+	var req dto.VariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for create variant", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	variant, err := h.service.CreateVariant(productCode, &req)
+	if err != nil {
+		h.log.Error("Failed to create variant", "product_code", productCode, "sku", req.SKU, "error", err.Error())
+		http.Error(w, err.Error(), variantErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(variant)
+}
+
+func (h *ProductHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	sku := r.PathValue("sku")
+
+	var req dto.VariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for update variant", "sku", sku, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	variant, err := h.service.UpdateVariant(sku, &req)
+	if err != nil {
+		h.log.Error("Failed to update variant", "sku", sku, "error", err.Error())
+		http.Error(w, err.Error(), variantErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(variant)
+}
+
+func (h *ProductHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	sku := r.PathValue("sku")
+
+	deleted, err := h.service.DeleteVariant(sku)
+	if err != nil {
+		h.log.Error("Failed to delete variant", "sku", sku, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !deleted {
+		h.log.Warn("Variant not found for deletion", "sku", sku)
+		http.Error(w, "Variant not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProductHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	format := fileFormat(r.URL.Query().Get("format"), r.Header.Get("Content-Type"))
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	report, err := h.service.ImportProducts(body, format, dryRun)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.log.Warn("Failed to import products", "format", format, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.log.Info("Imported products", "format", format, "dry_run", dryRun, "total", report.Total, "imported", report.Imported, "failed", report.Failed)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *ProductHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	format := fileFormat(r.URL.Query().Get("format"), "")
+	if format == "" {
+		format = enums.FileFormatCSV
+	}
+
+	switch format {
+	case enums.FileFormatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+	case enums.FileFormatNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	default:
+		http.Error(w, "format must be csv or ndjson", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format))
+
+	// The status line is already sent once streaming starts, so a failure
+	// part way through can only be logged and the body left truncated.
+	if err := h.service.ExportProducts(w, format); err != nil {
+		h.log.Error("Failed to export products", "format", format, "error", err.Error())
+	}
+}
+
+// listingFilter reads the attribute filters and ?include_deleted=true, which
+// lists soft-deleted products as well. include_deleted is ignored unless the
+// request is made by an admin.
+func (h *ProductHandler) listingFilter(r *http.Request) repository.ProductFilter {
+	includeDeleted, _ := strconv.ParseBool(r.URL.Query().Get("include_deleted"))
+	if includeDeleted && !h.isAdmin(r) {
+		h.log.Warn("Ignoring include_deleted for a non-admin request", "path", r.URL.Path)
+		includeDeleted = false
+	}
+	return repository.ProductFilter{
+		Attributes:     attributeFilters(r),
+		IncludeDeleted: includeDeleted,
+	}
+}
+
+// isAdmin reports whether the request carries the configured admin token.
+func (h *ProductHandler) isAdmin(r *http.Request) bool {
+	token := r.Header.Get(AdminTokenHeader)
+	return h.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) == 1
+}
+
+// attributeFilters collects attr.<name>=<value> query parameters.
+func attributeFilters(r *http.Request) map[string]string {
+	attributes := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		if name, ok := strings.CutPrefix(key, "attr."); ok && name != "" && len(values) > 0 {
+			attributes[name] = values[0]
+		}
+	}
+	return attributes
+}
+
+func productErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidProduct), errors.Is(err, service.ErrUnknownCategory):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrProductModified):
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
+func variantErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidVariant):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrProductNotFound), errors.Is(err, repository.ErrVariantNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// fileFormat resolves the import/export format from the format query
+// parameter, falling back to the request Content-Type.
+func fileFormat(query string, contentType string) enums.FileFormat {
+	if query != "" {
+		return enums.FileFormat(strings.ToLower(query))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return enums.FileFormatCSV
+	case "application/x-ndjson", "application/jsonl":
+		return enums.FileFormatNDJSON
+	}
+	return ""
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/orders"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/service"
+)
+
+// ReviewHandler serves product reviews. The review listing of a product is
+// served through ProductHandler.HandleResource, since it shares the
+// /products/{productCode}/{resource} pattern.
+type ReviewHandler struct {
+	service service.IReviewService
+	log     logger.Logger
+}
+
+func NewReviewHandler(s service.IReviewService, l logger.Logger) *ReviewHandler {
+	return &ReviewHandler{
+		service: s,
+		log:     l,
+	}
+}
+
+func (h *ReviewHandler) RegisterRoutes(mux *http.ServeMux) http.Handler {
+	mux.HandleFunc("POST /products/{productCode}/reviews", h.CreateReview)
+	mux.HandleFunc("GET /reviews", h.GetReviews)
+	mux.HandleFunc("GET /reviews/{reviewId}", h.GetReview)
+	mux.HandleFunc("PUT /reviews/{reviewId}/status", h.ModerateReview)
+	mux.HandleFunc("PUT /reviews/{reviewId}/votes/{userId}", h.AddHelpfulVote)
+	mux.HandleFunc("DELETE /reviews/{reviewId}/votes/{userId}", h.RemoveHelpfulVote)
+
+	return mux
+}
+
+func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+
+	var req dto.CreateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for create review", "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.service.CreateReview(productCode, &req)
+	if err != nil {
+		status := reviewErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			h.log.Error("Failed to create review", "product_code", productCode, "user_id", req.UserId, "error", err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(review)
+}
+
+// GetProductReviews lists the approved reviews of a product. It accepts the
+// page, page_size and sort (newest, helpful, highest, lowest) parameters.
+func (h *ReviewHandler) GetProductReviews(w http.ResponseWriter, r *http.Request) {
+	productCode := r.PathValue("productCode")
+	page, pageSize := pagination(r)
+
+	reviews, err := h.service.GetProductReviews(productCode, page, pageSize, enums.ReviewSort(r.URL.Query().Get("sort")))
+	if err != nil {
+		status := reviewErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			h.log.Error("Failed to get product reviews", "product_code", productCode, "error", err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// GetReviews lists the reviews of all products for moderation, optionally
+// filtered by the status parameter.
+func (h *ReviewHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := pagination(r)
+
+	reviews, err := h.service.GetReviews(enums.ReviewStatus(r.URL.Query().Get("status")), page, pageSize)
+	if err != nil {
+		status := reviewErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			h.log.Error("Failed to get reviews", "error", err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reviews)
+}
+
+func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	reviewId, ok := h.reviewId(w, r)
+	if !ok {
+		return
+	}
+
+	review, err := h.service.GetReview(reviewId)
+	if err != nil {
+		status := reviewErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			h.log.Error("Failed to get review", "review_id", reviewId, "error", err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(review)
+}
+
+func (h *ReviewHandler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	reviewId, ok := h.reviewId(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ModerateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body for moderate review", "review_id", reviewId, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.service.ModerateReview(reviewId, &req)
+	if err != nil {
+		status := reviewErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			h.log.Error("Failed to moderate review", "review_id", reviewId, "error", err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(review)
+}
+
+func (h *ReviewHandler) AddHelpfulVote(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, h.service.AddHelpfulVote)
+}
+
+func (h *ReviewHandler) RemoveHelpfulVote(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, h.service.RemoveHelpfulVote)
+}
+
+func (h *ReviewHandler) vote(w http.ResponseWriter, r *http.Request, apply func(reviewId int, userId string) (*dto.ReviewResponse, error)) {
+	reviewId, ok := h.reviewId(w, r)
+	if !ok {
+		return
+	}
+	userId := r.PathValue("userId")
+
+	review, err := apply(reviewId, userId)
+	if err != nil {
+		status := reviewErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			h.log.Error("Failed to update helpful vote", "review_id", reviewId, "user_id", userId, "error", err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(review)
+}
+
+func (h *ReviewHandler) reviewId(w http.ResponseWriter, r *http.Request) (int, bool) {
+	reviewId, err := strconv.Atoi(r.PathValue("reviewId"))
+	if err != nil {
+		http.Error(w, "review id must be a number", http.StatusBadRequest)
+		return 0, false
+	}
+	return reviewId, true
+}
+
+// pagination reads the page and page_size query parameters. Missing or
+// invalid values are left to the service defaults.
+func pagination(r *http.Request) (int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	return page, pageSize
+}
+
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidReview), errors.Is(err, service.ErrInvalidVote):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrReviewNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrProductNotFound), errors.Is(err, repository.ErrReviewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrDuplicateReview):
+		return http.StatusConflict
+	case errors.Is(err, orders.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,71 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+	"github.com/google/uuid"
+)
+
+type ProductCacheInvalidator interface {
+	InvalidateProduct(productCode string)
+}
+
+// CacheInvalidationConsumer listens for product events from every catalog
+// instance and evicts the affected products from the local cache. Each
+// instance binds its own temporary queue so that all of them see every event.
+type CacheInvalidationConsumer struct {
+	client rabbitmq.Broker
+	queue  string
+	cache  ProductCacheInvalidator
+	log    logger.Logger
+}
+
+func NewCacheInvalidationConsumer(client rabbitmq.Broker, cache ProductCacheInvalidator, log logger.Logger) (*CacheInvalidationConsumer, error) {
+	queue := "catalog.cache-invalidation." + uuid.New().String()
+
+	if err := client.DeclareExchange(events.CatalogExchange, "topic"); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	if err := client.DeclareTemporaryQueue(queue); err != nil {
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	if err := client.BindQueue(queue, events.CatalogExchange, "product.*"); err != nil {
+		return nil, fmt.Errorf("failed to bind queue: %w", err)
+	}
+
+	return &CacheInvalidationConsumer{
+		client: client,
+		queue:  queue,
+		cache:  cache,
+		log:    log,
+	}, nil
+}
+
+func (c *CacheInvalidationConsumer) Start() error {
+	c.log.Info("Starting cache invalidation consumer", "queue", c.queue)
+
+	router := rabbitmq.NewRouter(c.log)
+	router.AddMiddleware(rabbitmq.Tracing())
+	router.AddMiddleware(rabbitmq.Logging(c.log))
+	router.AddMiddleware(rabbitmq.Metrics())
+	router.AddMiddleware(rabbitmq.Recovery(c.log))
+	for _, routingKey := range []string{events.ProductCreated, events.ProductUpdated, events.ProductDeleted, events.ProductRestored, events.ProductImported} {
+		rabbitmq.Handle(router, routingKey, c.invalidate)
+	}
+
+	_, err := c.client.ConsumeWithOptions(context.Background(), c.queue, router.BuildHandler(), rabbitmq.ConsumeOptions{})
+	return err
+}
+
+// invalidate evicts the product of any product event, all of which embed
+// events.ProductEvent.
+func (c *CacheInvalidationConsumer) invalidate(_ context.Context, event events.Envelope[events.ProductEvent]) error {
+	c.cache.InvalidateProduct(event.Data.ProductCode)
+	return nil
+}
@@ -0,0 +1,61 @@
+package messaging
+
+import (
+	"fmt"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+)
+
+// producer identifies the service in the envelope of its events
+const producer = "catalog-service"
+
+type Publisher struct {
+	client rabbitmq.Broker
+}
+
+func NewPublisher(client rabbitmq.Broker) (*Publisher, error) {
+	if err := client.DeclareExchange(events.CatalogExchange, "topic"); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	return &Publisher{
+		client: client,
+	}, nil
+}
+
+func (p *Publisher) PublishProductCreated(event *events.ProductCreatedEvent) error {
+	return p.client.PublishMessage(events.CatalogExchange, events.ProductCreated, events.New(producer, *event))
+}
+
+func (p *Publisher) PublishProductUpdated(event *events.ProductUpdatedEvent) error {
+	return p.client.PublishMessage(events.CatalogExchange, events.ProductUpdated, events.New(producer, *event))
+}
+
+func (p *Publisher) PublishProductDeleted(event *events.ProductDeletedEvent) error {
+	return p.client.PublishMessage(events.CatalogExchange, events.ProductDeleted, events.New(producer, *event))
+}
+
+func (p *Publisher) PublishProductRestored(event *events.ProductRestoredEvent) error {
+	return p.client.PublishMessage(events.CatalogExchange, events.ProductRestored, events.New(producer, *event))
+}
+
+func (p *Publisher) PublishProductImported(event *events.ProductImportedEvent) error {
+	return p.client.PublishMessage(events.CatalogExchange, events.ProductImported, events.New(producer, *event))
+}
+
+// PublishProductsImported publishes the events of an import as one batch,
+// waiting for the broker to confirm them together. A failure is reported as
+// a *rabbitmq.BatchError holding the errors of the individual events.
+func (p *Publisher) PublishProductsImported(imported []*events.ProductImportedEvent) error {
+	messages := make([]rabbitmq.Message, len(imported))
+	for i, event := range imported {
+		messages[i] = rabbitmq.Message{
+			Exchange:   events.CatalogExchange,
+			RoutingKey: events.ProductImported,
+			Body:       events.New(producer, *event),
+		}
+	}
+
+	return p.client.PublishBatch(messages)
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type Category struct {
+	CategoryId int       `gorm:"primaryKey;column:id"`
+	ParentId   *int      `gorm:"column:parent_id"`
+	Name       string    `gorm:"column:name"`
+	Slug       string    `gorm:"column:slug"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
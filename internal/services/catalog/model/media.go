@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+)
+
+// ProductMedia is an image in a product's gallery. StorageKey locates the
+// original upload; Thumbnails maps each thumbnail size name to its key.
+type ProductMedia struct {
+	MediaId     int              `gorm:"primaryKey;column:id"`
+	ProductId   int              `gorm:"column:product_id"`
+	Position    int              `gorm:"column:position"`
+	ContentType string           `gorm:"column:content_type"`
+	StorageKey  string           `gorm:"column:storage_key"`
+	Thumbnails  postgres.JSONMap `gorm:"column:thumbnails"`
+	Width       int              `gorm:"column:width"`
+	Height      int              `gorm:"column:height"`
+	SizeBytes   int64            `gorm:"column:size_bytes"`
+	CreatedAt   time.Time        `gorm:"column:created_at;autoCreateTime"`
+}
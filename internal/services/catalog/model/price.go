@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+)
+
+// PriceHistory records one change of a product's price.
+type PriceHistory struct {
+	HistoryId  int                     `gorm:"primaryKey;column:id"`
+	ProductId  int                     `gorm:"column:product_id"`
+	OldPrice   float64                 `gorm:"column:old_price"`
+	NewPrice   float64                 `gorm:"column:new_price"`
+	Source     enums.PriceChangeSource `gorm:"column:source"`
+	ScheduleId *int                    `gorm:"column:schedule_id"`
+	ChangedAt  time.Time               `gorm:"column:changed_at"`
+	Product    *Product                `gorm:"foreignKey:ProductId;references:ProductId"`
+}
+
+// PriceSchedule is a price change that takes effect at EffectiveAt. A
+// schedule with a ParentId reverts its parent, e.g. at the end of a sale.
+type PriceSchedule struct {
+	ScheduleId  int                       `gorm:"primaryKey;column:id"`
+	ProductId   int                       `gorm:"column:product_id"`
+	ParentId    *int                      `gorm:"column:parent_id"`
+	Price       float64                   `gorm:"column:price"`
+	EffectiveAt time.Time                 `gorm:"column:effective_at"`
+	Status      enums.PriceScheduleStatus `gorm:"column:status"`
+	AppliedAt   *time.Time                `gorm:"column:applied_at"`
+	CreatedAt   time.Time                 `gorm:"column:created_at;autoCreateTime"`
+}
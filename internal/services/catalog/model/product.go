@@ -1,10 +1,30 @@
 package model
 
+import (
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+	"gorm.io/gorm"
+)
+
+// Product is a catalog entry. RatingAverage and RatingCount aggregate its
+// approved reviews and are maintained by the review repository.
 type Product struct {
-	ProductId   string  `gorm:"primaryKey;column:id"`
-	ProductCode string  `gorm:"column:product_code"`
-	Name        string  `gorm:"column:name"`
-	Category    string  `gorm:"column:category"`
-	Description string  `gorm:"column:description"`
-	Price       float64 `gorm:"column:price"`
+	ProductId     int               `gorm:"primaryKey;column:id"`
+	ProductCode   string            `gorm:"column:product_code"`
+	Name          string            `gorm:"column:name"`
+	Category      string            `gorm:"column:category"`
+	CategoryId    *int              `gorm:"column:category_id"`
+	Description   string            `gorm:"column:description"`
+	Price         float64           `gorm:"column:price"`
+	Stock         int               `gorm:"column:stock"`
+	MaxOrderQty   int               `gorm:"column:max_order_quantity"`
+	Attributes    postgres.JSONMap  `gorm:"column:attributes"`
+	RatingAverage float64           `gorm:"column:rating_average"`
+	RatingCount   int               `gorm:"column:rating_count"`
+	Variants      []*ProductVariant `gorm:"foreignKey:ProductId;references:ProductId"`
+	Media         []*ProductMedia   `gorm:"foreignKey:ProductId;references:ProductId"`
+	CreatedAt     time.Time         `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time         `gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt    `gorm:"column:deleted_at"`
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+)
+
+// Review is a user's rating of a product. Only approved reviews are listed
+// publicly and counted in the product's rating.
+type Review struct {
+	ReviewId       int                `gorm:"primaryKey;column:id"`
+	ProductId      int                `gorm:"column:product_id"`
+	UserId         string             `gorm:"column:user_id"`
+	Rating         int                `gorm:"column:rating"`
+	Title          string             `gorm:"column:title"`
+	Body           string             `gorm:"column:body"`
+	Status         enums.ReviewStatus `gorm:"column:status"`
+	ModerationNote string             `gorm:"column:moderation_note"`
+	HelpfulCount   int                `gorm:"column:helpful_count"`
+	ModeratedAt    *time.Time         `gorm:"column:moderated_at"`
+	CreatedAt      time.Time          `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time          `gorm:"column:updated_at;autoUpdateTime"`
+	Product        *Product           `gorm:"foreignKey:ProductId;references:ProductId"`
+}
+
+// ReviewVote marks a review as helpful to a user.
+type ReviewVote struct {
+	ReviewId  int       `gorm:"primaryKey;column:review_id"`
+	UserId    string    `gorm:"primaryKey;column:user_id"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
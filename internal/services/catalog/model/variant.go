@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+)
+
+// ProductVariant is a purchasable version of a product, e.g. a size and
+// colour combination. A nil Price inherits the parent product's price.
+type ProductVariant struct {
+	VariantId  int              `gorm:"primaryKey;column:id"`
+	ProductId  int              `gorm:"column:product_id"`
+	SKU        string           `gorm:"column:sku"`
+	Price      *float64         `gorm:"column:price"`
+	Stock      int              `gorm:"column:stock"`
+	Attributes postgres.JSONMap `gorm:"column:attributes"`
+	Product    *Product         `gorm:"foreignKey:ProductId;references:ProductId"`
+	CreatedAt  time.Time        `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time        `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (v *ProductVariant) EffectivePrice(parentPrice float64) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return parentPrice
+}
@@ -0,0 +1,54 @@
+package orders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrUnavailable = errors.New("order service unavailable")
+
+type IOrderClient interface {
+	// HasDeliveredProduct reports whether the user has received the product
+	// in a delivered order
+	HasDeliveredProduct(userId string, productCode string) (bool, error)
+}
+
+type HttpOrderClient struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+func NewHttpOrderClient(baseUrl string) *HttpOrderClient {
+	return &HttpOrderClient{
+		baseUrl: strings.TrimRight(baseUrl, "/"),
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+func (c *HttpOrderClient) HasDeliveredProduct(userId string, productCode string) (bool, error) {
+	resp, err := c.httpClient.Get(c.baseUrl + "/orders/user/" + url.PathEscape(userId) + "/delivered/" + url.PathEscape(productCode))
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%w: order service returned status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var delivered struct {
+		Delivered bool `json:"delivered"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&delivered); err != nil {
+		return false, fmt.Errorf("failed to decode delivered product: %w", err)
+	}
+
+	return delivered.Delivered, nil
+}
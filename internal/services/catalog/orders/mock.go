@@ -0,0 +1,41 @@
+package orders
+
+import "sync"
+
+type MockOrderClient struct {
+	delivered map[string]bool
+	err       error
+	mu        sync.RWMutex
+}
+
+func NewMockOrderClient() *MockOrderClient {
+	return &MockOrderClient{
+		delivered: make(map[string]bool),
+	}
+}
+
+func (c *MockOrderClient) HasDeliveredProduct(userId string, productCode string) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.err != nil {
+		return false, c.err
+	}
+	return c.delivered[userId+"/"+productCode], nil
+}
+
+// SetDelivered records a delivered order of the product for the user.
+func (c *MockOrderClient) SetDelivered(userId string, productCode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.delivered[userId+"/"+productCode] = true
+}
+
+// SetError makes every call fail with err until it is reset with nil.
+func (c *MockOrderClient) SetError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.err = err
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/cache"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedProductRepository is a read-through cache in front of another
+// IProductRepository. Single-product lookups are served from the cache;
+// concurrent misses for the same product code share one backend query.
+//
+// Each product code has a generation that InvalidateProduct bumps. A load
+// only caches its result when the generation did not change while it ran, so
+// a load that read the product before a write cannot re-cache the old row.
+type CachedProductRepository struct {
+	next  IProductRepository
+	cache cache.Cache[*model.Product]
+	group singleflight.Group
+
+	mu          sync.Mutex
+	generations map[string]uint64
+}
+
+func NewCachedProductRepository(next IProductRepository, c cache.Cache[*model.Product]) *CachedProductRepository {
+	return &CachedProductRepository{
+		next:        next,
+		cache:       c,
+		generations: make(map[string]uint64),
+	}
+}
+
+func (repo *CachedProductRepository) GetAllProducts() ([]*model.Product, error) {
+	return repo.next.GetAllProducts()
+}
+
+func (repo *CachedProductRepository) GetProductsByCategory(category string) ([]*model.Product, error) {
+	return repo.next.GetProductsByCategory(category)
+}
+
+func (repo *CachedProductRepository) GetProductsByFilter(filter ProductFilter) ([]*model.Product, error) {
+	return repo.next.GetProductsByFilter(filter)
+}
+
+func (repo *CachedProductRepository) GetProductByCode(productCode string) (*model.Product, error) {
+	if product, ok := repo.cache.Get(productCode); ok {
+		return copyProduct(product), nil
+	}
+
+	result, err, _ := repo.group.Do(productCode, func() (interface{}, error) {
+		generation := repo.generation(productCode)
+		product, err := repo.next.GetProductByCode(productCode)
+		if err != nil {
+			return nil, err
+		}
+		repo.setIfCurrent(productCode, product, generation)
+		return product, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyProduct(result.(*model.Product)), nil
+}
+
+func (repo *CachedProductRepository) CreateProduct(product *model.Product) (*model.Product, error) {
+	created, err := repo.next.CreateProduct(product)
+	if err != nil {
+		return nil, err
+	}
+	repo.InvalidateProduct(created.ProductCode)
+	return created, nil
+}
+
+func (repo *CachedProductRepository) UpdateProduct(product *model.Product, version *time.Time) (*model.Product, error) {
+	updated, err := repo.next.UpdateProduct(product, version)
+	repo.InvalidateProduct(product.ProductCode)
+	return updated, err
+}
+
+func (repo *CachedProductRepository) DeleteProduct(productCode string) (bool, error) {
+	deleted, err := repo.next.DeleteProduct(productCode)
+	repo.InvalidateProduct(productCode)
+	return deleted, err
+}
+
+func (repo *CachedProductRepository) RestoreProduct(productCode string) (bool, error) {
+	restored, err := repo.next.RestoreProduct(productCode)
+	repo.InvalidateProduct(productCode)
+	return restored, err
+}
+
+func (repo *CachedProductRepository) PurgeDeletedProducts(deletedBefore time.Time) ([]*model.Product, error) {
+	return repo.next.PurgeDeletedProducts(deletedBefore)
+}
+
+func (repo *CachedProductRepository) GetVariantBySKU(sku string) (*model.ProductVariant, error) {
+	return repo.next.GetVariantBySKU(sku)
+}
+
+func (repo *CachedProductRepository) CreateVariant(productCode string, variant *model.ProductVariant) (*model.ProductVariant, error) {
+	created, err := repo.next.CreateVariant(productCode, variant)
+	repo.InvalidateProduct(productCode)
+	return created, err
+}
+
+func (repo *CachedProductRepository) UpdateVariant(variant *model.ProductVariant) (*model.ProductVariant, error) {
+	productCode := repo.variantProductCode(variant.SKU)
+	updated, err := repo.next.UpdateVariant(variant)
+	repo.InvalidateProduct(productCode)
+	return updated, err
+}
+
+func (repo *CachedProductRepository) DeleteVariant(sku string) (bool, error) {
+	productCode := repo.variantProductCode(sku)
+	deleted, err := repo.next.DeleteVariant(sku)
+	repo.InvalidateProduct(productCode)
+	return deleted, err
+}
+
+func (repo *CachedProductRepository) AddMedia(productCode string, media *model.ProductMedia) (*model.ProductMedia, error) {
+	added, err := repo.next.AddMedia(productCode, media)
+	repo.InvalidateProduct(productCode)
+	return added, err
+}
+
+func (repo *CachedProductRepository) DeleteMedia(productCode string, mediaId int) (bool, error) {
+	deleted, err := repo.next.DeleteMedia(productCode, mediaId)
+	repo.InvalidateProduct(productCode)
+	return deleted, err
+}
+
+func (repo *CachedProductRepository) ReorderMedia(productCode string, mediaIds []int) error {
+	err := repo.next.ReorderMedia(productCode, mediaIds)
+	repo.InvalidateProduct(productCode)
+	return err
+}
+
+func (repo *CachedProductRepository) GetPriceHistory(productCode string, limit int) ([]*model.PriceHistory, error) {
+	return repo.next.GetPriceHistory(productCode, limit)
+}
+
+func (repo *CachedProductRepository) GetPriceSchedules(productCode string) ([]*model.PriceSchedule, error) {
+	return repo.next.GetPriceSchedules(productCode)
+}
+
+func (repo *CachedProductRepository) CreatePriceSchedule(productCode string, schedule *model.PriceSchedule, revert *model.PriceSchedule) ([]*model.PriceSchedule, error) {
+	return repo.next.CreatePriceSchedule(productCode, schedule, revert)
+}
+
+func (repo *CachedProductRepository) CancelPriceSchedule(productCode string, scheduleId int) (bool, error) {
+	return repo.next.CancelPriceSchedule(productCode, scheduleId)
+}
+
+func (repo *CachedProductRepository) ApplyDuePriceSchedule(now time.Time) (*model.PriceHistory, error) {
+	history, err := repo.next.ApplyDuePriceSchedule(now)
+	if history != nil && history.Product != nil {
+		repo.InvalidateProduct(history.Product.ProductCode)
+	}
+	return history, err
+}
+
+func (repo *CachedProductRepository) NextPriceScheduleTime() (*time.Time, error) {
+	return repo.next.NextPriceScheduleTime()
+}
+
+func (repo *CachedProductRepository) UpsertProducts(products []*model.Product) error {
+	err := repo.next.UpsertProducts(products)
+	for _, product := range products {
+		repo.InvalidateProduct(product.ProductCode)
+	}
+	return err
+}
+
+func (repo *CachedProductRepository) StreamProducts(fn func(*model.Product) error) error {
+	return repo.next.StreamProducts(fn)
+}
+
+func (repo *CachedProductRepository) RenameCategory(categoryId int, name string) ([]*model.Product, error) {
+	renamed, err := repo.next.RenameCategory(categoryId, name)
+	for _, product := range renamed {
+		repo.InvalidateProduct(product.ProductCode)
+	}
+	return renamed, err
+}
+
+// InvalidateProduct drops a product from the cache. It is called after local
+// writes and for product events published by other catalog instances.
+func (repo *CachedProductRepository) InvalidateProduct(productCode string) {
+	repo.mu.Lock()
+	repo.generations[productCode]++
+	repo.mu.Unlock()
+
+	repo.group.Forget(productCode)
+	repo.cache.Delete(productCode)
+}
+
+func (repo *CachedProductRepository) generation(productCode string) uint64 {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return repo.generations[productCode]
+}
+
+// setIfCurrent caches a loaded product unless the product was invalidated
+// since the load started.
+func (repo *CachedProductRepository) setIfCurrent(productCode string, product *model.Product, generation uint64) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.generations[productCode] == generation {
+		repo.cache.Set(productCode, product)
+	}
+}
+
+// variantProductCode resolves the parent of a variant, whose cached
+// representation includes its variants and must be dropped on variant writes.
+func (repo *CachedProductRepository) variantProductCode(sku string) string {
+	variant, err := repo.next.GetVariantBySKU(sku)
+	if err != nil || variant.Product == nil {
+		return ""
+	}
+	return variant.Product.ProductCode
+}
+
+// copyProduct keeps callers from mutating the cached instance.
+func copyProduct(product *model.Product) *model.Product {
+	productCopy := *product
+	productCopy.Attributes = maps.Clone(product.Attributes)
+	if product.Variants != nil {
+		productCopy.Variants = make([]*model.ProductVariant, len(product.Variants))
+		for i, variant := range product.Variants {
+			variantCopy := *variant
+			variantCopy.Attributes = maps.Clone(variant.Attributes)
+			productCopy.Variants[i] = &variantCopy
+		}
+	}
+	if product.Media != nil {
+		productCopy.Media = make([]*model.ProductMedia, len(product.Media))
+		for i, media := range product.Media {
+			mediaCopy := *media
+			mediaCopy.Thumbnails = maps.Clone(media.Thumbnails)
+			productCopy.Media[i] = &mediaCopy
+		}
+	}
+	return &productCopy
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/cache"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+)
+
+type countingProductRepository struct {
+	IProductRepository
+	products map[string]*model.Product
+	lookups  atomic.Int32
+	delay    time.Duration
+
+	// When resume is set, lookups signal loaded after reading the product
+	// and wait for resume before returning it
+	loaded chan struct{}
+	resume chan struct{}
+}
+
+func (repo *countingProductRepository) GetProductByCode(productCode string) (*model.Product, error) {
+	repo.lookups.Add(1)
+	time.Sleep(repo.delay)
+	product, ok := repo.products[productCode]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+	productCopy := *product
+	if repo.resume != nil {
+		repo.loaded <- struct{}{}
+		<-repo.resume
+	}
+	return &productCopy, nil
+}
+
+func (repo *countingProductRepository) UpdateProduct(product *model.Product, _ *time.Time) (*model.Product, error) {
+	repo.products[product.ProductCode] = product
+	return product, nil
+}
+
+func (repo *countingProductRepository) RenameCategory(categoryId int, name string) ([]*model.Product, error) {
+	var renamed []*model.Product
+	for code, product := range repo.products {
+		if product.CategoryId != nil && *product.CategoryId == categoryId {
+			productCopy := *product
+			productCopy.Category = name
+			repo.products[code] = &productCopy
+			renamed = append(renamed, &productCopy)
+		}
+	}
+	return renamed, nil
+}
+
+func newCachedTestRepository(delay time.Duration) (*CachedProductRepository, *countingProductRepository) {
+	backend := &countingProductRepository{
+		products: map[string]*model.Product{
+			"PROD-1": {ProductCode: "PROD-1", Name: "Keyboard", Price: 50},
+		},
+		delay: delay,
+	}
+	c := cache.NewLRU[*model.Product]("test_catalog_products", 10, time.Minute)
+	return NewCachedProductRepository(backend, c), backend
+}
+
+func TestCachedGetProductByCodeServesRepeatedReadsFromCache(t *testing.T) {
+	repo, backend := newCachedTestRepository(0)
+
+	for i := 0; i < 3; i++ {
+		product, err := repo.GetProductByCode("PROD-1")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if product.Name != "Keyboard" {
+			t.Errorf("Expected Keyboard, got %s", product.Name)
+		}
+	}
+
+	if backend.lookups.Load() != 1 {
+		t.Errorf("Expected 1 backend lookup, got %d", backend.lookups.Load())
+	}
+}
+
+func TestCachedGetProductByCodeCoalescesConcurrentMisses(t *testing.T) {
+	repo, backend := newCachedTestRepository(50 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.GetProductByCode("PROD-1"); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if backend.lookups.Load() != 1 {
+		t.Errorf("Expected 1 backend lookup, got %d", backend.lookups.Load())
+	}
+}
+
+func TestCachedGetProductByCodeDoesNotCacheMisses(t *testing.T) {
+	repo, backend := newCachedTestRepository(0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetProductByCode("MISSING"); err != ErrProductNotFound {
+			t.Fatalf("Expected ErrProductNotFound, got %v", err)
+		}
+	}
+
+	if backend.lookups.Load() != 2 {
+		t.Errorf("Expected 2 backend lookups, got %d", backend.lookups.Load())
+	}
+}
+
+func TestCachedUpdateProductInvalidatesEntry(t *testing.T) {
+	repo, _ := newCachedTestRepository(0)
+
+	product, _ := repo.GetProductByCode("PROD-1")
+	product.Price = 40
+	if _, err := repo.UpdateProduct(product, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	updated, err := repo.GetProductByCode("PROD-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Price != 40 {
+		t.Errorf("Expected price 40 after update, got %.2f", updated.Price)
+	}
+}
+
+func TestCachedInvalidateProductForcesReload(t *testing.T) {
+	repo, backend := newCachedTestRepository(0)
+
+	repo.GetProductByCode("PROD-1")
+	repo.InvalidateProduct("PROD-1")
+	repo.GetProductByCode("PROD-1")
+
+	if backend.lookups.Load() != 2 {
+		t.Errorf("Expected 2 backend lookups, got %d", backend.lookups.Load())
+	}
+}
+
+func TestCachedInvalidateDuringLoadDoesNotCacheStaleProduct(t *testing.T) {
+	repo, backend := newCachedTestRepository(0)
+	backend.loaded = make(chan struct{}, 2)
+	backend.resume = make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		repo.GetProductByCode("PROD-1")
+	}()
+
+	// The load has read the old price when the update lands
+	<-backend.loaded
+	if _, err := repo.UpdateProduct(&model.Product{ProductCode: "PROD-1", Name: "Keyboard", Price: 40}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	close(backend.resume)
+	<-done
+
+	product, err := repo.GetProductByCode("PROD-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if product.Price != 40 {
+		t.Errorf("Expected price 40 after update, got %.2f", product.Price)
+	}
+}
+
+func TestCachedRenameCategoryInvalidatesRenamedProducts(t *testing.T) {
+	repo, backend := newCachedTestRepository(0)
+	categoryId := 7
+	backend.products["PROD-1"].CategoryId = &categoryId
+	backend.products["PROD-1"].Category = "Computers"
+
+	if _, err := repo.GetProductByCode("PROD-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.RenameCategory(categoryId, "Peripherals"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	product, err := repo.GetProductByCode("PROD-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if product.Category != "Peripherals" {
+		t.Errorf("Expected the renamed category, got %s", product.Category)
+	}
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryInUse    = errors.New("category has subcategories or products")
+	ErrDuplicateSlug    = errors.New("category slug already exists below this parent")
+	ErrAmbiguousSlug    = errors.New("category slug is used by several categories")
+)
+
+type ICategoryRepository interface {
+	GetAllCategories() ([]*model.Category, error)
+	GetCategoryById(categoryId int) (*model.Category, error)
+	// GetCategoryBySlug returns ErrAmbiguousSlug when categories below
+	// different parents share the slug, since slugs are only unique per parent
+	GetCategoryBySlug(slug string) (*model.Category, error)
+	// GetDescendantIds returns the ids of all categories below the given one
+	GetDescendantIds(categoryId int) ([]int, error)
+	CreateCategory(category *model.Category) (*model.Category, error)
+	UpdateCategory(category *model.Category) (*model.Category, error)
+	DeleteCategory(categoryId int) (bool, error)
+}
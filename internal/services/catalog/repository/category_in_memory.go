@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+)
+
+// InMemoryCategoryRepository is used in tests. Product references are not
+// tracked, so DeleteCategory only refuses categories with children.
+type InMemoryCategoryRepository struct {
+	categories map[int]*model.Category
+	nextId     int
+	mu         sync.RWMutex
+}
+
+func NewInMemoryCategoryRepository() *InMemoryCategoryRepository {
+	return &InMemoryCategoryRepository{
+		categories: make(map[int]*model.Category),
+		nextId:     1,
+	}
+}
+
+func (repo *InMemoryCategoryRepository) GetAllCategories() ([]*model.Category, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	categories := make([]*model.Category, 0, len(repo.categories))
+	for _, category := range repo.categories {
+		categoryCopy := *category
+		categories = append(categories, &categoryCopy)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return categories, nil
+}
+
+func (repo *InMemoryCategoryRepository) GetCategoryById(categoryId int) (*model.Category, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	category, ok := repo.categories[categoryId]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	categoryCopy := *category
+	return &categoryCopy, nil
+}
+
+func (repo *InMemoryCategoryRepository) GetCategoryBySlug(slug string) (*model.Category, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var found *model.Category
+	for _, category := range repo.categories {
+		if category.Slug != slug {
+			continue
+		}
+		if found != nil {
+			return nil, ErrAmbiguousSlug
+		}
+		found = category
+	}
+	if found == nil {
+		return nil, ErrCategoryNotFound
+	}
+	categoryCopy := *found
+	return &categoryCopy, nil
+}
+
+func (repo *InMemoryCategoryRepository) GetDescendantIds(categoryId int) ([]int, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var ids []int
+	queue := []int{categoryId}
+	for len(queue) > 0 {
+		parentId := queue[0]
+		queue = queue[1:]
+		for _, category := range repo.categories {
+			if category.ParentId != nil && *category.ParentId == parentId {
+				ids = append(ids, category.CategoryId)
+				queue = append(queue, category.CategoryId)
+			}
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (repo *InMemoryCategoryRepository) CreateCategory(category *model.Category) (*model.Category, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if repo.slugTaken(category, 0) {
+		return nil, ErrDuplicateSlug
+	}
+
+	category.CategoryId = repo.nextId
+	repo.nextId++
+	categoryCopy := *category
+	repo.categories[category.CategoryId] = &categoryCopy
+	return category, nil
+}
+
+func (repo *InMemoryCategoryRepository) UpdateCategory(category *model.Category) (*model.Category, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.categories[category.CategoryId]; !ok {
+		return nil, ErrCategoryNotFound
+	}
+	if repo.slugTaken(category, category.CategoryId) {
+		return nil, ErrDuplicateSlug
+	}
+
+	categoryCopy := *category
+	repo.categories[category.CategoryId] = &categoryCopy
+	return category, nil
+}
+
+func (repo *InMemoryCategoryRepository) DeleteCategory(categoryId int) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.categories[categoryId]; !ok {
+		return false, nil
+	}
+	for _, category := range repo.categories {
+		if category.ParentId != nil && *category.ParentId == categoryId {
+			return false, ErrCategoryInUse
+		}
+	}
+
+	delete(repo.categories, categoryId)
+	return true, nil
+}
+
+// slugTaken reports whether a sibling of category other than exceptId
+// already uses its slug.
+func (repo *InMemoryCategoryRepository) slugTaken(category *model.Category, exceptId int) bool {
+	for _, other := range repo.categories {
+		if other.Slug == category.Slug && other.CategoryId != exceptId && sameParent(other.ParentId, category.ParentId) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
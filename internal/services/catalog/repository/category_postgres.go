@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type PostgresCategoryRepository struct {
+	gormDb *postgres.GormDatabase
+}
+
+func NewPostgresCategoryRepository(logger logger.Logger) *PostgresCategoryRepository {
+	gormDb, err := postgres.NewGormDatabase(
+		logger,
+		&gorm.Config{
+			NamingStrategy: schema.NamingStrategy{
+				TablePrefix:   "products.t_",
+				SingularTable: true,
+			},
+			TranslateError: true,
+		},
+	)
+
+	if err != nil {
+		return nil
+	}
+
+	return &PostgresCategoryRepository{
+		gormDb: gormDb,
+	}
+}
+
+func (repo *PostgresCategoryRepository) GetAllCategories() ([]*model.Category, error) {
+	var categories []*model.Category
+	if err := repo.gormDb.Order("name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (repo *PostgresCategoryRepository) GetCategoryById(categoryId int) (*model.Category, error) {
+	return repo.first("id = ?", categoryId)
+}
+
+func (repo *PostgresCategoryRepository) GetCategoryBySlug(slug string) (*model.Category, error) {
+	var categories []*model.Category
+	if err := repo.gormDb.Where("slug = ?", slug).Limit(2).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	switch len(categories) {
+	case 0:
+		return nil, ErrCategoryNotFound
+	case 1:
+		return categories[0], nil
+	default:
+		return nil, ErrAmbiguousSlug
+	}
+}
+
+func (repo *PostgresCategoryRepository) GetDescendantIds(categoryId int) ([]int, error) {
+	var ids []int
+	err := repo.gormDb.Raw(`
+		WITH RECURSIVE descendants AS (
+			SELECT id FROM products.t_category WHERE parent_id = ?
+			UNION
+			SELECT c.id FROM products.t_category c JOIN descendants d ON c.parent_id = d.id
+		)
+		SELECT id FROM descendants`, categoryId).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (repo *PostgresCategoryRepository) CreateCategory(category *model.Category) (*model.Category, error) {
+	err := repo.gormDb.Create(category).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrDuplicateSlug
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory renames or moves a category. The denormalised category name
+// on its products is updated by IProductRepository.RenameCategory, so that
+// the product cache sees the change.
+func (repo *PostgresCategoryRepository) UpdateCategory(category *model.Category) (*model.Category, error) {
+	result := repo.gormDb.Model(&model.Category{}).
+		Where("id = ?", category.CategoryId).
+		Select("parent_id", "name", "slug", "updated_at").
+		Updates(category)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return nil, ErrDuplicateSlug
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (repo *PostgresCategoryRepository) DeleteCategory(categoryId int) (bool, error) {
+	var references int64
+	err := repo.gormDb.Raw(`
+		SELECT (SELECT COUNT(*) FROM products.t_category WHERE parent_id = ?)
+		     + (SELECT COUNT(*) FROM products.t_product WHERE category_id = ?)`,
+		categoryId, categoryId).Scan(&references).Error
+	if err != nil {
+		return false, err
+	}
+	if references > 0 {
+		return false, ErrCategoryInUse
+	}
+
+	result := repo.gormDb.Delete(&model.Category{}, "id = ?", categoryId)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (repo *PostgresCategoryRepository) first(query string, args ...any) (*model.Category, error) {
+	var category model.Category
+	result := repo.gormDb.Where(query, args...).First(&category)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrCategoryNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &category, nil
+}
@@ -2,24 +2,38 @@ package repository
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
 
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"gorm.io/gorm"
 )
 
 type MockProductRepository struct {
-	data map[string]*model.Product
+	data           map[string]*model.Product
+	nextMediaId    int
+	priceHistory   map[string][]*model.PriceHistory
+	priceSchedules map[string][]*model.PriceSchedule
+	nextScheduleId int
 }
 
 func NewMockProductRepository() *MockProductRepository {
 	return &MockProductRepository{
-		data: make(map[string]*model.Product),
+		data:           make(map[string]*model.Product),
+		priceHistory:   make(map[string][]*model.PriceHistory),
+		priceSchedules: make(map[string][]*model.PriceSchedule),
 	}
 }
 
 func (repo *MockProductRepository) GetAllProducts() ([]*model.Product, error) {
 	var productList []*model.Product
 	for _, product := range repo.data {
-		productList = append(productList, product)
+		if !product.DeletedAt.Valid {
+			productList = append(productList, product)
+		}
 	}
 	return productList, nil
 }
@@ -27,7 +41,7 @@ func (repo *MockProductRepository) GetAllProducts() ([]*model.Product, error) {
 func (repo *MockProductRepository) GetProductsByCategory(category string) ([]*model.Product, error) {
 	var productList []*model.Product
 	for _, product := range repo.data {
-		if product.Category == category {
+		if product.Category == category && !product.DeletedAt.Valid {
 			productList = append(productList, product)
 		}
 	}
@@ -37,34 +51,403 @@ func (repo *MockProductRepository) GetProductsByCategory(category string) ([]*mo
 	return productList, nil
 }
 
-func (repo *MockProductRepository) CreateProduct(product *model.Product) error {
-	if _, exists := repo.data[product.ProductId]; exists {
-		return errors.New("product already exists")
+func (repo *MockProductRepository) GetProductsByFilter(filter ProductFilter) ([]*model.Product, error) {
+	var productList []*model.Product
+	for _, product := range repo.data {
+		if product.DeletedAt.Valid && !filter.IncludeDeleted {
+			continue
+		}
+		if filter.Category != "" && product.Category != filter.Category {
+			continue
+		}
+		if len(filter.CategoryIds) > 0 && (product.CategoryId == nil || !slices.Contains(filter.CategoryIds, *product.CategoryId)) {
+			continue
+		}
+		if matchesAttributes(product, filter.Attributes) {
+			productList = append(productList, product)
+		}
 	}
-	repo.data[product.ProductId] = product
-	return nil
+	return productList, nil
+}
+
+func (repo *MockProductRepository) CreateProduct(product *model.Product) (*model.Product, error) {
+	if _, exists := repo.data[product.ProductCode]; exists {
+		return nil, errors.New("product already exists")
+	}
+	repo.data[product.ProductCode] = product
+	return product, nil
 }
 
+// GetProductByCode returns a copy of the product, as the Postgres repository
+// returns a fresh row, so that later updates do not change it.
 func (repo *MockProductRepository) GetProductByCode(productCode string) (*model.Product, error) {
+	product, exists := repo.activeProduct(productCode)
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+	productCopy := *product
+	return &productCopy, nil
+}
+
+func (repo *MockProductRepository) UpdateProduct(product *model.Product, version *time.Time) (*model.Product, error) {
+	existing, exists := repo.activeProduct(product.ProductCode)
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+	if version != nil && !existing.UpdatedAt.Equal(*version) {
+		return nil, ErrProductModified
+	}
+	if product.Price != existing.Price {
+		repo.priceHistory[product.ProductCode] = append(repo.priceHistory[product.ProductCode], &model.PriceHistory{
+			ProductId: existing.ProductId,
+			OldPrice:  existing.Price,
+			NewPrice:  product.Price,
+			Source:    enums.PriceChangeSourceManual,
+			ChangedAt: time.Now(),
+		})
+	}
+	existing.Name = product.Name
+	existing.Category = product.Category
+	existing.CategoryId = product.CategoryId
+	existing.Description = product.Description
+	existing.Price = product.Price
+	existing.Stock = product.Stock
+	existing.MaxOrderQty = product.MaxOrderQty
+	existing.Attributes = product.Attributes
+	existing.UpdatedAt = time.Now()
+	return existing, nil
+}
+
+func (repo *MockProductRepository) DeleteProduct(productCode string) (bool, error) {
+	product, exists := repo.activeProduct(productCode)
+	if !exists {
+		return false, nil
+	}
+	product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return true, nil
+}
+
+func (repo *MockProductRepository) RestoreProduct(productCode string) (bool, error) {
 	product, exists := repo.data[productCode]
+	if !exists || !product.DeletedAt.Valid {
+		return false, nil
+	}
+	product.DeletedAt = gorm.DeletedAt{}
+	product.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (repo *MockProductRepository) PurgeDeletedProducts(deletedBefore time.Time) ([]*model.Product, error) {
+	var purged []*model.Product
+	for code, product := range repo.data {
+		if product.DeletedAt.Valid && product.DeletedAt.Time.Before(deletedBefore) {
+			purged = append(purged, product)
+			delete(repo.data, code)
+			delete(repo.priceHistory, code)
+			delete(repo.priceSchedules, code)
+		}
+	}
+	return purged, nil
+}
+
+func (repo *MockProductRepository) GetVariantBySKU(sku string) (*model.ProductVariant, error) {
+	for _, product := range repo.data {
+		if product.DeletedAt.Valid {
+			continue
+		}
+		for _, variant := range product.Variants {
+			if variant.SKU == sku {
+				variant.Product = product
+				return variant, nil
+			}
+		}
+	}
+	return nil, ErrVariantNotFound
+}
+
+func (repo *MockProductRepository) CreateVariant(productCode string, variant *model.ProductVariant) (*model.ProductVariant, error) {
+	product, exists := repo.activeProduct(productCode)
 	if !exists {
-		return nil, errors.New("product not found")
+		return nil, ErrProductNotFound
 	}
-	return product, nil
+	if _, err := repo.GetVariantBySKU(variant.SKU); err == nil {
+		return nil, errors.New("variant already exists")
+	}
+	variant.ProductId = product.ProductId
+	product.Variants = append(product.Variants, variant)
+	return variant, nil
+}
+
+func (repo *MockProductRepository) UpdateVariant(variant *model.ProductVariant) (*model.ProductVariant, error) {
+	existing, err := repo.GetVariantBySKU(variant.SKU)
+	if err != nil {
+		return nil, err
+	}
+	existing.Price = variant.Price
+	existing.Stock = variant.Stock
+	existing.Attributes = variant.Attributes
+	return existing, nil
 }
 
-func (repo *MockProductRepository) UpdateProduct(product *model.Product) error {
-	if _, exists := repo.data[product.ProductId]; !exists {
-		return errors.New("product not found")
+func (repo *MockProductRepository) DeleteVariant(sku string) (bool, error) {
+	for _, product := range repo.data {
+		for i, variant := range product.Variants {
+			if variant.SKU == sku {
+				product.Variants = append(product.Variants[:i], product.Variants[i+1:]...)
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (repo *MockProductRepository) AddMedia(productCode string, media *model.ProductMedia) (*model.ProductMedia, error) {
+	product, exists := repo.activeProduct(productCode)
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+	repo.nextMediaId++
+	media.MediaId = repo.nextMediaId
+	media.ProductId = product.ProductId
+	media.Position = len(product.Media)
+	product.Media = append(product.Media, media)
+	product.UpdatedAt = time.Now()
+	return media, nil
+}
+
+func (repo *MockProductRepository) DeleteMedia(productCode string, mediaId int) (bool, error) {
+	product, exists := repo.activeProduct(productCode)
+	if !exists {
+		return false, ErrProductNotFound
+	}
+	i := slices.IndexFunc(product.Media, func(media *model.ProductMedia) bool { return media.MediaId == mediaId })
+	if i < 0 {
+		return false, nil
+	}
+	product.Media = slices.Delete(product.Media, i, i+1)
+	for position, media := range product.Media {
+		media.Position = position
+	}
+	product.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (repo *MockProductRepository) ReorderMedia(productCode string, mediaIds []int) error {
+	product, exists := repo.activeProduct(productCode)
+	if !exists {
+		return ErrProductNotFound
+	}
+	if len(mediaIds) != len(product.Media) {
+		return ErrMediaNotFound
+	}
+	reordered := make([]*model.ProductMedia, len(mediaIds))
+	for position, mediaId := range mediaIds {
+		i := slices.IndexFunc(product.Media, func(media *model.ProductMedia) bool { return media.MediaId == mediaId })
+		if i < 0 {
+			return ErrMediaNotFound
+		}
+		reordered[position] = product.Media[i]
 	}
-	repo.data[product.ProductId] = product
+	for position, media := range reordered {
+		media.Position = position
+	}
+	product.Media = reordered
+	product.UpdatedAt = time.Now()
 	return nil
 }
 
-func (repo *MockProductRepository) DeleteProduct(productCode string) error {
-	if _, exists := repo.data[productCode]; !exists {
-		return errors.New("product not found")
+func (repo *MockProductRepository) GetPriceHistory(productCode string, limit int) ([]*model.PriceHistory, error) {
+	if _, exists := repo.activeProduct(productCode); !exists {
+		return nil, ErrProductNotFound
+	}
+	if limit <= 0 {
+		limit = DefaultPriceHistoryLimit
+	}
+
+	history := slices.Clone(repo.priceHistory[productCode])
+	slices.Reverse(history)
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	return history, nil
+}
+
+func (repo *MockProductRepository) GetPriceSchedules(productCode string) ([]*model.PriceSchedule, error) {
+	if _, exists := repo.activeProduct(productCode); !exists {
+		return nil, ErrProductNotFound
+	}
+
+	schedules := slices.Clone(repo.priceSchedules[productCode])
+	sort.SliceStable(schedules, func(i, j int) bool { return schedules[i].EffectiveAt.Before(schedules[j].EffectiveAt) })
+	return schedules, nil
+}
+
+func (repo *MockProductRepository) CreatePriceSchedule(productCode string, schedule *model.PriceSchedule, revert *model.PriceSchedule) ([]*model.PriceSchedule, error) {
+	product, exists := repo.activeProduct(productCode)
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+
+	schedules := []*model.PriceSchedule{schedule}
+	if revert != nil {
+		schedules = append(schedules, revert)
+	}
+	for _, created := range schedules {
+		repo.nextScheduleId++
+		created.ScheduleId = repo.nextScheduleId
+		created.ProductId = product.ProductId
+		created.Status = enums.PriceScheduleStatusPending
+		repo.priceSchedules[productCode] = append(repo.priceSchedules[productCode], created)
+	}
+	if revert != nil {
+		revert.ParentId = &schedule.ScheduleId
+	}
+	return schedules, nil
+}
+
+func (repo *MockProductRepository) CancelPriceSchedule(productCode string, scheduleId int) (bool, error) {
+	if _, exists := repo.activeProduct(productCode); !exists {
+		return false, ErrProductNotFound
+	}
+	for _, schedule := range repo.priceSchedules[productCode] {
+		if schedule.ScheduleId == scheduleId && schedule.Status == enums.PriceScheduleStatusPending {
+			schedule.Status = enums.PriceScheduleStatusCancelled
+			repo.updatePendingReverts(productCode, scheduleId, func(revert *model.PriceSchedule) {
+				revert.Status = enums.PriceScheduleStatusCancelled
+			})
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repo *MockProductRepository) updatePendingReverts(productCode string, parentId int, update func(*model.PriceSchedule)) {
+	for _, schedule := range repo.priceSchedules[productCode] {
+		if schedule.ParentId != nil && *schedule.ParentId == parentId && schedule.Status == enums.PriceScheduleStatusPending {
+			update(schedule)
+		}
+	}
+}
+
+func (repo *MockProductRepository) ApplyDuePriceSchedule(now time.Time) (*model.PriceHistory, error) {
+	productCode, schedule := repo.nextPendingSchedule()
+	if schedule == nil || schedule.EffectiveAt.After(now) {
+		return nil, nil
+	}
+
+	product := repo.data[productCode]
+	history := &model.PriceHistory{
+		ProductId:  product.ProductId,
+		OldPrice:   product.Price,
+		NewPrice:   schedule.Price,
+		Source:     enums.PriceChangeSourceSchedule,
+		ScheduleId: &schedule.ScheduleId,
+		ChangedAt:  now,
+		Product:    product,
+	}
+	repo.priceHistory[productCode] = append(repo.priceHistory[productCode], history)
+
+	repo.updatePendingReverts(productCode, schedule.ScheduleId, func(revert *model.PriceSchedule) {
+		revert.Price = product.Price
+	})
+	product.Price = schedule.Price
+	product.UpdatedAt = now
+	schedule.Status = enums.PriceScheduleStatusApplied
+	schedule.AppliedAt = &now
+	return history, nil
+}
+
+func (repo *MockProductRepository) NextPriceScheduleTime() (*time.Time, error) {
+	_, schedule := repo.nextPendingSchedule()
+	if schedule == nil {
+		return nil, nil
+	}
+	next := schedule.EffectiveAt
+	return &next, nil
+}
+
+// nextPendingSchedule finds the earliest pending schedule of a product that
+// is not soft deleted.
+func (repo *MockProductRepository) nextPendingSchedule() (string, *model.PriceSchedule) {
+	var nextCode string
+	var next *model.PriceSchedule
+	for productCode, schedules := range repo.priceSchedules {
+		if _, exists := repo.activeProduct(productCode); !exists {
+			continue
+		}
+		for _, schedule := range schedules {
+			if schedule.Status != enums.PriceScheduleStatusPending {
+				continue
+			}
+			if next == nil || schedule.EffectiveAt.Before(next.EffectiveAt) ||
+				(schedule.EffectiveAt.Equal(next.EffectiveAt) && schedule.ScheduleId < next.ScheduleId) {
+				nextCode, next = productCode, schedule
+			}
+		}
+	}
+	return nextCode, next
+}
+
+func (repo *MockProductRepository) UpsertProducts(products []*model.Product) error {
+	for _, product := range products {
+		repo.data[product.ProductCode] = product
+	}
+	return nil
+}
+
+func (repo *MockProductRepository) RenameCategory(categoryId int, name string) ([]*model.Product, error) {
+	var renamed []*model.Product
+	for _, product := range repo.data {
+		if product.DeletedAt.Valid || product.CategoryId == nil || *product.CategoryId != categoryId || product.Category == name {
+			continue
+		}
+		product.Category = name
+		product.UpdatedAt = time.Now()
+		renamed = append(renamed, product)
+	}
+	return renamed, nil
+}
+
+func (repo *MockProductRepository) StreamProducts(fn func(*model.Product) error) error {
+	codes := make([]string, 0, len(repo.data))
+	for code, product := range repo.data {
+		if !product.DeletedAt.Valid {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if err := fn(repo.data[code]); err != nil {
+			return err
+		}
 	}
-	delete(repo.data, productCode)
 	return nil
 }
+
+// activeProduct looks up a product that has not been soft deleted.
+func (repo *MockProductRepository) activeProduct(productCode string) (*model.Product, bool) {
+	product, exists := repo.data[productCode]
+	if !exists || product.DeletedAt.Valid {
+		return nil, false
+	}
+	return product, true
+}
+
+// matchesAttributes compares attribute values as text, like the Postgres
+// repository does.
+func matchesAttributes(product *model.Product, filters map[string]string) bool {
+	for key, value := range filters {
+		if !attributeEquals(product.Attributes, key, value) && !slices.ContainsFunc(product.Variants, func(variant *model.ProductVariant) bool {
+			return attributeEquals(variant.Attributes, key, value)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func attributeEquals(attributes map[string]any, key string, value string) bool {
+	attribute, ok := attributes[key]
+	return ok && fmt.Sprint(attribute) == value
+}
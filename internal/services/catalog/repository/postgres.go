@@ -1,10 +1,17 @@
 package repository
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
 	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
 )
 
@@ -34,7 +41,7 @@ func NewPostgresProductRepository(logger logger.Logger) *PostgresProductReposito
 
 func (repo *PostgresProductRepository) GetAllProducts() ([]*model.Product, error) {
 	var products []*model.Product
-	result := repo.gormDb.Find(&products)
+	result := repo.gormDb.Preload("Variants").Find(&products)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -43,7 +50,7 @@ func (repo *PostgresProductRepository) GetAllProducts() ([]*model.Product, error
 
 func (repo *PostgresProductRepository) GetProductsByCategory(category string) ([]*model.Product, error) {
 	var products []*model.Product
-	result := repo.gormDb.Where("category = ?", category).Find(&products)
+	result := repo.gormDb.Preload("Variants").Where("category = ?", category).Find(&products)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -53,7 +60,10 @@ func (repo *PostgresProductRepository) GetProductsByCategory(category string) ([
 
 func (repo *PostgresProductRepository) GetProductByCode(productCode string) (*model.Product, error) {
 	var product *model.Product
-	result := repo.gormDb.Where("product_code = ?", productCode).First(&product)
+	result := repo.gormDb.Preload("Variants").Preload("Media", orderByPosition).Where("product_code = ?", productCode).First(&product)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	}
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -69,15 +79,122 @@ func (repo *PostgresProductRepository) CreateProduct(product *model.Product) (*m
 	return product, nil
 }
 
-func (repo *PostgresProductRepository) UpdateProduct(product *model.Product) (*model.Product, error) {
-	err := repo.gormDb.Model(&model.Product{}).Where("product_code = ?", product.ProductCode).Updates(product).Error
+// GetProductsByFilter matches attribute filters against the product's own
+// attributes or those of any of its variants. Values are compared as text so
+// that numbers and booleans can be filtered from query strings.
+func (repo *PostgresProductRepository) GetProductsByFilter(filter ProductFilter) ([]*model.Product, error) {
+	query := repo.gormDb.Preload("Variants")
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if len(filter.CategoryIds) > 0 {
+		query = query.Where("category_id IN ?", filter.CategoryIds)
+	}
+	// Containment rather than ->> lets the GIN indexes on attributes serve
+	// the filter
+	for key, value := range filter.Attributes {
+		documents := attributeDocuments(key, value)
+		query = query.Where(
+			"("+containsAny("attributes", len(documents))+" OR EXISTS (SELECT 1 FROM products.t_product_variant v WHERE v.product_id = products.t_product.id AND "+containsAny("v.attributes", len(documents))+"))",
+			append(documents, documents...)...,
+		)
+	}
+
+	var products []*model.Product
+	if err := query.Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// attributeDocuments returns the JSON objects an attribute filter matches. A
+// filter value is text, so it matches a string attribute, and also the
+// number or boolean it spells, as the text comparison of the in-memory
+// repository does.
+func attributeDocuments(key string, value string) []any {
+	text, _ := json.Marshal(map[string]string{key: value})
+	documents := []any{string(text)}
+
+	var typed any
+	if err := json.Unmarshal([]byte(value), &typed); err == nil {
+		switch typed.(type) {
+		case float64, bool:
+			document, _ := json.Marshal(map[string]json.RawMessage{key: json.RawMessage(value)})
+			documents = append(documents, string(document))
+		}
+	}
+	return documents
+}
+
+// containsAny matches column against any of n JSON documents.
+func containsAny(column string, n int) string {
+	conditions := make([]string, n)
+	for i := range conditions {
+		conditions[i] = column + " @> ?::jsonb"
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// UpdateProduct writes every editable column of product, including zero
+// values: an empty description, a zero price or stock, a nil category_id and
+// nil attributes (stored as {}) replace the current values rather than being
+// skipped, so a patch that sets a field to null or zero clears it. A price
+// change is recorded in the price history in the same transaction. The
+// version is checked by the UPDATE itself, so a concurrent write between the
+// client's read and this one fails with ErrProductModified.
+func (repo *PostgresProductRepository) UpdateProduct(product *model.Product, version *time.Time) (*model.Product, error) {
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		var current model.Product
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "price").
+			Where("product_code = ?", product.ProductCode).
+			First(&current)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		query := tx.Model(&model.Product{}).Where("id = ?", current.ProductId)
+		if version != nil {
+			query = query.Where("updated_at = ?", *version)
+		}
+
+		// Select every editable column so that zero values are written too
+		result = query.
+			Select("name", "category", "category_id", "description", "price", "stock", "max_order_quantity", "attributes", "updated_at").
+			Updates(product)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrProductModified
+		}
+
+		if product.Price == current.Price {
+			return nil
+		}
+		return tx.Omit("Product").Create(&model.PriceHistory{
+			ProductId: current.ProductId,
+			OldPrice:  current.Price,
+			NewPrice:  product.Price,
+			Source:    enums.PriceChangeSourceManual,
+			ChangedAt: time.Now(),
+		}).Error
+	})
 	if err != nil {
 		return product, err
 	}
 
-	return product, err
+	return product, nil
 }
 
+// DeleteProduct soft deletes a product by setting deleted_at. The row is kept
+// until PurgeDeletedProducts removes it.
 func (repo *PostgresProductRepository) DeleteProduct(productCode string) (bool, error) {
 	result := repo.gormDb.Delete(&model.Product{}, "product_code = ?", productCode)
 	if result.Error != nil {
@@ -85,3 +202,460 @@ func (repo *PostgresProductRepository) DeleteProduct(productCode string) (bool,
 	}
 	return result.RowsAffected > 0, nil
 }
+
+// RestoreProduct clears deleted_at. It reports false when no soft-deleted
+// product has the code.
+func (repo *PostgresProductRepository) RestoreProduct(productCode string) (bool, error) {
+	result := repo.gormDb.Unscoped().Model(&model.Product{}).
+		Where("product_code = ? AND deleted_at IS NOT NULL", productCode).
+		Updates(map[string]any{"deleted_at": nil, "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PurgeDeletedProducts permanently removes products soft deleted before the
+// given time. Their variants and media rows are removed by cascade; the
+// purged products are returned with their media so stored files can be
+// cleaned up.
+func (repo *PostgresProductRepository) PurgeDeletedProducts(deletedBefore time.Time) ([]*model.Product, error) {
+	var products []*model.Product
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Preload("Media").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at < ?", deletedBefore).
+			Find(&products).Error
+		if err != nil || len(products) == 0 {
+			return err
+		}
+		return tx.Unscoped().Delete(&products).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (repo *PostgresProductRepository) GetVariantBySKU(sku string) (*model.ProductVariant, error) {
+	var variant *model.ProductVariant
+	result := repo.gormDb.Preload("Product").Where("sku = ?", sku).First(&variant)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrVariantNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	// The preload skips soft-deleted products, whose variants are not for sale
+	if variant.Product == nil {
+		return nil, ErrVariantNotFound
+	}
+
+	return variant, nil
+}
+
+func (repo *PostgresProductRepository) CreateVariant(productCode string, variant *model.ProductVariant) (*model.ProductVariant, error) {
+	var product model.Product
+	result := repo.gormDb.Select("id").Where("product_code = ?", productCode).First(&product)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	variant.ProductId = product.ProductId
+	if err := repo.gormDb.Omit("Product").Create(variant).Error; err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// UpdateVariant replaces the price override, stock and attributes of the
+// variant with the given SKU.
+func (repo *PostgresProductRepository) UpdateVariant(variant *model.ProductVariant) (*model.ProductVariant, error) {
+	result := repo.gormDb.Model(&model.ProductVariant{}).
+		Where("sku = ?", variant.SKU).
+		Select("price", "stock", "attributes", "updated_at").
+		Updates(variant)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrVariantNotFound
+	}
+
+	return variant, nil
+}
+
+func (repo *PostgresProductRepository) DeleteVariant(sku string) (bool, error) {
+	result := repo.gormDb.Delete(&model.ProductVariant{}, "sku = ?", sku)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// AddMedia appends media to the end of the product's gallery. The product row
+// is locked so that concurrent uploads get distinct positions.
+func (repo *PostgresProductRepository) AddMedia(productCode string, media *model.ProductMedia) (*model.ProductMedia, error) {
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		productId, err := lockProduct(tx, productCode)
+		if err != nil {
+			return err
+		}
+
+		var position int
+		err = tx.Model(&model.ProductMedia{}).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Where("product_id = ?", productId).
+			Scan(&position).Error
+		if err != nil {
+			return err
+		}
+
+		media.ProductId = productId
+		media.Position = position
+		if err := tx.Create(media).Error; err != nil {
+			return err
+		}
+		return touchProduct(tx, productId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// DeleteMedia removes media from the gallery and closes the gap it leaves.
+func (repo *PostgresProductRepository) DeleteMedia(productCode string, mediaId int) (bool, error) {
+	deleted := false
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		productId, err := lockProduct(tx, productCode)
+		if err != nil {
+			return err
+		}
+
+		var media model.ProductMedia
+		result := tx.Where("id = ? AND product_id = ?", mediaId, productId).First(&media)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Delete(&media).Error; err != nil {
+			return err
+		}
+		err = tx.Model(&model.ProductMedia{}).
+			Where("product_id = ? AND position > ?", productId, media.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+		if err != nil {
+			return err
+		}
+
+		deleted = true
+		return touchProduct(tx, productId)
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// ReorderMedia assigns positions in the order of mediaIds, which must list
+// every media item of the product exactly once.
+func (repo *PostgresProductRepository) ReorderMedia(productCode string, mediaIds []int) error {
+	return repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		productId, err := lockProduct(tx, productCode)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.ProductMedia{}).Where("product_id = ?", productId).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(mediaIds)) {
+			return ErrMediaNotFound
+		}
+
+		for position, mediaId := range mediaIds {
+			result := tx.Model(&model.ProductMedia{}).
+				Where("id = ? AND product_id = ?", mediaId, productId).
+				Update("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrMediaNotFound
+			}
+		}
+
+		return touchProduct(tx, productId)
+	})
+}
+
+// GetPriceHistory returns the most recent price changes of a product, newest
+// first.
+func (repo *PostgresProductRepository) GetPriceHistory(productCode string, limit int) ([]*model.PriceHistory, error) {
+	productId, err := findProductId(repo.gormDb.GetDB(), productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultPriceHistoryLimit
+	}
+
+	var history []*model.PriceHistory
+	err = repo.gormDb.Where("product_id = ?", productId).Order("changed_at DESC, id DESC").Limit(limit).Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetPriceSchedules returns all schedules of a product ordered by when they
+// take effect.
+func (repo *PostgresProductRepository) GetPriceSchedules(productCode string) ([]*model.PriceSchedule, error) {
+	productId, err := findProductId(repo.gormDb.GetDB(), productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedules []*model.PriceSchedule
+	err = repo.gormDb.Where("product_id = ?", productId).Order("effective_at, id").Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// CreatePriceSchedule stores a schedule and its revert in one transaction, so
+// that the start and end of a sale are created together.
+func (repo *PostgresProductRepository) CreatePriceSchedule(productCode string, schedule *model.PriceSchedule, revert *model.PriceSchedule) ([]*model.PriceSchedule, error) {
+	schedules := []*model.PriceSchedule{schedule}
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		productId, err := findProductId(tx, productCode)
+		if err != nil {
+			return err
+		}
+
+		schedule.ProductId = productId
+		schedule.Status = enums.PriceScheduleStatusPending
+		if err := tx.Create(schedule).Error; err != nil {
+			return err
+		}
+		if revert == nil {
+			return nil
+		}
+
+		revert.ProductId = productId
+		revert.ParentId = &schedule.ScheduleId
+		revert.Status = enums.PriceScheduleStatusPending
+		schedules = append(schedules, revert)
+		return tx.Create(revert).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// CancelPriceSchedule cancels a pending schedule together with the pending
+// schedule that reverts it. Applied and cancelled schedules are left as they
+// are and reported as not found.
+func (repo *PostgresProductRepository) CancelPriceSchedule(productCode string, scheduleId int) (bool, error) {
+	var cancelled bool
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		productId, err := findProductId(tx, productCode)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.PriceSchedule{}).
+			Where("id = ? AND product_id = ? AND status = ?", scheduleId, productId, enums.PriceScheduleStatusPending).
+			Update("status", enums.PriceScheduleStatusCancelled)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		cancelled = true
+
+		return tx.Model(&model.PriceSchedule{}).
+			Where("parent_id = ? AND status = ?", scheduleId, enums.PriceScheduleStatusPending).
+			Update("status", enums.PriceScheduleStatusCancelled).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return cancelled, nil
+}
+
+// ApplyDuePriceSchedule claims the earliest pending schedule due at now,
+// applies its price and records the change. The schedule that reverts it
+// takes the price it replaced. Rows claimed by another catalog instance are
+// skipped. It returns nil when nothing is due. Schedules of soft-deleted
+// products stay pending until the product is restored.
+func (repo *PostgresProductRepository) ApplyDuePriceSchedule(now time.Time) (*model.PriceHistory, error) {
+	var history *model.PriceHistory
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		var schedule model.PriceSchedule
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND effective_at <= ?", enums.PriceScheduleStatusPending, now).
+			Where("product_id IN (SELECT id FROM products.t_product WHERE deleted_at IS NULL)").
+			Order("effective_at, id").
+			Limit(1).
+			Find(&schedule)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		var product model.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", schedule.ProductId).First(&product).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.Product{}).Where("id = ?", product.ProductId).
+			Updates(map[string]any{"price": schedule.Price, "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&schedule).Updates(map[string]any{"status": enums.PriceScheduleStatusApplied, "applied_at": now}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.PriceSchedule{}).
+			Where("parent_id = ? AND status = ?", schedule.ScheduleId, enums.PriceScheduleStatusPending).
+			Update("price", product.Price).Error
+		if err != nil {
+			return err
+		}
+
+		history = &model.PriceHistory{
+			ProductId:  product.ProductId,
+			OldPrice:   product.Price,
+			NewPrice:   schedule.Price,
+			Source:     enums.PriceChangeSourceSchedule,
+			ScheduleId: &schedule.ScheduleId,
+			ChangedAt:  now,
+		}
+		if err := tx.Omit("Product").Create(history).Error; err != nil {
+			return err
+		}
+
+		product.Price = schedule.Price
+		history.Product = &product
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// NextPriceScheduleTime returns when the earliest pending schedule takes
+// effect, or nil when nothing is scheduled.
+func (repo *PostgresProductRepository) NextPriceScheduleTime() (*time.Time, error) {
+	var next *time.Time
+	err := repo.gormDb.Model(&model.PriceSchedule{}).
+		Select("MIN(effective_at)").
+		Where("status = ?", enums.PriceScheduleStatusPending).
+		Where("product_id IN (SELECT id FROM products.t_product WHERE deleted_at IS NULL)").
+		Scan(&next).Error
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// UpsertProducts inserts or updates products by product code in a single
+// transaction, so a batch is either fully applied or not at all. Variants are
+// not part of the import and are left untouched; the import rejects rows that
+// carry them. Importing a soft-deleted product restores it.
+func (repo *PostgresProductRepository) UpsertProducts(products []*model.Product) error {
+	return repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		return tx.Omit("Variants", "Media").Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "product_code"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"name", "category", "category_id", "description", "price", "stock", "max_order_quantity", "attributes", "updated_at", "deleted_at",
+			}),
+		}).Create(&products).Error
+	})
+}
+
+func (repo *PostgresProductRepository) RenameCategory(categoryId int, name string) ([]*model.Product, error) {
+	var products []*model.Product
+	err := repo.gormDb.Model(&products).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "product_code"}, {Name: "price"}}}).
+		Where("category_id = ? AND category IS DISTINCT FROM ?", categoryId, name).
+		Updates(map[string]any{"category": name, "updated_at": time.Now()}).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// StreamProducts calls fn for every product ordered by product code, reading
+// rows one at a time instead of loading the catalog into memory.
+func (repo *PostgresProductRepository) StreamProducts(fn func(*model.Product) error) error {
+	rows, err := repo.gormDb.Model(&model.Product{}).Order("product_code").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product model.Product
+		if err := repo.gormDb.ScanRows(rows, &product); err != nil {
+			return err
+		}
+		if err := fn(&product); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// findProductId resolves the code of a product that is not soft deleted to
+// its id.
+func findProductId(tx *gorm.DB, productCode string) (int, error) {
+	var product model.Product
+	result := tx.Select("id").Where("product_code = ?", productCode).First(&product)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return 0, ErrProductNotFound
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return product.ProductId, nil
+}
+
+// lockProduct resolves a product code to its id and locks the product row
+// for the rest of the transaction.
+func lockProduct(tx *gorm.DB, productCode string) (int, error) {
+	var product model.Product
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("product_code = ?", productCode).First(&product)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return 0, ErrProductNotFound
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return product.ProductId, nil
+}
+
+// touchProduct bumps the product's update time after a change to data that is
+// part of its representation, so that conditional reads see the change.
+func touchProduct(tx *gorm.DB, productId int) error {
+	return tx.Model(&model.Product{}).Where("id = ?", productId).Update("updated_at", time.Now()).Error
+}
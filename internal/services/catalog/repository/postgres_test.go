@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+)
+
+func TestAttributeDocuments(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected []any
+	}{
+		{"M", []any{`{"size":"M"}`}},
+		{"350", []any{`{"size":"350"}`, `{"size":350}`}},
+		{"true", []any{`{"size":"true"}`, `{"size":true}`}},
+		{"null", []any{`{"size":"null"}`}},
+		{`"M"`, []any{`{"size":"\"M\""}`}},
+	}
+
+	for _, c := range cases {
+		if got := attributeDocuments("size", c.value); !slices.Equal(got, c.expected) {
+			t.Errorf("Expected %s to match %v, got %v", c.value, c.expected, got)
+		}
+	}
+}
+
+// newTestPostgresRepository connects to the database configured by the DB_*
+// variables, initialised from database/init, and skips the test without one.
+func newTestPostgresRepository(t *testing.T) *PostgresProductRepository {
+	t.Helper()
+	if os.Getenv("DB_HOST") == "" {
+		t.Skip("DB_HOST is not set, skipping Postgres test")
+	}
+
+	repo := NewPostgresProductRepository(logger.NewLogger())
+	if repo == nil {
+		t.Fatal("Failed to connect to Postgres")
+	}
+	return repo
+}
+
+func TestPostgresCreateAndUpsertProducts(t *testing.T) {
+	repo := newTestPostgresRepository(t)
+
+	prefix := fmt.Sprintf("T%d-", time.Now().UnixNano()%1e9)
+	codes := []string{prefix + "1", prefix + "2", prefix + "3"}
+	t.Cleanup(func() {
+		repo.gormDb.Unscoped().Where("product_code IN ?", codes).Delete(&model.Product{})
+	})
+
+	created, err := repo.CreateProduct(&model.Product{
+		ProductCode: codes[0],
+		Name:        "Mug",
+		Category:    "Home",
+		Description: "Ceramic",
+		Price:       9.5,
+		Variants:    []*model.ProductVariant{{SKU: codes[0] + "-W", Stock: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Expected the product to be created, got %v", err)
+	}
+	if created.ProductId == 0 || created.Variants[0].ProductId != created.ProductId {
+		t.Errorf("Expected the product and its variant to be linked by the generated id, got %+v", created)
+	}
+
+	// The first row updates the product created above, the others are new
+	err = repo.UpsertProducts([]*model.Product{
+		{ProductCode: codes[0], Name: "Mug", Category: "Home", Description: "Ceramic", Price: 8},
+		{ProductCode: codes[1], Name: "Plate", Category: "Home", Description: "Ceramic", Price: 12},
+		{ProductCode: codes[2], Name: "Bowl", Category: "Home", Description: "Ceramic", Price: 7},
+	})
+	if err != nil {
+		t.Fatalf("Expected the batch to be upserted, got %v", err)
+	}
+
+	updated, err := repo.GetProductByCode(codes[0])
+	if err != nil || updated.ProductId != created.ProductId || updated.Price != 8 || len(updated.Variants) != 1 {
+		t.Errorf("Expected the existing product to be updated in place with its variant, got %+v, %v", updated, err)
+	}
+	for _, code := range codes[1:] {
+		if product, err := repo.GetProductByCode(code); err != nil || product.ProductId == 0 {
+			t.Errorf("Expected %s to be inserted, got %+v, %v", code, product, err)
+		}
+	}
+}
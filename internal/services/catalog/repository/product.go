@@ -1,14 +1,67 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
 )
 
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrVariantNotFound = errors.New("variant not found")
+	ErrMediaNotFound   = errors.New("media not found")
+	// ErrProductModified means a conditional update found the product at a
+	// different version than the one the client read
+	ErrProductModified = errors.New("product has been modified since it was last read")
+)
+
+// DefaultPriceHistoryLimit caps price history listings without a limit.
+const DefaultPriceHistoryLimit = 100
+
+// ProductFilter narrows a product listing. CategoryIds matches products
+// assigned to any of the categories. An attribute filter matches when the
+// product or any of its variants has the attribute with that value.
+// Soft-deleted products are only listed when IncludeDeleted is set.
+type ProductFilter struct {
+	Category       string
+	CategoryIds    []int
+	Attributes     map[string]string
+	IncludeDeleted bool
+}
+
 type IProductRepository interface {
 	GetAllProducts() ([]*model.Product, error)
 	GetProductsByCategory(category string) ([]*model.Product, error)
+	GetProductsByFilter(filter ProductFilter) ([]*model.Product, error)
 	GetProductByCode(productCode string) (*model.Product, error)
 	CreateProduct(*model.Product) (*model.Product, error)
-	UpdateProduct(*model.Product) (*model.Product, error)
+	// UpdateProduct writes the editable fields of a product. When version is
+	// set, only a product last updated at version is written; otherwise it
+	// fails with ErrProductModified.
+	UpdateProduct(product *model.Product, version *time.Time) (*model.Product, error)
 	DeleteProduct(productCode string) (bool, error)
+	RestoreProduct(productCode string) (bool, error)
+	PurgeDeletedProducts(deletedBefore time.Time) ([]*model.Product, error)
+	GetVariantBySKU(sku string) (*model.ProductVariant, error)
+	CreateVariant(productCode string, variant *model.ProductVariant) (*model.ProductVariant, error)
+	UpdateVariant(variant *model.ProductVariant) (*model.ProductVariant, error)
+	DeleteVariant(sku string) (bool, error)
+	AddMedia(productCode string, media *model.ProductMedia) (*model.ProductMedia, error)
+	DeleteMedia(productCode string, mediaId int) (bool, error)
+	ReorderMedia(productCode string, mediaIds []int) error
+	GetPriceHistory(productCode string, limit int) ([]*model.PriceHistory, error)
+	GetPriceSchedules(productCode string) ([]*model.PriceSchedule, error)
+	// CreatePriceSchedule stores a schedule and, when revert is not nil, a
+	// schedule that reverts it. The revert takes the price the schedule
+	// replaces once the schedule applies, and is cancelled with it.
+	CreatePriceSchedule(productCode string, schedule *model.PriceSchedule, revert *model.PriceSchedule) ([]*model.PriceSchedule, error)
+	CancelPriceSchedule(productCode string, scheduleId int) (bool, error)
+	ApplyDuePriceSchedule(now time.Time) (*model.PriceHistory, error)
+	NextPriceScheduleTime() (*time.Time, error)
+	UpsertProducts(products []*model.Product) error
+	StreamProducts(fn func(*model.Product) error) error
+	// RenameCategory updates the denormalised category name of the products
+	// in a category and returns the products it renamed.
+	RenameCategory(categoryId int, name string) ([]*model.Product, error)
 }
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+)
+
+var (
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrDuplicateReview = errors.New("user has already reviewed this product")
+)
+
+// ReviewFilter selects a page of reviews. An empty ProductCode or Status
+// matches all products or statuses; reviews of soft-deleted products are
+// never listed.
+type ReviewFilter struct {
+	ProductCode string
+	Status      enums.ReviewStatus
+	Sort        enums.ReviewSort
+	Offset      int
+	Limit       int
+}
+
+type IReviewRepository interface {
+	// GetReviews returns a page of reviews and the number of matching reviews
+	GetReviews(filter ReviewFilter) ([]*model.Review, int64, error)
+	GetReviewById(reviewId int) (*model.Review, error)
+	CreateReview(productCode string, review *model.Review) (*model.Review, error)
+	// SetReviewStatus moderates a review and refreshes the rating of its
+	// product in the same transaction
+	SetReviewStatus(reviewId int, status enums.ReviewStatus, note string) (*model.Review, error)
+	// AddVote and RemoveVote report whether the vote was added or removed,
+	// keeping the review's helpful count in step
+	AddVote(reviewId int, userId string) (bool, error)
+	RemoveVote(reviewId int, userId string) (bool, error)
+}
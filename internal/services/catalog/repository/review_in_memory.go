@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+)
+
+// InMemoryReviewRepository is used in tests. Reviews belong to the products
+// of the given MockProductRepository, whose rating aggregates it maintains.
+type InMemoryReviewRepository struct {
+	products *MockProductRepository
+	reviews  map[int]*model.Review
+	votes    map[int]map[string]bool
+	nextId   int
+	mu       sync.RWMutex
+}
+
+func NewInMemoryReviewRepository(products *MockProductRepository) *InMemoryReviewRepository {
+	return &InMemoryReviewRepository{
+		products: products,
+		reviews:  make(map[int]*model.Review),
+		votes:    make(map[int]map[string]bool),
+		nextId:   1,
+	}
+}
+
+func (repo *InMemoryReviewRepository) GetReviews(filter ReviewFilter) ([]*model.Review, int64, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var reviews []*model.Review
+	for _, review := range repo.reviews {
+		if review.Product.DeletedAt.Valid {
+			continue
+		}
+		if filter.ProductCode != "" && review.Product.ProductCode != filter.ProductCode {
+			continue
+		}
+		if filter.Status != "" && review.Status != filter.Status {
+			continue
+		}
+		reviewCopy := *review
+		reviews = append(reviews, &reviewCopy)
+	}
+
+	sort.Slice(reviews, func(i, j int) bool {
+		a, b := reviews[i], reviews[j]
+		switch {
+		case filter.Sort == enums.ReviewSortHelpful && a.HelpfulCount != b.HelpfulCount:
+			return a.HelpfulCount > b.HelpfulCount
+		case filter.Sort == enums.ReviewSortHighest && a.Rating != b.Rating:
+			return a.Rating > b.Rating
+		case filter.Sort == enums.ReviewSortLowest && a.Rating != b.Rating:
+			return a.Rating < b.Rating
+		case !a.CreatedAt.Equal(b.CreatedAt):
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ReviewId > b.ReviewId
+	})
+
+	total := int64(len(reviews))
+	start := min(filter.Offset, len(reviews))
+	end := len(reviews)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(reviews))
+	}
+	return reviews[start:end], total, nil
+}
+
+func (repo *InMemoryReviewRepository) GetReviewById(reviewId int) (*model.Review, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	review, ok := repo.reviews[reviewId]
+	if !ok || review.Product.DeletedAt.Valid {
+		return nil, ErrReviewNotFound
+	}
+	reviewCopy := *review
+	return &reviewCopy, nil
+}
+
+func (repo *InMemoryReviewRepository) CreateReview(productCode string, review *model.Review) (*model.Review, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	product, exists := repo.products.activeProduct(productCode)
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+	for _, existing := range repo.reviews {
+		if existing.Product.ProductCode == productCode && existing.UserId == review.UserId {
+			return nil, ErrDuplicateReview
+		}
+	}
+
+	now := time.Now()
+	review.ReviewId = repo.nextId
+	review.ProductId = product.ProductId
+	review.Product = product
+	review.CreatedAt = now
+	review.UpdatedAt = now
+	repo.nextId++
+
+	reviewCopy := *review
+	repo.reviews[review.ReviewId] = &reviewCopy
+	return review, nil
+}
+
+func (repo *InMemoryReviewRepository) SetReviewStatus(reviewId int, status enums.ReviewStatus, note string) (*model.Review, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	review, ok := repo.reviews[reviewId]
+	if !ok || review.Product.DeletedAt.Valid {
+		return nil, ErrReviewNotFound
+	}
+
+	now := time.Now()
+	previous := review.Status
+	review.Status = status
+	review.ModerationNote = note
+	review.ModeratedAt = &now
+	review.UpdatedAt = now
+
+	if previous == enums.ReviewStatusApproved || status == enums.ReviewStatusApproved {
+		repo.refreshRating(review.Product)
+	}
+
+	reviewCopy := *review
+	return &reviewCopy, nil
+}
+
+func (repo *InMemoryReviewRepository) AddVote(reviewId int, userId string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	review, ok := repo.reviews[reviewId]
+	if !ok {
+		return false, ErrReviewNotFound
+	}
+	if repo.votes[reviewId] == nil {
+		repo.votes[reviewId] = make(map[string]bool)
+	}
+	if repo.votes[reviewId][userId] {
+		return false, nil
+	}
+
+	repo.votes[reviewId][userId] = true
+	review.HelpfulCount++
+	return true, nil
+}
+
+func (repo *InMemoryReviewRepository) RemoveVote(reviewId int, userId string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if !repo.votes[reviewId][userId] {
+		return false, nil
+	}
+
+	delete(repo.votes[reviewId], userId)
+	repo.reviews[reviewId].HelpfulCount--
+	return true, nil
+}
+
+func (repo *InMemoryReviewRepository) refreshRating(product *model.Product) {
+	var sum, count int
+	for _, review := range repo.reviews {
+		if review.Product.ProductCode == product.ProductCode && review.Status == enums.ReviewStatusApproved {
+			sum += review.Rating
+			count++
+		}
+	}
+
+	product.RatingCount = count
+	product.RatingAverage = 0
+	if count > 0 {
+		product.RatingAverage = math.Round(float64(sum)/float64(count)*100) / 100
+	}
+	product.UpdatedAt = time.Now()
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type PostgresReviewRepository struct {
+	gormDb *postgres.GormDatabase
+}
+
+func NewPostgresReviewRepository(logger logger.Logger) *PostgresReviewRepository {
+	gormDb, err := postgres.NewGormDatabase(
+		logger,
+		&gorm.Config{
+			NamingStrategy: schema.NamingStrategy{
+				TablePrefix:   "products.t_",
+				SingularTable: true,
+			},
+			TranslateError: true,
+		},
+	)
+
+	if err != nil {
+		return nil
+	}
+
+	return &PostgresReviewRepository{
+		gormDb: gormDb,
+	}
+}
+
+func (repo *PostgresReviewRepository) GetReviews(filter ReviewFilter) ([]*model.Review, int64, error) {
+	query := repo.gormDb.Model(&model.Review{})
+	if filter.ProductCode != "" {
+		query = query.Where("product_id = (SELECT id FROM products.t_product WHERE product_code = ? AND deleted_at IS NULL)", filter.ProductCode)
+	} else {
+		query = query.Where("product_id IN (SELECT id FROM products.t_product WHERE deleted_at IS NULL)")
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []*model.Review
+	err := query.Preload("Product").
+		Order(reviewOrder(filter.Sort)).
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+func (repo *PostgresReviewRepository) GetReviewById(reviewId int) (*model.Review, error) {
+	var review model.Review
+	result := repo.gormDb.Preload("Product").First(&review, reviewId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) || (result.Error == nil && review.Product == nil) {
+		return nil, ErrReviewNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &review, nil
+}
+
+func (repo *PostgresReviewRepository) CreateReview(productCode string, review *model.Review) (*model.Review, error) {
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		productId, err := findProductId(tx, productCode)
+		if err != nil {
+			return err
+		}
+
+		review.ProductId = productId
+		return tx.Omit("Product").Create(review).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrDuplicateReview
+	}
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// SetReviewStatus locks the product before the review so that concurrent
+// moderations of the same product's reviews recompute its rating one after
+// the other.
+func (repo *PostgresReviewRepository) SetReviewStatus(reviewId int, status enums.ReviewStatus, note string) (*model.Review, error) {
+	var review model.Review
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		result := tx.Select("product_id").First(&review, reviewId)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrReviewNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var product model.Product
+		result = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", review.ProductId).First(&product)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrReviewNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, reviewId).Error; err != nil {
+			return err
+		}
+		previous := review.Status
+
+		now := time.Now()
+		review.Status = status
+		review.ModerationNote = note
+		review.ModeratedAt = &now
+		err := tx.Model(&review).
+			Select("status", "moderation_note", "moderated_at", "updated_at").
+			Updates(&review).Error
+		if err != nil {
+			return err
+		}
+
+		if previous != enums.ReviewStatusApproved && status != enums.ReviewStatusApproved {
+			return nil
+		}
+		return refreshRating(tx, review.ProductId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetReviewById(reviewId)
+}
+
+func (repo *PostgresReviewRepository) AddVote(reviewId int, userId string) (bool, error) {
+	var added bool
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		var review model.Review
+		result := tx.Select("id").First(&review, reviewId)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrReviewNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ReviewVote{ReviewId: reviewId, UserId: userId})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		added = true
+		return tx.Model(&model.Review{}).Where("id = ?", reviewId).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	})
+	return added, err
+}
+
+func (repo *PostgresReviewRepository) RemoveVote(reviewId int, userId string) (bool, error) {
+	var removed bool
+	err := repo.gormDb.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.ReviewVote{}, "review_id = ? AND user_id = ?", reviewId, userId)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		removed = true
+		return tx.Model(&model.Review{}).Where("id = ?", reviewId).
+			UpdateColumn("helpful_count", gorm.Expr("GREATEST(helpful_count - 1, 0)")).Error
+	})
+	return removed, err
+}
+
+// refreshRating recomputes the rating aggregate of a product from its
+// approved reviews.
+func refreshRating(tx *gorm.DB, productId int) error {
+	return tx.Exec(`
+		UPDATE products.t_product p
+		SET rating_count = s.count, rating_average = s.average, updated_at = ?
+		FROM (
+			SELECT COUNT(*) AS count, COALESCE(ROUND(AVG(rating), 2), 0) AS average
+			FROM products.t_review
+			WHERE product_id = ? AND status = ?
+		) s
+		WHERE p.id = ?`, time.Now(), productId, enums.ReviewStatusApproved, productId).Error
+}
+
+func reviewOrder(sort enums.ReviewSort) string {
+	switch sort {
+	case enums.ReviewSortHelpful:
+		return "helpful_count DESC, created_at DESC, id DESC"
+	case enums.ReviewSortHighest:
+		return "rating DESC, created_at DESC, id DESC"
+	case enums.ReviewSortLowest:
+		return "rating ASC, created_at DESC, id DESC"
+	default:
+		return "created_at DESC, id DESC"
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+var (
+	ErrInvalidCategory = errors.New("invalid category")
+	ErrUnknownCategory = errors.New("unknown category")
+)
+
+type ICategoryService interface {
+	GetCategoryTree() ([]*dto.CategoryResponse, error)
+	GetCategory(categoryId int) (*dto.CategoryResponse, error)
+	CreateCategory(categoryReq *dto.CategoryRequest) (*dto.CategoryResponse, error)
+	UpdateCategory(categoryId int, categoryReq *dto.CategoryRequest) (*dto.CategoryResponse, error)
+	DeleteCategory(categoryId int) (bool, error)
+}
+
+type CategoryService struct {
+	repo      repository.ICategoryRepository
+	products  repository.IProductRepository
+	publisher *messaging.Publisher
+}
+
+func NewCategoryService(repo repository.ICategoryRepository, products repository.IProductRepository, publisher *messaging.Publisher) *CategoryService {
+	return &CategoryService{
+		repo:      repo,
+		products:  products,
+		publisher: publisher,
+	}
+}
+
+// GetCategoryTree returns the top-level categories with their subcategories
+// nested below them.
+func (c *CategoryService) GetCategoryTree() ([]*dto.CategoryResponse, error) {
+	categories, err := c.repo.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	roots, _ := buildCategoryTree(categories)
+	return roots, nil
+}
+
+func (c *CategoryService) GetCategory(categoryId int) (*dto.CategoryResponse, error) {
+	categories, err := c.repo.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	_, nodes := buildCategoryTree(categories)
+	node, ok := nodes[categoryId]
+	if !ok {
+		return nil, repository.ErrCategoryNotFound
+	}
+	return node, nil
+}
+
+func (c *CategoryService) CreateCategory(categoryReq *dto.CategoryRequest) (*dto.CategoryResponse, error) {
+	category, err := c.validateCategory(0, categoryReq)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := c.repo.CreateCategory(category)
+	if err != nil {
+		return nil, err
+	}
+	return mapCategoryModelToDto(created), nil
+}
+
+// UpdateCategory renames or moves a category. A category cannot be moved
+// below itself or one of its descendants. Renaming a category renames it on
+// its products, which are announced as updated.
+func (c *CategoryService) UpdateCategory(categoryId int, categoryReq *dto.CategoryRequest) (*dto.CategoryResponse, error) {
+	existing, err := c.repo.GetCategoryById(categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := c.validateCategory(categoryId, categoryReq)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := c.repo.UpdateCategory(category)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.products != nil && updated.Name != existing.Name {
+		if err := c.renameProducts(updated); err != nil {
+			return nil, err
+		}
+	}
+	return mapCategoryModelToDto(updated), nil
+}
+
+func (c *CategoryService) renameProducts(category *model.Category) error {
+	renamed, err := c.products.RenameCategory(category.CategoryId, category.Name)
+	if err != nil {
+		return err
+	}
+
+	if c.publisher == nil {
+		return nil
+	}
+	for _, product := range renamed {
+		event := &events.ProductUpdatedEvent{
+			ProductEvent: events.ProductEvent{
+				ProductCode: product.ProductCode,
+			},
+			ChangedFields: []string{"category"},
+			OldPrice:      product.Price,
+			NewPrice:      product.Price,
+		}
+		if err := c.publisher.PublishProductUpdated(event); err != nil {
+			fmt.Printf("Failed to publish ProductUpdated event: %v\n", err)
+		}
+	}
+	return nil
+}
+
+func (c *CategoryService) DeleteCategory(categoryId int) (bool, error) {
+	return c.repo.DeleteCategory(categoryId)
+}
+
+func (c *CategoryService) validateCategory(categoryId int, categoryReq *dto.CategoryRequest) (*model.Category, error) {
+	name := strings.TrimSpace(categoryReq.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCategory)
+	}
+	if len(name) > 50 {
+		return nil, fmt.Errorf("%w: name must be at most 50 characters", ErrInvalidCategory)
+	}
+
+	slug := categoryReq.Slug
+	if slug == "" {
+		slug = name
+	}
+	slug = slugify(slug)
+	if slug == "" || len(slug) > 60 {
+		return nil, fmt.Errorf("%w: slug must contain between 1 and 60 letters or digits", ErrInvalidCategory)
+	}
+
+	if categoryReq.ParentId != nil {
+		parentId := *categoryReq.ParentId
+		if _, err := c.repo.GetCategoryById(parentId); errors.Is(err, repository.ErrCategoryNotFound) {
+			return nil, fmt.Errorf("%w: parent category %d does not exist", ErrInvalidCategory, parentId)
+		} else if err != nil {
+			return nil, err
+		}
+
+		if categoryId != 0 {
+			descendants, err := c.repo.GetDescendantIds(categoryId)
+			if err != nil {
+				return nil, err
+			}
+			if parentId == categoryId || slices.Contains(descendants, parentId) {
+				return nil, fmt.Errorf("%w: a category cannot be moved below itself", ErrInvalidCategory)
+			}
+		}
+	}
+
+	return &model.Category{
+		CategoryId: categoryId,
+		ParentId:   categoryReq.ParentId,
+		Name:       name,
+		Slug:       slug,
+	}, nil
+}
+
+// resolveCategory finds a category by numeric id or by the slug of a name,
+// so that differently spelled names resolve to the same category. A name
+// shared by categories below different parents has to be given by id.
+func resolveCategory(repo repository.ICategoryRepository, ref string) (*model.Category, error) {
+	var category *model.Category
+	var err error
+	if id, convErr := strconv.Atoi(ref); convErr == nil {
+		category, err = repo.GetCategoryById(id)
+	} else {
+		category, err = repo.GetCategoryBySlug(slugify(ref))
+	}
+
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCategory, ref)
+	}
+	if errors.Is(err, repository.ErrAmbiguousSlug) {
+		return nil, fmt.Errorf("%w: %s matches several categories, use the category id", ErrUnknownCategory, ref)
+	}
+	return category, err
+}
+
+// slugify lower-cases s and joins runs of letters and digits with hyphens,
+// matching the normalisation applied by the category migration.
+func slugify(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingHyphen = false
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
+
+func buildCategoryTree(categories []*model.Category) ([]*dto.CategoryResponse, map[int]*dto.CategoryResponse) {
+	nodes := make(map[int]*dto.CategoryResponse, len(categories))
+	for _, category := range categories {
+		nodes[category.CategoryId] = mapCategoryModelToDto(category)
+	}
+
+	roots := make([]*dto.CategoryResponse, 0)
+	for _, category := range categories {
+		node := nodes[category.CategoryId]
+		if category.ParentId == nil {
+			roots = append(roots, node)
+			continue
+		}
+		if parent, ok := nodes[*category.ParentId]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return roots, nodes
+}
+
+func mapCategoryModelToDto(category *model.Category) *dto.CategoryResponse {
+	return &dto.CategoryResponse{
+		CategoryId: category.CategoryId,
+		ParentId:   category.ParentId,
+		Name:       category.Name,
+		Slug:       category.Slug,
+	}
+}
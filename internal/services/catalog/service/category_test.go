@@ -0,0 +1,227 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+func newCategoryTestServices(t *testing.T) (*CategoryService, *ProductService, map[string]int) {
+	t.Helper()
+
+	categoryRepo := repository.NewInMemoryCategoryRepository()
+	productRepo := repository.NewMockProductRepository()
+	categories := NewCategoryService(categoryRepo, productRepo, nil)
+	products := NewProductService(productRepo, categoryRepo, nil)
+
+	ids := make(map[string]int)
+	create := func(name string, parent string) {
+		req := &dto.CategoryRequest{Name: name}
+		if parent != "" {
+			parentId := ids[parent]
+			req.ParentId = &parentId
+		}
+		category, err := categories.CreateCategory(req)
+		if err != nil {
+			t.Fatalf("Expected no error creating %s, got %v", name, err)
+		}
+		ids[name] = category.CategoryId
+	}
+	create("Electronics", "")
+	create("Computers & Laptops", "Electronics")
+	create("Gaming Laptops", "Computers & Laptops")
+	create("Books", "")
+
+	return categories, products, ids
+}
+
+func TestCreateCategoryDerivesSlugAndRejectsDuplicates(t *testing.T) {
+	categories, _, ids := newCategoryTestServices(t)
+
+	category, _ := categories.GetCategory(ids["Computers & Laptops"])
+	if category.Slug != "computers-laptops" {
+		t.Errorf("Expected slug computers-laptops, got %s", category.Slug)
+	}
+
+	if _, err := categories.CreateCategory(&dto.CategoryRequest{Name: "electronics"}); !errors.Is(err, repository.ErrDuplicateSlug) {
+		t.Errorf("Expected ErrDuplicateSlug, got %v", err)
+	}
+
+	missingParent := 999
+	if _, err := categories.CreateCategory(&dto.CategoryRequest{Name: "Phones", ParentId: &missingParent}); !errors.Is(err, ErrInvalidCategory) {
+		t.Errorf("Expected ErrInvalidCategory, got %v", err)
+	}
+}
+
+func TestCategorySlugsAreUniquePerParent(t *testing.T) {
+	categories, products, ids := newCategoryTestServices(t)
+
+	electronicsId, booksId := ids["Electronics"], ids["Books"]
+	accessories, err := categories.CreateCategory(&dto.CategoryRequest{Name: "Accessories", ParentId: &electronicsId})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := categories.CreateCategory(&dto.CategoryRequest{Name: "Accessories", ParentId: &booksId}); err != nil {
+		t.Fatalf("Expected the slug to be reusable below another parent, got %v", err)
+	}
+	if _, err := categories.CreateCategory(&dto.CategoryRequest{Name: "accessories", ParentId: &electronicsId}); !errors.Is(err, repository.ErrDuplicateSlug) {
+		t.Errorf("Expected ErrDuplicateSlug below the same parent, got %v", err)
+	}
+
+	if _, err := products.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "CASE-1", Name: "Case", Category: "Accessories", Description: "Sleeve", Price: 20,
+	}); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("Expected an ambiguous name to be rejected, got %v", err)
+	}
+	created, err := products.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "CASE-1", Name: "Case", CategoryId: &accessories.CategoryId, Description: "Sleeve", Price: 20,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *created.CategoryId != accessories.CategoryId {
+		t.Errorf("Expected category %d, got %d", accessories.CategoryId, *created.CategoryId)
+	}
+}
+
+func TestGetCategoryTreeNestsChildren(t *testing.T) {
+	categories, _, _ := newCategoryTestServices(t)
+
+	tree, err := categories.GetCategoryTree()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(tree) != 2 || tree[0].Name != "Books" || tree[1].Name != "Electronics" {
+		t.Fatalf("Expected Books and Electronics at the top level, got %+v", tree)
+	}
+	if len(tree[1].Children) != 1 || len(tree[1].Children[0].Children) != 1 {
+		t.Fatalf("Expected two levels below Electronics, got %+v", tree[1].Children)
+	}
+}
+
+func TestUpdateCategoryRejectsCycles(t *testing.T) {
+	categories, _, ids := newCategoryTestServices(t)
+
+	parentId := ids["Gaming Laptops"]
+	_, err := categories.UpdateCategory(ids["Electronics"], &dto.CategoryRequest{Name: "Electronics", ParentId: &parentId})
+	if !errors.Is(err, ErrInvalidCategory) {
+		t.Errorf("Expected ErrInvalidCategory, got %v", err)
+	}
+}
+
+func TestUpdateCategoryRenamesAndAnnouncesProducts(t *testing.T) {
+	broker := rabbitmq.NewInMemoryBroker(clock.Real(), logger.NewLogger())
+	publisher, err := messaging.NewPublisher(broker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.DeclareQueue("product-updates")
+	broker.BindQueue("product-updates", events.CatalogExchange, events.ProductUpdated)
+
+	categoryRepo := repository.NewInMemoryCategoryRepository()
+	productRepo := repository.NewMockProductRepository()
+	categories := NewCategoryService(categoryRepo, productRepo, publisher)
+	products := NewProductService(productRepo, categoryRepo, nil)
+
+	category, err := categories.CreateCategory(&dto.CategoryRequest{Name: "Laptops"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := products.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "LAPTOP-1", Name: "Laptop", CategoryId: &category.CategoryId, Description: "Fast", Price: 1500,
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := categories.UpdateCategory(category.CategoryId, &dto.CategoryRequest{Name: "Notebooks"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	product, _ := products.GetProductByCode("LAPTOP-1")
+	if product.Category != "Notebooks" {
+		t.Errorf("Expected the product to follow the category name, got %s", product.Category)
+	}
+
+	messages := broker.Messages("product-updates")
+	if len(messages) != 1 {
+		t.Fatalf("Expected one product.updated event, got %d", len(messages))
+	}
+	var updated events.Envelope[events.ProductUpdatedEvent]
+	if err := json.Unmarshal(messages[0].Body, &updated); err != nil {
+		t.Fatalf("Expected a product.updated envelope, got %v", err)
+	}
+	if updated.Data.ProductCode != "LAPTOP-1" || len(updated.Data.ChangedFields) != 1 || updated.Data.ChangedFields[0] != "category" {
+		t.Errorf("Expected a category change for LAPTOP-1, got %+v", updated.Data)
+	}
+	if updated.Data.OldPrice != 1500 || updated.Data.NewPrice != 1500 {
+		t.Errorf("Expected the unchanged price, got %v to %v", updated.Data.OldPrice, updated.Data.NewPrice)
+	}
+}
+
+func TestDeleteCategoryWithChildren(t *testing.T) {
+	categories, _, ids := newCategoryTestServices(t)
+
+	if _, err := categories.DeleteCategory(ids["Electronics"]); !errors.Is(err, repository.ErrCategoryInUse) {
+		t.Errorf("Expected ErrCategoryInUse, got %v", err)
+	}
+
+	deleted, err := categories.DeleteCategory(ids["Books"])
+	if err != nil || !deleted {
+		t.Errorf("Expected Books to be deleted, got %v, %v", deleted, err)
+	}
+}
+
+func TestProductsAreAssignedToNormalisedCategories(t *testing.T) {
+	_, products, ids := newCategoryTestServices(t)
+
+	created, err := products.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "LAPTOP-1", Name: "Laptop", Category: " gaming LAPTOPS ", Description: "Fast", Price: 1500,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.Category != "Gaming Laptops" || created.CategoryId == nil || *created.CategoryId != ids["Gaming Laptops"] {
+		t.Errorf("Expected canonical Gaming Laptops category, got %s (%v)", created.Category, created.CategoryId)
+	}
+
+	computersId := ids["Computers & Laptops"]
+	if _, err := products.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "PC-1", Name: "Desktop", CategoryId: &computersId, Description: "Tower", Price: 900,
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := products.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "X-1", Name: "Unknown", Category: "Gadgets", Description: "?", Price: 1,
+	}); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("Expected ErrUnknownCategory, got %v", err)
+	}
+
+	direct, _ := products.GetProductsByCategory("electronics", false, repository.ProductFilter{})
+	if len(direct) != 0 {
+		t.Errorf("Expected no products directly in Electronics, got %d", len(direct))
+	}
+
+	all, _ := products.GetProductsByCategory("electronics", true, repository.ProductFilter{})
+	if len(all) != 2 {
+		t.Errorf("Expected 2 products including descendants, got %d", len(all))
+	}
+
+	byId, _ := products.GetProductsByCategory("Computers & Laptops", true, repository.ProductFilter{})
+	if len(byId) != 2 {
+		t.Errorf("Expected 2 products below Computers & Laptops, got %d", len(byId))
+	}
+
+	if _, err := products.GetProductsByCategory("gadgets", true, repository.ProductFilter{}); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("Expected ErrUnknownCategory, got %v", err)
+	}
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+)
+
+const importBatchSize = 500
+
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+// productColumns is the CSV layout used by both import and export. Attributes
+// are written as a JSON object.
+var productColumns = []string{"product_code", "name", "category", "description", "price", "stock", "max_order_quantity", "category_id", "attributes"}
+
+var requiredImportColumns = []string{"product_code", "name", "category", "description", "price"}
+
+type importRow struct {
+	line    int
+	product *dto.CreateProductRequest
+	err     error
+}
+
+// ImportProducts validates every row of a CSV or NDJSON stream and upserts
+// the valid ones by product code in batches, one transaction per batch.
+// Invalid rows are reported and skipped. In dry-run mode nothing is written.
+func (p *ProductService) ImportProducts(r io.Reader, format enums.FileFormat, dryRun bool) (*dto.ImportReport, error) {
+	var readRows func(io.Reader, func(importRow)) error
+	switch format {
+	case enums.FileFormatCSV:
+		readRows = readCSVRows
+	case enums.FileFormatNDJSON:
+		readRows = readNDJSONRows
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+
+	report := &dto.ImportReport{
+		DryRun: dryRun,
+		Errors: make([]*dto.ImportRowError, 0),
+	}
+	seen := make(map[string]int)
+	categories := &importCategories{service: p, resolved: make(map[string]model.Category)}
+	batch := make([]*model.Product, 0, importBatchSize)
+	batchLines := make([]int, 0, importBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if dryRun {
+			report.Imported += len(batch)
+		} else if err := p.repo.UpsertProducts(batch); err != nil {
+			for i, product := range batch {
+				report.Errors = append(report.Errors, &dto.ImportRowError{
+					Line:        batchLines[i],
+					ProductCode: product.ProductCode,
+					Error:       fmt.Sprintf("batch rejected: %v", err),
+				})
+			}
+			report.Failed += len(batch)
+		} else {
+			report.Imported += len(batch)
+			p.publishProductsImported(batch)
+		}
+		batch = batch[:0]
+		batchLines = batchLines[:0]
+	}
+
+	err := readRows(r, func(row importRow) {
+		report.Total++
+
+		if row.err == nil {
+			row.err = validateImportProduct(row.product)
+		}
+		if row.err == nil {
+			if firstLine, duplicate := seen[row.product.ProductCode]; duplicate {
+				row.err = fmt.Errorf("duplicate product_code, first seen on line %d", firstLine)
+			}
+		}
+
+		var product *model.Product
+		if row.err == nil {
+			product = p.mapProductDtoToModel(row.product)
+			row.err = categories.assign(product)
+		}
+
+		if row.err != nil {
+			rowError := &dto.ImportRowError{Line: row.line, Error: row.err.Error()}
+			if row.product != nil {
+				rowError.ProductCode = row.product.ProductCode
+			}
+			report.Errors = append(report.Errors, rowError)
+			report.Failed++
+			return
+		}
+
+		seen[row.product.ProductCode] = row.line
+		batch = append(batch, product)
+		batchLines = append(batchLines, row.line)
+		if len(batch) == importBatchSize {
+			flush()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	flush()
+	return report, nil
+}
+
+// ExportProducts streams the catalog to w one product at a time.
+func (p *ProductService) ExportProducts(w io.Writer, format enums.FileFormat) error {
+	switch format {
+	case enums.FileFormatCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(productColumns); err != nil {
+			return err
+		}
+		err := p.repo.StreamProducts(func(product *model.Product) error {
+			var categoryId, attributes string
+			if product.CategoryId != nil {
+				categoryId = strconv.Itoa(*product.CategoryId)
+			}
+			if len(product.Attributes) > 0 {
+				encoded, err := json.Marshal(product.Attributes)
+				if err != nil {
+					return err
+				}
+				attributes = string(encoded)
+			}
+			return csvWriter.Write([]string{
+				product.ProductCode,
+				product.Name,
+				product.Category,
+				product.Description,
+				strconv.FormatFloat(product.Price, 'f', 2, 64),
+				strconv.Itoa(product.Stock),
+				strconv.Itoa(product.MaxOrderQty),
+				categoryId,
+				attributes,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	case enums.FileFormatNDJSON:
+		encoder := json.NewEncoder(w)
+		return p.repo.StreamProducts(func(product *model.Product) error {
+			return encoder.Encode(p.mapProductModelToDto(product))
+		})
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+func (p *ProductService) publishProductsImported(products []*model.Product) {
+	if p.publisher == nil {
+		return
+	}
+
+	imported := make([]*events.ProductImportedEvent, len(products))
+	for i, product := range products {
+		imported[i] = &events.ProductImportedEvent{
+			ProductEvent: events.ProductEvent{
+				ProductCode: product.ProductCode,
+			},
+			Price: product.Price,
+			Stock: product.Stock,
+		}
+	}
+	if err := p.publisher.PublishProductsImported(imported); err != nil {
+		fmt.Printf("Failed to publish ProductImported events: %v\n", err)
+	}
+}
+
+// importCategories memoises category resolution for the duration of an
+// import, where many rows share a handful of categories.
+type importCategories struct {
+	service  *ProductService
+	resolved map[string]model.Category
+}
+
+func (c *importCategories) assign(product *model.Product) error {
+	key := slugify(product.Category)
+	if product.CategoryId != nil {
+		key = strconv.Itoa(*product.CategoryId)
+	}
+
+	if category, ok := c.resolved[key]; ok {
+		product.CategoryId = &category.CategoryId
+		product.Category = category.Name
+		return nil
+	}
+
+	if err := c.service.assignCategory(product); err != nil {
+		return err
+	}
+	if product.CategoryId != nil {
+		c.resolved[key] = model.Category{CategoryId: *product.CategoryId, Name: product.Category}
+	}
+	return nil
+}
+
+func validateImportProduct(product *dto.CreateProductRequest) error {
+	switch {
+	case product.ProductCode == "":
+		return errors.New("product_code is required")
+	case len(product.ProductCode) > 20:
+		return errors.New("product_code must be at most 20 characters")
+	case product.Name == "":
+		return errors.New("name is required")
+	case len(product.Name) > 100:
+		return errors.New("name must be at most 100 characters")
+	case product.Category == "" && product.CategoryId == nil:
+		return errors.New("category is required")
+	case len(product.Category) > 50:
+		return errors.New("category must be at most 50 characters")
+	case product.Description == "":
+		return errors.New("description is required")
+	case product.Price < 0:
+		return errors.New("price must not be negative")
+	case product.Stock < 0:
+		return errors.New("stock must not be negative")
+	case product.MaxOrderQty < 0:
+		return errors.New("max_order_quantity must not be negative")
+	case len(product.Variants) > 0:
+		return errors.New("variants cannot be imported, create them with the variants endpoint")
+	}
+	return nil
+}
+
+// readCSVRows reads a CSV file with a header row. Columns are matched by
+// name; unknown columns are ignored and the required ones must be present.
+func readCSVRows(r io.Reader, yield func(importRow)) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("CSV header is missing required column %q", name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return err
+			}
+			yield(importRow{line: parseErr.StartLine, err: parseErr.Err})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		product, err := parseCSVRecord(record, columns)
+		yield(importRow{line: line, product: product, err: err})
+	}
+}
+
+func parseCSVRecord(record []string, columns map[string]int) (*dto.CreateProductRequest, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	product := &dto.CreateProductRequest{
+		ProductCode: field("product_code"),
+		Name:        field("name"),
+		Category:    field("category"),
+		Description: field("description"),
+	}
+
+	var err error
+	if product.Price, err = strconv.ParseFloat(field("price"), 64); err != nil {
+		return product, fmt.Errorf("invalid price %q", field("price"))
+	}
+	if value := field("stock"); value != "" {
+		if product.Stock, err = strconv.Atoi(value); err != nil {
+			return product, fmt.Errorf("invalid stock %q", value)
+		}
+	}
+	if value := field("max_order_quantity"); value != "" {
+		if product.MaxOrderQty, err = strconv.Atoi(value); err != nil {
+			return product, fmt.Errorf("invalid max_order_quantity %q", value)
+		}
+	}
+	if value := field("category_id"); value != "" {
+		categoryId, err := strconv.Atoi(value)
+		if err != nil {
+			return product, fmt.Errorf("invalid category_id %q", value)
+		}
+		product.CategoryId = &categoryId
+	}
+	if value := field("attributes"); value != "" {
+		if err := json.Unmarshal([]byte(value), &product.Attributes); err != nil {
+			return product, fmt.Errorf("invalid attributes: %w", err)
+		}
+	}
+
+	return product, nil
+}
+
+// readNDJSONRows reads one JSON product object per line, skipping blank lines.
+func readNDJSONRows(r io.Reader, yield func(importRow)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var product dto.CreateProductRequest
+		if err := json.Unmarshal([]byte(text), &product); err != nil {
+			yield(importRow{line: line, err: fmt.Errorf("invalid JSON: %w", err)})
+			continue
+		}
+
+		yield(importRow{line: line, product: &product})
+	}
+
+	return scanner.Err()
+}
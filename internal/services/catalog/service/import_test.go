@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+func TestImportProductsCSV(t *testing.T) {
+	repo := repository.NewMockProductRepository()
+	repo.CreateProduct(&model.Product{ProductCode: "PROD-1", Name: "Old name", Category: "Books", Description: "Old", Price: 10})
+	s := NewProductService(repo, nil, nil)
+
+	input := strings.Join([]string{
+		"product_code,name,category,description,price,stock,created_at",
+		"PROD-1,Keyboard,Computers,Mechanical keyboard,49.99,10,2025-01-01",
+		"PROD-2,Mouse,Computers,Wireless mouse,19.99,,2025-01-01",
+		"PROD-3,,Computers,Missing name,5,1,2025-01-01",
+		"PROD-4,Cable,Computers,USB cable,abc,1,2025-01-01",
+		"PROD-2,Mouse,Computers,Duplicate row,19.99,1,2025-01-01",
+	}, "\n")
+
+	report, err := s.ImportProducts(strings.NewReader(input), enums.FileFormatCSV, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Total != 5 || report.Imported != 2 || report.Failed != 3 {
+		t.Fatalf("Expected 5 total, 2 imported, 3 failed, got %+v", report)
+	}
+
+	expectedLines := []int{4, 5, 6}
+	for i, rowErr := range report.Errors {
+		if rowErr.Line != expectedLines[i] {
+			t.Errorf("Expected error on line %d, got line %d (%s)", expectedLines[i], rowErr.Line, rowErr.Error)
+		}
+	}
+
+	product, _ := repo.GetProductByCode("PROD-1")
+	if product.Name != "Keyboard" || product.Price != 49.99 || product.Stock != 10 {
+		t.Errorf("Expected PROD-1 to be updated, got %+v", product)
+	}
+	if _, err := repo.GetProductByCode("PROD-2"); err != nil {
+		t.Errorf("Expected PROD-2 to be created, got %v", err)
+	}
+}
+
+func TestImportProductsNDJSON(t *testing.T) {
+	repo := repository.NewMockProductRepository()
+	s := NewProductService(repo, nil, nil)
+
+	input := `{"product_code":"PROD-1","name":"Keyboard","category":"Computers","description":"Mechanical","price":49.99,"stock":3}
+
+{"product_code":"PROD-2","name":"Mouse","category":"Computers","description":"Wireless","price":-1}
+{not json}
+{"product_code":"PROD-3","name":"T-Shirt","category":"Clothing","description":"Cotton","price":15,"variants":[{"sku":"PROD-3-M"}]}
+`
+
+	report, err := s.ImportProducts(strings.NewReader(input), enums.FileFormatNDJSON, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Total != 4 || report.Imported != 1 || report.Failed != 3 {
+		t.Fatalf("Expected 4 total, 1 imported, 3 failed, got %+v", report)
+	}
+	if report.Errors[0].Line != 3 || report.Errors[0].ProductCode != "PROD-2" {
+		t.Errorf("Expected price error for PROD-2 on line 3, got %+v", report.Errors[0])
+	}
+	if report.Errors[1].Line != 4 {
+		t.Errorf("Expected JSON error on line 4, got %+v", report.Errors[1])
+	}
+	if report.Errors[2].Line != 5 || report.Errors[2].ProductCode != "PROD-3" {
+		t.Errorf("Expected variants error for PROD-3 on line 5, got %+v", report.Errors[2])
+	}
+	if _, err := repo.GetProductByCode("PROD-3"); err == nil {
+		t.Error("Expected PROD-3 not to be imported without its variants")
+	}
+}
+
+func TestImportProductsDryRunDoesNotWrite(t *testing.T) {
+	repo := repository.NewMockProductRepository()
+	s := NewProductService(repo, nil, nil)
+
+	input := "product_code,name,category,description,price\nPROD-1,Keyboard,Computers,Mechanical,49.99\n"
+
+	report, err := s.ImportProducts(strings.NewReader(input), enums.FileFormatCSV, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !report.DryRun || report.Imported != 1 {
+		t.Errorf("Expected dry run reporting 1 importable row, got %+v", report)
+	}
+	if _, err := repo.GetProductByCode("PROD-1"); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected no product to be written, got %v", err)
+	}
+}
+
+func TestImportProductsRejectsMissingColumnsAndUnknownFormat(t *testing.T) {
+	s := NewProductService(repository.NewMockProductRepository(), nil, nil)
+
+	if _, err := s.ImportProducts(strings.NewReader("product_code,name\n"), enums.FileFormatCSV, false); err == nil {
+		t.Errorf("Expected error for missing CSV columns")
+	}
+
+	if _, err := s.ImportProducts(strings.NewReader(""), enums.FileFormat("xml"), false); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestExportProductsCSVRoundTrips(t *testing.T) {
+	repo := repository.NewMockProductRepository()
+	repo.CreateProduct(&model.Product{ProductCode: "PROD-2", Name: "Mouse", Category: "Computers", Description: "Wireless, silent", Price: 19.99, Stock: 4})
+	repo.CreateProduct(&model.Product{ProductCode: "PROD-1", Name: "Keyboard", Category: "Computers", Description: "Mechanical", Price: 49.99, Stock: 2})
+	s := NewProductService(repo, nil, nil)
+
+	var buf bytes.Buffer
+	if err := s.ExportProducts(&buf, enums.FileFormatCSV); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got %v", err)
+	}
+	if len(records) != 3 || records[1][0] != "PROD-1" || records[2][3] != "Wireless, silent" {
+		t.Fatalf("Unexpected export %v", records)
+	}
+
+	target := repository.NewMockProductRepository()
+	report, err := NewProductService(target, nil, nil).ImportProducts(&buf, enums.FileFormatCSV, false)
+	if err != nil || report.Imported != 2 {
+		t.Fatalf("Expected exported file to import cleanly, got %+v, %v", report, err)
+	}
+}
+
+func TestExportProductsCSVRoundTripsCategoryAndAttributes(t *testing.T) {
+	categoryRepo := repository.NewInMemoryCategoryRepository()
+	category, _ := categoryRepo.CreateCategory(&model.Category{Name: "Home", Slug: "home"})
+	repo := repository.NewMockProductRepository()
+	repo.CreateProduct(&model.Product{
+		ProductCode: "MUG",
+		Name:        "Mug",
+		Category:    "Home",
+		CategoryId:  &category.CategoryId,
+		Description: "Ceramic",
+		Price:       9.5,
+		Attributes:  map[string]any{"colour": "white, glazed", "capacity_ml": 350.0, "dishwasher_safe": true},
+	})
+
+	var buf bytes.Buffer
+	if err := NewProductService(repo, categoryRepo, nil).ExportProducts(&buf, enums.FileFormatCSV); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	target := repository.NewMockProductRepository()
+	report, err := NewProductService(target, categoryRepo, nil).ImportProducts(&buf, enums.FileFormatCSV, false)
+	if err != nil || report.Imported != 1 {
+		t.Fatalf("Expected exported file to import cleanly, got %+v, %v", report, err)
+	}
+
+	product, _ := target.GetProductByCode("MUG")
+	if product.CategoryId == nil || *product.CategoryId != category.CategoryId {
+		t.Errorf("Expected category %d, got %v", category.CategoryId, product.CategoryId)
+	}
+	if len(product.Attributes) != 3 || product.Attributes["colour"] != "white, glazed" || product.Attributes["capacity_ml"] != 350.0 || product.Attributes["dishwasher_safe"] != true {
+		t.Errorf("Expected the attributes to round trip, got %v", product.Attributes)
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	maxImageEdge   = 8000
+	maxImagePixels = 40_000_000
+)
+
+var (
+	ErrInvalidMedia         = errors.New("invalid media")
+	ErrMediaTooLarge        = errors.New("media exceeds the maximum upload size")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// mediaExtensions lists the accepted upload types by sniffed content type.
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type IMediaService interface {
+	UploadMedia(productCode string, r io.Reader) (*dto.MediaResponse, error)
+	DeleteMedia(productCode string, mediaId int) (bool, error)
+	ReorderMedia(productCode string, mediaIds []int) ([]*dto.MediaResponse, error)
+	OpenMedia(key string) (storage.Blob, string, error)
+}
+
+type MediaService struct {
+	repo          repository.IProductRepository
+	blobs         storage.IBlobStorage
+	publisher     *messaging.Publisher
+	maxUploadSize int64
+}
+
+func NewMediaService(repo repository.IProductRepository, blobs storage.IBlobStorage, publisher *messaging.Publisher, maxUploadSize int64) *MediaService {
+	return &MediaService{
+		repo:          repo,
+		blobs:         blobs,
+		publisher:     publisher,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+// UploadMedia validates an image, stores it together with its thumbnails and
+// appends it to the product's gallery. The content type is sniffed from the
+// data rather than taken from the client.
+func (m *MediaService) UploadMedia(productCode string, r io.Reader) (*dto.MediaResponse, error) {
+	data, err := io.ReadAll(io.LimitReader(r, m.maxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > m.maxUploadSize {
+		return nil, fmt.Errorf("%w of %d bytes", ErrMediaTooLarge, m.maxUploadSize)
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := mediaExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMedia, err)
+	}
+	if config.Width > maxImageEdge || config.Height > maxImageEdge || config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w: image of %dx%d pixels is too large", ErrInvalidMedia, config.Width, config.Height)
+	}
+
+	product, err := m.repo.GetProductByCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMedia, err)
+	}
+
+	thumbnails, err := encodeThumbnails(img, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnailExtension := ".png"
+	if contentType == "image/jpeg" {
+		thumbnailExtension = ".jpg"
+	}
+
+	prefix := uuid.NewString()
+	media := &model.ProductMedia{
+		ContentType: contentType,
+		StorageKey:  prefix + "/original" + extension,
+		Thumbnails:  make(map[string]any, len(thumbnails)),
+		Width:       config.Width,
+		Height:      config.Height,
+		SizeBytes:   int64(len(data)),
+	}
+
+	stored := []string{media.StorageKey}
+	err = m.blobs.Put(media.StorageKey, bytes.NewReader(data))
+	for _, size := range thumbnailSizes {
+		if err != nil {
+			break
+		}
+		key := prefix + "/" + size.name + thumbnailExtension
+		media.Thumbnails[size.name] = key
+		stored = append(stored, key)
+		err = m.blobs.Put(key, bytes.NewReader(thumbnails[size.name]))
+	}
+	if err == nil {
+		media, err = m.repo.AddMedia(productCode, media)
+	}
+	if err != nil {
+		m.deleteBlobs(stored)
+		return nil, err
+	}
+
+	publishProductChanged(m.publisher, product, "media")
+	return mapMediaModelToDto(media), nil
+}
+
+// DeleteMedia removes an image from the gallery and then from storage.
+func (m *MediaService) DeleteMedia(productCode string, mediaId int) (bool, error) {
+	product, err := m.repo.GetProductByCode(productCode)
+	if err != nil {
+		return false, err
+	}
+
+	i := slices.IndexFunc(product.Media, func(media *model.ProductMedia) bool { return media.MediaId == mediaId })
+	if i < 0 {
+		return false, nil
+	}
+	media := product.Media[i]
+
+	deleted, err := m.repo.DeleteMedia(productCode, mediaId)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+
+	m.deleteBlobs(mediaStorageKeys(media))
+	publishProductChanged(m.publisher, product, "media")
+	return true, nil
+}
+
+// ReorderMedia sets the gallery order. mediaIds must list every image of the
+// product exactly once.
+func (m *MediaService) ReorderMedia(productCode string, mediaIds []int) ([]*dto.MediaResponse, error) {
+	product, err := m.repo.GetProductByCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make([]int, len(product.Media))
+	for i, media := range product.Media {
+		current[i] = media.MediaId
+	}
+	requested := slices.Clone(mediaIds)
+	slices.Sort(current)
+	slices.Sort(requested)
+	if !slices.Equal(current, requested) {
+		return nil, fmt.Errorf("%w: media_ids must list every media id of the product exactly once", ErrInvalidMedia)
+	}
+
+	if err := m.repo.ReorderMedia(productCode, mediaIds); err != nil {
+		return nil, err
+	}
+
+	publishProductChanged(m.publisher, product, "media")
+
+	reordered, err := m.repo.GetProductByCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+	return mapMediaModelsToDto(reordered.Media), nil
+}
+
+// OpenMedia opens a stored image or thumbnail and returns its content type.
+func (m *MediaService) OpenMedia(key string) (storage.Blob, string, error) {
+	blob, err := m.blobs.Open(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return blob, mime.TypeByExtension(path.Ext(key)), nil
+}
+
+func (m *MediaService) deleteBlobs(keys []string) {
+	for _, key := range keys {
+		if err := m.blobs.Delete(key); err != nil {
+			fmt.Printf("Failed to delete media blob %s: %v\n", key, err)
+		}
+	}
+}
+
+// encodeThumbnails renders every thumbnail size. JPEG sources produce JPEG
+// thumbnails; PNG and GIF sources produce PNG to keep transparency.
+func encodeThumbnails(img image.Image, contentType string) (map[string][]byte, error) {
+	rgba := toRGBA(img)
+	thumbnails := make(map[string][]byte, len(thumbnailSizes))
+	for _, size := range thumbnailSizes {
+		var buf bytes.Buffer
+		var err error
+		thumbnail := resizeToFit(rgba, size.maxEdge)
+		if contentType == "image/jpeg" {
+			err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&buf, thumbnail)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s thumbnail: %w", size.name, err)
+		}
+		thumbnails[size.name] = buf.Bytes()
+	}
+	return thumbnails, nil
+}
+
+func mediaStorageKeys(media *model.ProductMedia) []string {
+	keys := []string{media.StorageKey}
+	for _, key := range media.Thumbnails {
+		if key, ok := key.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func mediaURL(key string) string {
+	return "/media/" + key
+}
+
+func mapMediaModelToDto(media *model.ProductMedia) *dto.MediaResponse {
+	thumbnails := make(map[string]string, len(media.Thumbnails))
+	for name, key := range media.Thumbnails {
+		if key, ok := key.(string); ok {
+			thumbnails[name] = mediaURL(key)
+		}
+	}
+
+	return &dto.MediaResponse{
+		MediaId:     media.MediaId,
+		Position:    media.Position,
+		ContentType: media.ContentType,
+		Width:       media.Width,
+		Height:      media.Height,
+		URL:         mediaURL(media.StorageKey),
+		Thumbnails:  thumbnails,
+	}
+}
+
+func mapMediaModelsToDto(media []*model.ProductMedia) []*dto.MediaResponse {
+	responses := make([]*dto.MediaResponse, len(media))
+	for i, item := range media {
+		responses[i] = mapMediaModelToDto(item)
+	}
+	return responses
+}
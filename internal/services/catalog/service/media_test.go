@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/storage"
+)
+
+func newMediaTestService(t *testing.T, maxUploadSize int64) (*MediaService, *ProductService, *storage.LocalFileStorage) {
+	t.Helper()
+
+	repo := repository.NewMockProductRepository()
+	products := NewProductService(repo, nil, nil)
+	products.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "LAMP",
+		Name:        "Desk Lamp",
+		Category:    "Home",
+		Description: "LED desk lamp",
+		Price:       39.99,
+	})
+
+	blobs, err := storage.NewLocalFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	return NewMediaService(repo, blobs, nil, maxUploadSize), products, blobs
+}
+
+func testPNG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decodeStoredImage(t *testing.T, blobs *storage.LocalFileStorage, url string) image.Config {
+	t.Helper()
+
+	blob, err := blobs.Open(strings.TrimPrefix(url, "/media/"))
+	if err != nil {
+		t.Fatalf("Expected %s to be stored, got %v", url, err)
+	}
+	defer blob.Close()
+
+	config, _, err := image.DecodeConfig(blob)
+	if err != nil {
+		t.Fatalf("Expected %s to be an image, got %v", url, err)
+	}
+	return config
+}
+
+func TestUploadMediaStoresOriginalAndThumbnails(t *testing.T) {
+	s, products, blobs := newMediaTestService(t, 1<<20)
+
+	media, err := s.UploadMedia("LAMP", bytes.NewReader(testPNG(t, 1200, 600)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if media.ContentType != "image/png" || media.Width != 1200 || media.Height != 600 {
+		t.Errorf("Expected a 1200x600 PNG, got %s %dx%d", media.ContentType, media.Width, media.Height)
+	}
+	if config := decodeStoredImage(t, blobs, media.URL); config.Width != 1200 {
+		t.Errorf("Expected the original to be stored unchanged, got width %d", config.Width)
+	}
+
+	expected := map[string][2]int{"small": {160, 80}, "medium": {480, 240}, "large": {1024, 512}}
+	for name, size := range expected {
+		url, ok := media.Thumbnails[name]
+		if !ok {
+			t.Errorf("Expected a %s thumbnail", name)
+			continue
+		}
+		if config := decodeStoredImage(t, blobs, url); config.Width != size[0] || config.Height != size[1] {
+			t.Errorf("Expected %s thumbnail of %dx%d, got %dx%d", name, size[0], size[1], config.Width, config.Height)
+		}
+	}
+
+	product, _ := products.GetProductByCode("LAMP")
+	if len(product.Media) != 1 || product.Media[0].URL != media.URL {
+		t.Errorf("Expected the product response to include the media URL, got %+v", product.Media)
+	}
+}
+
+func TestUploadMediaRejectsInvalidUploads(t *testing.T) {
+	s, _, _ := newMediaTestService(t, 200)
+
+	if _, err := s.UploadMedia("LAMP", strings.NewReader("<html>not an image</html>")); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Expected ErrUnsupportedMediaType, got %v", err)
+	}
+
+	if _, err := s.UploadMedia("LAMP", bytes.NewReader(testPNG(t, 400, 400))); !errors.Is(err, ErrMediaTooLarge) {
+		t.Errorf("Expected ErrMediaTooLarge, got %v", err)
+	}
+
+	truncated := testPNG(t, 10, 10)[:40]
+	if _, err := s.UploadMedia("LAMP", bytes.NewReader(truncated)); !errors.Is(err, ErrInvalidMedia) {
+		t.Errorf("Expected ErrInvalidMedia, got %v", err)
+	}
+
+	if _, err := s.UploadMedia("MISSING", bytes.NewReader(testPNG(t, 10, 10))); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestReorderAndDeleteMedia(t *testing.T) {
+	s, products, blobs := newMediaTestService(t, 1<<20)
+
+	var ids []int
+	for i := 0; i < 3; i++ {
+		media, err := s.UploadMedia("LAMP", bytes.NewReader(testPNG(t, 20+i, 20)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ids = append(ids, media.MediaId)
+	}
+
+	if _, err := s.ReorderMedia("LAMP", []int{ids[0], ids[1]}); !errors.Is(err, ErrInvalidMedia) {
+		t.Errorf("Expected ErrInvalidMedia for an incomplete order, got %v", err)
+	}
+
+	reordered, err := s.ReorderMedia("LAMP", []int{ids[2], ids[0], ids[1]})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reordered[0].MediaId != ids[2] || reordered[0].Position != 0 || reordered[2].MediaId != ids[1] {
+		t.Errorf("Expected the requested order, got %+v", reordered)
+	}
+
+	first := reordered[0]
+	deleted, err := s.DeleteMedia("LAMP", first.MediaId)
+	if err != nil || !deleted {
+		t.Fatalf("Expected media to be deleted, got %v, %v", deleted, err)
+	}
+	if _, err := blobs.Open(strings.TrimPrefix(first.URL, "/media/")); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("Expected the original to be removed from storage, got %v", err)
+	}
+
+	product, _ := products.GetProductByCode("LAMP")
+	if len(product.Media) != 2 || product.Media[0].MediaId != ids[0] || product.Media[0].Position != 0 {
+		t.Errorf("Expected the remaining media to move up, got %+v", product.Media)
+	}
+
+	if deleted, _ := s.DeleteMedia("LAMP", first.MediaId); deleted {
+		t.Error("Expected deleting missing media to report false")
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+)
+
+var ErrInvalidPriceSchedule = errors.New("invalid price schedule")
+
+func (p *ProductService) GetPriceHistory(productCode string, limit int) ([]*dto.PriceChangeResponse, error) {
+	history, err := p.repo.GetPriceHistory(productCode, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.PriceChangeResponse, len(history))
+	for i, change := range history {
+		responses[i] = &dto.PriceChangeResponse{
+			OldPrice:   change.OldPrice,
+			NewPrice:   change.NewPrice,
+			Source:     string(change.Source),
+			ScheduleId: change.ScheduleId,
+			ChangedAt:  change.ChangedAt,
+		}
+	}
+	return responses, nil
+}
+
+func (p *ProductService) GetPriceSchedules(productCode string) ([]*dto.PriceScheduleResponse, error) {
+	schedules, err := p.repo.GetPriceSchedules(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapPriceSchedulesToDto(schedules), nil
+}
+
+// SchedulePriceChange stores a future price change for the price scheduler.
+// A change whose time has already passed is applied on the scheduler's next
+// run. With EndsAt, a linked change reverts it; until the change applies the
+// revert shows the current price.
+func (p *ProductService) SchedulePriceChange(productCode string, req *dto.PriceScheduleRequest) ([]*dto.PriceScheduleResponse, error) {
+	switch {
+	case req.Price <= 0:
+		return nil, fmt.Errorf("%w: price must be positive", ErrInvalidPriceSchedule)
+	case req.EffectiveAt.IsZero():
+		return nil, fmt.Errorf("%w: effective_at is required", ErrInvalidPriceSchedule)
+	case req.EndsAt != nil && !req.EndsAt.After(req.EffectiveAt):
+		return nil, fmt.Errorf("%w: ends_at must be after effective_at", ErrInvalidPriceSchedule)
+	}
+
+	product, err := p.repo.GetProductByCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &model.PriceSchedule{Price: req.Price, EffectiveAt: req.EffectiveAt}
+	var revert *model.PriceSchedule
+	if req.EndsAt != nil {
+		revert = &model.PriceSchedule{Price: product.Price, EffectiveAt: *req.EndsAt}
+	}
+
+	created, err := p.repo.CreatePriceSchedule(productCode, schedule, revert)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapPriceSchedulesToDto(created), nil
+}
+
+func (p *ProductService) CancelPriceSchedule(productCode string, scheduleId int) (bool, error) {
+	return p.repo.CancelPriceSchedule(productCode, scheduleId)
+}
+
+func mapPriceSchedulesToDto(schedules []*model.PriceSchedule) []*dto.PriceScheduleResponse {
+	responses := make([]*dto.PriceScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		responses[i] = &dto.PriceScheduleResponse{
+			ScheduleId:  schedule.ScheduleId,
+			ParentId:    schedule.ParentId,
+			Price:       schedule.Price,
+			EffectiveAt: schedule.EffectiveAt,
+			Status:      string(schedule.Status),
+			AppliedAt:   schedule.AppliedAt,
+		}
+	}
+	return responses
+}
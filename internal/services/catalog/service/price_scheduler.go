@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+// minSchedulerWait keeps the scheduler from spinning when a due schedule
+// cannot be applied.
+const minSchedulerWait = time.Second
+
+// PriceScheduler applies scheduled price changes when they become due.
+// Schedules are stored in the database, so pending changes survive restarts
+// and are picked up by whichever catalog instance runs first. It sleeps until
+// the next schedule is due, but at most pollInterval, so that schedules
+// created by other instances are noticed.
+type PriceScheduler struct {
+	repo         repository.IProductRepository
+	publisher    *messaging.Publisher
+	clock        clock.Clock
+	pollInterval time.Duration
+	log          logger.Logger
+	stop         chan struct{}
+	done         sync.WaitGroup
+}
+
+func NewPriceScheduler(repo repository.IProductRepository, publisher *messaging.Publisher, clk clock.Clock, pollInterval time.Duration, log logger.Logger) *PriceScheduler {
+	return &PriceScheduler{
+		repo:         repo,
+		publisher:    publisher,
+		clock:        clk,
+		pollInterval: pollInterval,
+		log:          log,
+		stop:         make(chan struct{}),
+	}
+}
+
+func (s *PriceScheduler) Start() {
+	s.log.Info("Starting price scheduler", "poll_interval", s.pollInterval.String())
+
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+
+		for {
+			if _, err := s.ApplyDue(); err != nil {
+				s.log.Error("Failed to apply scheduled price changes", "error", err)
+			}
+
+			select {
+			case <-s.clock.After(s.nextWait()):
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *PriceScheduler) Stop() {
+	close(s.stop)
+	s.done.Wait()
+}
+
+// ApplyDue applies every schedule that is due at the current time, oldest
+// first, and returns how many were applied.
+func (s *PriceScheduler) ApplyDue() (int, error) {
+	applied := 0
+	for {
+		history, err := s.repo.ApplyDuePriceSchedule(s.clock.Now())
+		if err != nil {
+			return applied, err
+		}
+		if history == nil {
+			return applied, nil
+		}
+		applied++
+
+		s.log.Info("Applied scheduled price change",
+			"product_code", history.Product.ProductCode,
+			"old_price", history.OldPrice,
+			"new_price", history.NewPrice)
+
+		if s.publisher != nil && history.OldPrice != history.NewPrice {
+			event := &events.ProductUpdatedEvent{
+				ProductEvent: events.ProductEvent{
+					ProductCode: history.Product.ProductCode,
+				},
+				ChangedFields: []string{"price"},
+				OldPrice:      history.OldPrice,
+				NewPrice:      history.NewPrice,
+			}
+			if err := s.publisher.PublishProductUpdated(event); err != nil {
+				fmt.Printf("Failed to publish ProductUpdated event: %v\n", err)
+			}
+		}
+	}
+}
+
+func (s *PriceScheduler) nextWait() time.Duration {
+	wait := s.pollInterval
+
+	next, err := s.repo.NextPriceScheduleTime()
+	if err != nil {
+		s.log.Error("Failed to get next price schedule", "error", err)
+		return wait
+	}
+	if next != nil {
+		wait = min(wait, next.Sub(s.clock.Now()))
+	}
+	return max(wait, minSchedulerWait)
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+// waitForScheduler waits until the scheduler goroutine is blocked on the fake
+// clock, after which it no longer touches the repository.
+func waitForScheduler(t *testing.T, c *clock.Fake) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Waiters() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the price scheduler")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestUpdateProductRecordsPriceHistory(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	for _, update := range []struct {
+		name  string
+		price float64
+	}{{"Mug", 9}, {"Big Mug", 9}, {"Big Mug", 7.5}} {
+		_, err := s.UpdateProduct("MUG", &dto.UpdateProductRequest{Name: update.name, Category: "Home", Price: update.price}, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	history, err := s.GetPriceHistory("MUG", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 price changes, got %d", len(history))
+	}
+	if history[0].OldPrice != 9 || history[0].NewPrice != 7.5 || history[0].Source != "manual" {
+		t.Errorf("Expected the newest change 9 -> 7.5 first, got %+v", history[0])
+	}
+
+	limited, _ := s.GetPriceHistory("MUG", 1)
+	if len(limited) != 1 {
+		t.Errorf("Expected 1 price change with limit, got %d", len(limited))
+	}
+
+	if _, err := s.GetPriceHistory("MISSING", 0); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestSchedulePriceChangeValidation(t *testing.T) {
+	s, _ := newVariantTestService(t)
+	start := time.Date(2030, 11, 28, 0, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Hour)
+
+	tests := []*dto.PriceScheduleRequest{
+		{Price: 0, EffectiveAt: start},
+		{Price: 5},
+		{Price: 5, EffectiveAt: start, EndsAt: &before},
+	}
+	for _, req := range tests {
+		if _, err := s.SchedulePriceChange("MUG", req); !errors.Is(err, ErrInvalidPriceSchedule) {
+			t.Errorf("Expected ErrInvalidPriceSchedule for %+v, got %v", req, err)
+		}
+	}
+
+	if _, err := s.SchedulePriceChange("MISSING", &dto.PriceScheduleRequest{Price: 5, EffectiveAt: start}); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestPriceSchedulerAppliesSaleAtTheRightTime(t *testing.T) {
+	s, repo := newVariantTestService(t)
+	now := time.Date(2030, 11, 27, 12, 0, 0, 0, time.UTC)
+	saleStart := now.Add(12 * time.Hour)
+	saleEnd := saleStart.Add(72 * time.Hour)
+
+	schedules, err := s.SchedulePriceChange("MUG", &dto.PriceScheduleRequest{Price: 5, EffectiveAt: saleStart, EndsAt: &saleEnd})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(schedules) != 2 || schedules[1].Price != 8 {
+		t.Fatalf("Expected a sale start and a revert to 8, got %+v", schedules)
+	}
+
+	fake := clock.NewFake(now)
+	scheduler := NewPriceScheduler(repo, nil, fake, time.Hour, logger.NewLogger())
+	scheduler.Start()
+
+	waitForScheduler(t, fake)
+	fake.Set(saleStart.Add(-time.Minute))
+	waitForScheduler(t, fake)
+	if product, _ := s.GetProductByCode("MUG"); product.Price != 8 {
+		t.Fatalf("Expected the price to be unchanged before the sale, got %.2f", product.Price)
+	}
+
+	fake.Set(saleStart)
+	waitForScheduler(t, fake)
+	if product, _ := s.GetProductByCode("MUG"); product.Price != 5 {
+		t.Fatalf("Expected the sale price at the start of the sale, got %.2f", product.Price)
+	}
+
+	// Schedules are persisted, so a scheduler started after a restart picks
+	// up the end of the sale.
+	scheduler.Stop()
+	restarted := NewPriceScheduler(repo, nil, fake, time.Hour, logger.NewLogger())
+	restarted.Start()
+	defer restarted.Stop()
+
+	waitForScheduler(t, fake)
+	fake.Set(saleEnd)
+	waitForScheduler(t, fake)
+	if product, _ := s.GetProductByCode("MUG"); product.Price != 8 {
+		t.Fatalf("Expected the price to revert after the sale, got %.2f", product.Price)
+	}
+
+	history, _ := s.GetPriceHistory("MUG", 0)
+	if len(history) != 2 || history[0].Source != "schedule" || history[0].ChangedAt != saleEnd {
+		t.Errorf("Expected 2 scheduled price changes, got %+v", history)
+	}
+
+	pending, _ := s.GetPriceSchedules("MUG")
+	for _, schedule := range pending {
+		if schedule.Status != "applied" {
+			t.Errorf("Expected schedule %d to be applied, got %s", schedule.ScheduleId, schedule.Status)
+		}
+	}
+}
+
+func TestCancelledPriceSchedulesAreNotApplied(t *testing.T) {
+	s, repo := newVariantTestService(t)
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	schedules, _ := s.SchedulePriceChange("MUG", &dto.PriceScheduleRequest{Price: 5, EffectiveAt: now.Add(time.Hour)})
+	cancelled, err := s.CancelPriceSchedule("MUG", schedules[0].ScheduleId)
+	if err != nil || !cancelled {
+		t.Fatalf("Expected schedule to be cancelled, got %v, %v", cancelled, err)
+	}
+	if cancelled, _ := s.CancelPriceSchedule("MUG", schedules[0].ScheduleId); cancelled {
+		t.Error("Expected cancelling twice to report false")
+	}
+
+	fake := clock.NewFake(now.Add(2 * time.Hour))
+	applied, err := NewPriceScheduler(repo, nil, fake, time.Hour, logger.NewLogger()).ApplyDue()
+	if err != nil || applied != 0 {
+		t.Errorf("Expected nothing to be applied, got %d, %v", applied, err)
+	}
+}
+
+func TestSaleRevertsToThePriceItReplaced(t *testing.T) {
+	s, repo := newVariantTestService(t)
+	now := time.Date(2030, 11, 27, 12, 0, 0, 0, time.UTC)
+	saleStart := now.Add(12 * time.Hour)
+	saleEnd := saleStart.Add(72 * time.Hour)
+
+	schedules, err := s.SchedulePriceChange("MUG", &dto.PriceScheduleRequest{Price: 5, EffectiveAt: saleStart, EndsAt: &saleEnd})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if schedules[1].ParentId == nil || *schedules[1].ParentId != schedules[0].ScheduleId {
+		t.Fatalf("Expected the revert to be linked to the sale start, got %+v", schedules[1])
+	}
+
+	// The price changes between scheduling and the start of the sale
+	if _, err := s.UpdateProduct("MUG", &dto.UpdateProductRequest{Name: "Mug", Category: "Home", Price: 10}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	scheduler := NewPriceScheduler(repo, nil, clock.NewFake(saleEnd), time.Hour, logger.NewLogger())
+	if applied, err := scheduler.ApplyDue(); err != nil || applied != 2 {
+		t.Fatalf("Expected the sale to start and end, got %d, %v", applied, err)
+	}
+
+	if product, _ := s.GetProductByCode("MUG"); product.Price != 10 || !product.UpdatedAt.Equal(saleEnd) {
+		t.Errorf("Expected the price to revert to 10 at the end of the sale, got %.2f at %v", product.Price, product.UpdatedAt)
+	}
+}
+
+func TestCancellingASaleCancelsItsRevert(t *testing.T) {
+	s, repo := newVariantTestService(t)
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	saleEnd := now.Add(2 * time.Hour)
+
+	schedules, _ := s.SchedulePriceChange("MUG", &dto.PriceScheduleRequest{Price: 5, EffectiveAt: now.Add(time.Hour), EndsAt: &saleEnd})
+	if cancelled, err := s.CancelPriceSchedule("MUG", schedules[0].ScheduleId); err != nil || !cancelled {
+		t.Fatalf("Expected schedule to be cancelled, got %v, %v", cancelled, err)
+	}
+
+	pending, _ := s.GetPriceSchedules("MUG")
+	for _, schedule := range pending {
+		if schedule.Status != "cancelled" {
+			t.Errorf("Expected schedule %d to be cancelled, got %s", schedule.ScheduleId, schedule.Status)
+		}
+	}
+
+	applied, err := NewPriceScheduler(repo, nil, clock.NewFake(saleEnd), time.Hour, logger.NewLogger()).ApplyDue()
+	if err != nil || applied != 0 {
+		t.Errorf("Expected nothing to be applied, got %d, %v", applied, err)
+	}
+}
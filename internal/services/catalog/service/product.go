@@ -1,27 +1,60 @@
 package service
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/mergepatch"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
 	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
 )
 
+var ErrInvalidProduct = errors.New("invalid product")
+
 type IProductService interface {
 	GetAllProducts() ([]*model.Product, error)
-	GetProductsByCategory(category string) ([]*model.Product, error)
+	GetProductsByCategory(category string, includeDescendants bool, filter repository.ProductFilter) ([]*model.Product, error)
+	FilterProducts(filter repository.ProductFilter) ([]*model.Product, error)
 	GetProductByCode(productCode string) (*dto.ProductResponse, error)
 	CreateProduct(productReq *dto.CreateProductRequest) (*dto.ProductResponse, error)
-	UpdateProduct(productCode string, product *model.Product) (*model.Product, error)
+	UpdateProduct(productCode string, productReq *dto.UpdateProductRequest, version *time.Time) (*dto.ProductResponse, error)
+	PatchProduct(productCode string, patch []byte, version *time.Time) (*dto.ProductResponse, error)
 	DeleteProduct(productCode string) (bool, error)
+	RestoreProduct(productCode string) (*dto.ProductResponse, error)
+	GetVariantBySKU(sku string) (*dto.VariantDetailResponse, error)
+	CreateVariant(productCode string, variantReq *dto.VariantRequest) (*dto.VariantResponse, error)
+	UpdateVariant(sku string, variantReq *dto.VariantRequest) (*dto.VariantResponse, error)
+	DeleteVariant(sku string) (bool, error)
+	GetPriceHistory(productCode string, limit int) ([]*dto.PriceChangeResponse, error)
+	GetPriceSchedules(productCode string) ([]*dto.PriceScheduleResponse, error)
+	SchedulePriceChange(productCode string, req *dto.PriceScheduleRequest) ([]*dto.PriceScheduleResponse, error)
+	CancelPriceSchedule(productCode string, scheduleId int) (bool, error)
+	ImportProducts(r io.Reader, format enums.FileFormat, dryRun bool) (*dto.ImportReport, error)
+	ExportProducts(w io.Writer, format enums.FileFormat) error
 }
 
 type ProductService struct {
-	repo repository.IProductRepository
+	repo         repository.IProductRepository
+	categoryRepo repository.ICategoryRepository
+	publisher    *messaging.Publisher
 }
 
-func NewProductService(repo repository.IProductRepository) *ProductService {
+// NewProductService creates a product service. When categoryRepo is nil,
+// categories are treated as free text and not resolved against t_category.
+func NewProductService(repo repository.IProductRepository, categoryRepo repository.ICategoryRepository, publisher *messaging.Publisher) *ProductService {
 	return &ProductService{
-		repo: repo,
+		repo:         repo,
+		categoryRepo: categoryRepo,
+		publisher:    publisher,
 	}
 }
 
@@ -34,8 +67,33 @@ func (p *ProductService) GetAllProducts() ([]*model.Product, error) {
 	return products, nil
 }
 
-func (p *ProductService) GetProductsByCategory(category string) ([]*model.Product, error) {
-	products, err := p.repo.GetProductsByCategory(category)
+// GetProductsByCategory lists the products of a category given by id or
+// name, optionally including the products of all its subcategories. The
+// category fields of filter are set from the resolved category.
+func (p *ProductService) GetProductsByCategory(category string, includeDescendants bool, filter repository.ProductFilter) ([]*model.Product, error) {
+	if p.categoryRepo == nil {
+		if len(filter.Attributes) > 0 || filter.IncludeDeleted {
+			filter.Category = category
+			return p.repo.GetProductsByFilter(filter)
+		}
+		return p.repo.GetProductsByCategory(category)
+	}
+
+	resolved, err := resolveCategory(p.categoryRepo, category)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.CategoryIds = []int{resolved.CategoryId}
+	if includeDescendants {
+		descendants, err := p.categoryRepo.GetDescendantIds(resolved.CategoryId)
+		if err != nil {
+			return nil, err
+		}
+		filter.CategoryIds = append(filter.CategoryIds, descendants...)
+	}
+
+	products, err := p.repo.GetProductsByFilter(filter)
 	if err != nil {
 		return nil, err
 	}
@@ -43,6 +101,10 @@ func (p *ProductService) GetProductsByCategory(category string) ([]*model.Produc
 	return products, nil
 }
 
+func (p *ProductService) FilterProducts(filter repository.ProductFilter) ([]*model.Product, error) {
+	return p.repo.GetProductsByFilter(filter)
+}
+
 func (p *ProductService) GetProductByCode(productCode string) (*dto.ProductResponse, error) {
 	product, err := p.repo.GetProductByCode(productCode)
 	if err != nil {
@@ -53,42 +115,301 @@ func (p *ProductService) GetProductByCode(productCode string) (*dto.ProductRespo
 }
 
 func (p *ProductService) CreateProduct(productReq *dto.CreateProductRequest) (*dto.ProductResponse, error) {
+	for _, variant := range productReq.Variants {
+		if err := validateVariant(variant); err != nil {
+			return nil, err
+		}
+	}
+
 	product := p.mapProductDtoToModel(productReq)
+	if err := p.assignCategory(product); err != nil {
+		return nil, err
+	}
 
 	createdProduct, err := p.repo.CreateProduct(product)
 	if err != nil {
 		return nil, err
 	}
 
+	if p.publisher != nil {
+		event := &events.ProductCreatedEvent{
+			ProductEvent: events.ProductEvent{
+				ProductCode: createdProduct.ProductCode,
+			},
+			Name:        createdProduct.Name,
+			Category:    createdProduct.Category,
+			Description: createdProduct.Description,
+			Price:       createdProduct.Price,
+			Stock:       createdProduct.Stock,
+		}
+		if err := p.publisher.PublishProductCreated(event); err != nil {
+			fmt.Printf("Failed to publish ProductCreated event: %v\n", err)
+		}
+	}
+
 	return p.mapProductModelToDto(createdProduct), nil
 }
 
-func (p *ProductService) UpdateProduct(productCode string, updatedProduct *model.Product) (*model.Product, error) {
-	product, err := p.repo.UpdateProduct(updatedProduct)
+// UpdateProduct replaces every editable field of a product with the values in
+// the request; omitted fields are reset. The product code in the path is
+// authoritative and cannot be changed. A non-nil version makes the update
+// conditional on the product's last update time, see
+// IProductRepository.UpdateProduct.
+func (p *ProductService) UpdateProduct(productCode string, productReq *dto.UpdateProductRequest, version *time.Time) (*dto.ProductResponse, error) {
+	oldProduct, err := p.repo.GetProductByCode(productCode)
 	if err != nil {
 		return nil, err
 	}
 
-	return product, nil
+	return p.replaceProduct(oldProduct, productReq, version)
 }
 
+// PatchProduct applies a JSON Merge Patch (RFC 7396) to the product's
+// editable fields. Members set to null are cleared and zero values are kept,
+// unlike the omitted members, which stay unchanged. version is handled as in
+// UpdateProduct.
+func (p *ProductService) PatchProduct(productCode string, patch []byte, version *time.Time) (*dto.ProductResponse, error) {
+	oldProduct, err := p.repo.GetProductByCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return nil, fmt.Errorf("%w: patch must be a JSON object", ErrInvalidProduct)
+	}
+
+	document, err := json.Marshal(mapProductModelToUpdateDto(oldProduct))
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	var productReq dto.UpdateProductRequest
+	if err := json.Unmarshal(patched, &productReq); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+
+	// A category changed by name must not be overridden by the previous id
+	_, hasCategory := members["category"]
+	_, hasCategoryId := members["category_id"]
+	if hasCategory && !hasCategoryId {
+		productReq.CategoryId = nil
+	}
+
+	return p.replaceProduct(oldProduct, &productReq, version)
+}
+
+func (p *ProductService) replaceProduct(oldProduct *model.Product, productReq *dto.UpdateProductRequest, version *time.Time) (*dto.ProductResponse, error) {
+	if productReq.ProductCode != "" && productReq.ProductCode != oldProduct.ProductCode {
+		return nil, fmt.Errorf("%w: product_code %q does not match the product being updated", ErrInvalidProduct, productReq.ProductCode)
+	}
+	if err := validateProduct(productReq); err != nil {
+		return nil, err
+	}
+
+	updatedProduct := &model.Product{
+		ProductCode: oldProduct.ProductCode,
+		Name:        productReq.Name,
+		Category:    productReq.Category,
+		CategoryId:  productReq.CategoryId,
+		Description: productReq.Description,
+		Price:       productReq.Price,
+		Stock:       productReq.Stock,
+		MaxOrderQty: productReq.MaxOrderQty,
+		Attributes:  attributesOrEmpty(productReq.Attributes),
+	}
+	if p.categoryRepo != nil {
+		if err := p.assignCategory(updatedProduct); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.repo.UpdateProduct(updatedProduct, version); err != nil {
+		return nil, err
+	}
+
+	if changedFields := changedProductFields(oldProduct, updatedProduct); p.publisher != nil && len(changedFields) > 0 {
+		event := &events.ProductUpdatedEvent{
+			ProductEvent: events.ProductEvent{
+				ProductCode: oldProduct.ProductCode,
+			},
+			ChangedFields: changedFields,
+			OldPrice:      oldProduct.Price,
+			NewPrice:      updatedProduct.Price,
+		}
+		if err := p.publisher.PublishProductUpdated(event); err != nil {
+			fmt.Printf("Failed to publish ProductUpdated event: %v\n", err)
+		}
+	}
+
+	product, err := p.repo.GetProductByCode(oldProduct.ProductCode)
+	if err != nil {
+		return nil, err
+	}
+	return p.mapProductModelToDto(product), nil
+}
+
+// DeleteProduct soft deletes a product. It disappears from listings and
+// lookups but keeps its product code until it is restored or purged.
 func (s *ProductService) DeleteProduct(productCode string) (bool, error) {
 	productDeleted, err := s.repo.DeleteProduct(productCode)
 	if err != nil {
 		return false, err
 	}
 
+	if s.publisher != nil && productDeleted {
+		event := &events.ProductDeletedEvent{
+			ProductEvent: events.ProductEvent{
+				ProductCode: productCode,
+			},
+		}
+		if err := s.publisher.PublishProductDeleted(event); err != nil {
+			fmt.Printf("Failed to publish ProductDeleted event: %v\n", err)
+		}
+	}
+
 	return productDeleted, nil
 }
 
+// RestoreProduct undoes a soft delete. Restoring a product that is not
+// deleted returns it unchanged.
+func (s *ProductService) RestoreProduct(productCode string) (*dto.ProductResponse, error) {
+	restored, err := s.repo.RestoreProduct(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.repo.GetProductByCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.publisher != nil && restored {
+		event := &events.ProductRestoredEvent{
+			ProductEvent: events.ProductEvent{
+				ProductCode: productCode,
+			},
+		}
+		if err := s.publisher.PublishProductRestored(event); err != nil {
+			fmt.Printf("Failed to publish ProductRestored event: %v\n", err)
+		}
+	}
+
+	return s.mapProductModelToDto(product), nil
+}
+
+// assignCategory resolves the product's category by id, or by name when no
+// id is given, and stores both the id and the canonical name.
+func (p *ProductService) assignCategory(product *model.Product) error {
+	if p.categoryRepo == nil {
+		return nil
+	}
+
+	var category *model.Category
+	var err error
+	switch {
+	case product.CategoryId != nil:
+		category, err = resolveCategory(p.categoryRepo, strconv.Itoa(*product.CategoryId))
+	case product.Category != "":
+		category, err = resolveCategory(p.categoryRepo, product.Category)
+	default:
+		return fmt.Errorf("%w: category is required", ErrUnknownCategory)
+	}
+	if err != nil {
+		return err
+	}
+
+	product.CategoryId = &category.CategoryId
+	product.Category = category.Name
+	return nil
+}
+
+// changedProductFields lists the fields an update changes.
+func changedProductFields(old *model.Product, updated *model.Product) []string {
+	var fields []string
+	if updated.Name != old.Name {
+		fields = append(fields, "name")
+	}
+	if updated.Category != old.Category {
+		fields = append(fields, "category")
+	}
+	if updated.Description != old.Description {
+		fields = append(fields, "description")
+	}
+	if updated.Price != old.Price {
+		fields = append(fields, "price")
+	}
+	if updated.Stock != old.Stock {
+		fields = append(fields, "stock")
+	}
+	if updated.MaxOrderQty != old.MaxOrderQty {
+		fields = append(fields, "max_order_quantity")
+	}
+	oldAttributes, _ := json.Marshal(attributesOrEmpty(old.Attributes))
+	updatedAttributes, _ := json.Marshal(attributesOrEmpty(updated.Attributes))
+	if !bytes.Equal(oldAttributes, updatedAttributes) {
+		fields = append(fields, "attributes")
+	}
+	return fields
+}
+
+// validateProduct checks the fields of a full product update.
+func validateProduct(product *dto.UpdateProductRequest) error {
+	switch {
+	case product.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidProduct)
+	case len(product.Name) > 100:
+		return fmt.Errorf("%w: name must be at most 100 characters", ErrInvalidProduct)
+	case product.Category == "" && product.CategoryId == nil:
+		return fmt.Errorf("%w: category is required", ErrInvalidProduct)
+	case len(product.Category) > 50:
+		return fmt.Errorf("%w: category must be at most 50 characters", ErrInvalidProduct)
+	case product.Price < 0:
+		return fmt.Errorf("%w: price must not be negative", ErrInvalidProduct)
+	case product.Stock < 0:
+		return fmt.Errorf("%w: stock must not be negative", ErrInvalidProduct)
+	case product.MaxOrderQty < 0:
+		return fmt.Errorf("%w: max_order_quantity must not be negative", ErrInvalidProduct)
+	}
+	return nil
+}
+
 // Helper functions to map between DTOs and Models
 func (p *ProductService) mapProductDtoToModel(dto *dto.CreateProductRequest) *model.Product {
-	return &model.Product{
+	product := &model.Product{
 		ProductCode: dto.ProductCode,
 		Name:        dto.Name,
 		Category:    dto.Category,
+		CategoryId:  dto.CategoryId,
 		Description: dto.Description,
 		Price:       dto.Price,
+		Stock:       dto.Stock,
+		MaxOrderQty: dto.MaxOrderQty,
+		Attributes:  dto.Attributes,
+	}
+	for _, variant := range dto.Variants {
+		product.Variants = append(product.Variants, p.mapVariantDtoToModel(variant))
+	}
+	return product
+}
+
+// mapProductModelToUpdateDto returns the editable fields of a product, the
+// document that merge patches are applied to.
+func mapProductModelToUpdateDto(product *model.Product) *dto.UpdateProductRequest {
+	return &dto.UpdateProductRequest{
+		Name:        product.Name,
+		Category:    product.Category,
+		CategoryId:  product.CategoryId,
+		Description: product.Description,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		MaxOrderQty: product.MaxOrderQty,
+		Attributes:  attributesOrEmpty(product.Attributes),
 	}
 }
 
@@ -97,7 +418,18 @@ func (p *ProductService) mapProductModelToDto(product *model.Product) *dto.Produ
 		ProductCode: product.ProductCode,
 		Name:        product.Name,
 		Category:    product.Category,
+		CategoryId:  product.CategoryId,
 		Description: product.Description,
 		Price:       product.Price,
+		Stock:       product.Stock,
+		MaxOrderQty: product.MaxOrderQty,
+		Attributes:  attributesOrEmpty(product.Attributes),
+		Variants:    p.mapVariantModelsToDto(product.Variants, product.Price),
+		Media:       mapMediaModelsToDto(product.Media),
+		Rating: dto.RatingResponse{
+			Average: product.RatingAverage,
+			Count:   product.RatingCount,
+		},
+		UpdatedAt: product.UpdatedAt,
 	}
 }
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+// newPublishingTestService returns a product service that publishes to an
+// in-memory broker, with every catalog event routed to the "catalog-events"
+// queue.
+func newPublishingTestService(t *testing.T) (*ProductService, *rabbitmq.InMemoryBroker) {
+	t.Helper()
+
+	broker := rabbitmq.NewInMemoryBroker(clock.Real(), logger.NewLogger())
+	publisher, err := messaging.NewPublisher(broker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.DeclareQueue("catalog-events")
+	broker.BindQueue("catalog-events", events.CatalogExchange, "product.*")
+
+	return NewProductService(repository.NewMockProductRepository(), nil, publisher), broker
+}
+
+func TestProductCreatedEventIsPublished(t *testing.T) {
+	s, broker := newPublishingTestService(t)
+
+	if _, err := s.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "MUG", Name: "Mug", Category: "Home", Description: "Ceramic mug", Price: 8, Stock: 40,
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	messages := broker.Messages("catalog-events")
+	if len(messages) != 1 || messages[0].RoutingKey != events.ProductCreated {
+		t.Fatalf("Expected one product.created event, got %+v", messages)
+	}
+
+	var created events.Envelope[events.ProductCreatedEvent]
+	if err := json.Unmarshal(messages[0].Body, &created); err != nil {
+		t.Fatalf("Expected a product.created envelope, got %v", err)
+	}
+	if created.EventType != events.ProductCreated || created.Producer != "catalog-service" || created.EventID == "" {
+		t.Errorf("Expected catalog-service metadata, got %+v", created.Metadata)
+	}
+	want := events.ProductCreatedEvent{
+		ProductEvent: events.ProductEvent{ProductCode: "MUG"},
+		Name:         "Mug",
+		Category:     "Home",
+		Description:  "Ceramic mug",
+		Price:        8,
+		Stock:        40,
+	}
+	if created.Data != want {
+		t.Errorf("Expected %+v, got %+v", want, created.Data)
+	}
+}
+
+func TestProductUpdatedEventListsChangedFields(t *testing.T) {
+	s, broker := newPublishingTestService(t)
+	s.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "MUG", Name: "Mug", Category: "Home", Description: "Ceramic mug", Price: 8, Stock: 40,
+	})
+
+	if _, err := s.PatchProduct("MUG", []byte(`{"name": "Large mug", "price": 9.5}`), nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// A patch that leaves every field unchanged is not announced
+	if _, err := s.PatchProduct("MUG", []byte(`{"stock": 40}`), nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	messages := broker.Messages("catalog-events")
+	if len(messages) != 2 || messages[1].RoutingKey != events.ProductUpdated {
+		t.Fatalf("Expected product.created and a single product.updated event, got %d messages", len(messages))
+	}
+
+	var updated events.Envelope[events.ProductUpdatedEvent]
+	if err := json.Unmarshal(messages[1].Body, &updated); err != nil {
+		t.Fatalf("Expected a product.updated envelope, got %v", err)
+	}
+	if updated.EventType != events.ProductUpdated || updated.Data.ProductCode != "MUG" {
+		t.Errorf("Expected a product.updated event for MUG, got %+v", updated)
+	}
+	if !slices.Equal(updated.Data.ChangedFields, []string{"name", "price"}) {
+		t.Errorf("Expected name and price to have changed, got %v", updated.Data.ChangedFields)
+	}
+	if updated.Data.OldPrice != 8 || updated.Data.NewPrice != 9.5 {
+		t.Errorf("Expected the price to change from 8 to 9.5, got %v to %v", updated.Data.OldPrice, updated.Data.NewPrice)
+	}
+}
+
+func TestProductDeletedEventIsPublishedOnce(t *testing.T) {
+	s, broker := newPublishingTestService(t)
+	s.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "MUG", Name: "Mug", Category: "Home", Description: "Ceramic mug", Price: 8,
+	})
+
+	if deleted, err := s.DeleteProduct("MUG"); err != nil || !deleted {
+		t.Fatalf("Expected MUG to be deleted, got %v, %v", deleted, err)
+	}
+	if deleted, _ := s.DeleteProduct("MUG"); deleted {
+		t.Fatal("Expected the second delete to find nothing")
+	}
+
+	messages := broker.Messages("catalog-events")
+	if len(messages) != 2 || messages[1].RoutingKey != events.ProductDeleted {
+		t.Fatalf("Expected product.created and a single product.deleted event, got %d messages", len(messages))
+	}
+
+	var deleted events.Envelope[events.ProductDeletedEvent]
+	if err := json.Unmarshal(messages[1].Body, &deleted); err != nil {
+		t.Fatalf("Expected a product.deleted envelope, got %v", err)
+	}
+	if deleted.EventType != events.ProductDeleted || deleted.Data.ProductCode != "MUG" {
+		t.Errorf("Expected a product.deleted event for MUG, got %+v", deleted)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/storage"
+)
+
+// ProductPurger periodically removes products that have been soft deleted
+// for longer than the retention period, together with their stored media.
+type ProductPurger struct {
+	repo      repository.IProductRepository
+	blobs     storage.IBlobStorage
+	retention time.Duration
+	interval  time.Duration
+	log       logger.Logger
+	now       func() time.Time
+	stop      chan struct{}
+	done      sync.WaitGroup
+}
+
+// NewProductPurger creates a purger. When blobs is nil, media files of purged
+// products are left in place.
+func NewProductPurger(repo repository.IProductRepository, blobs storage.IBlobStorage, retention time.Duration, interval time.Duration, log logger.Logger) *ProductPurger {
+	return &ProductPurger{
+		repo:      repo,
+		blobs:     blobs,
+		retention: retention,
+		interval:  interval,
+		log:       log,
+		now:       time.Now,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start runs a purge immediately and then once per interval until Stop is
+// called.
+func (p *ProductPurger) Start() {
+	p.log.Info("Starting product purger", "retention", p.retention.String(), "interval", p.interval.String())
+
+	p.done.Add(1)
+	go func() {
+		defer p.done.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := p.Purge(); err != nil {
+				p.log.Error("Failed to purge deleted products", "error", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (p *ProductPurger) Stop() {
+	close(p.stop)
+	p.done.Wait()
+}
+
+// Purge removes products deleted before now minus the retention period and
+// returns how many were removed.
+func (p *ProductPurger) Purge() (int, error) {
+	purged, err := p.repo.PurgeDeletedProducts(p.now().Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, product := range purged {
+		if p.blobs == nil {
+			break
+		}
+		for _, media := range product.Media {
+			for _, key := range mediaStorageKeys(media) {
+				if err := p.blobs.Delete(key); err != nil {
+					p.log.Warn("Failed to delete media of purged product", "product_code", product.ProductCode, "key", key, "error", err)
+				}
+			}
+		}
+	}
+
+	if len(purged) > 0 {
+		p.log.Info("Purged deleted products", "count", len(purged))
+	}
+	return len(purged), nil
+}
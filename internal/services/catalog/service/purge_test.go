@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/storage"
+)
+
+func TestDeletedProductsAreHiddenUntilRestored(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	deleted, err := s.DeleteProduct("MUG")
+	if err != nil || !deleted {
+		t.Fatalf("Expected MUG to be deleted, got %v, %v", deleted, err)
+	}
+
+	if _, err := s.GetProductByCode("MUG"); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+	if all, _ := s.GetAllProducts(); len(all) != 1 {
+		t.Errorf("Expected 1 listed product, got %d", len(all))
+	}
+	if all, _ := s.FilterProducts(repository.ProductFilter{IncludeDeleted: true}); len(all) != 2 {
+		t.Errorf("Expected 2 products including deleted ones, got %d", len(all))
+	}
+	if home, _ := s.GetProductsByCategory("Home", false, repository.ProductFilter{IncludeDeleted: true}); len(home) != 1 {
+		t.Errorf("Expected the deleted product in its category with include_deleted, got %d", len(home))
+	}
+	if deleted, _ := s.DeleteProduct("MUG"); deleted {
+		t.Error("Expected deleting an already deleted product to report false")
+	}
+
+	restored, err := s.RestoreProduct("MUG")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if restored.ProductCode != "MUG" {
+		t.Errorf("Expected MUG to be restored, got %s", restored.ProductCode)
+	}
+	if _, err := s.GetProductByCode("MUG"); err != nil {
+		t.Errorf("Expected restored product to be found, got %v", err)
+	}
+
+	if _, err := s.RestoreProduct("MISSING"); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestDeletedProductVariantsAreNotSold(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	s.DeleteProduct("TSHIRT")
+
+	if _, err := s.GetVariantBySKU("TSHIRT-M-RED"); !errors.Is(err, repository.ErrVariantNotFound) {
+		t.Errorf("Expected ErrVariantNotFound, got %v", err)
+	}
+}
+
+func TestPurgeRemovesProductsAfterRetention(t *testing.T) {
+	media, products, blobs := newMediaTestService(t, 1<<20)
+	repo := media.repo
+
+	uploaded, err := media.UploadMedia("LAMP", bytes.NewReader(testPNG(t, 20, 20)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	products.DeleteProduct("LAMP")
+
+	purger := NewProductPurger(repo, blobs, 30*24*time.Hour, time.Hour, logger.NewLogger())
+
+	purger.now = func() time.Time { return time.Now().Add(29 * 24 * time.Hour) }
+	if count, err := purger.Purge(); err != nil || count != 0 {
+		t.Fatalf("Expected nothing to be purged within retention, got %d, %v", count, err)
+	}
+
+	purger.now = func() time.Time { return time.Now().Add(31 * 24 * time.Hour) }
+	if count, err := purger.Purge(); err != nil || count != 1 {
+		t.Fatalf("Expected 1 product to be purged, got %d, %v", count, err)
+	}
+
+	if _, err := products.RestoreProduct("LAMP"); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected purged product to be gone, got %v", err)
+	}
+	if _, err := blobs.Open(strings.TrimPrefix(uploaded.URL, "/media/")); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("Expected media of purged product to be removed, got %v", err)
+	}
+
+	if _, err := products.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "LAMP", Name: "Floor Lamp", Category: "Home", Description: "Tall", Price: 89,
+	}); err != nil {
+		t.Errorf("Expected the product code to be reusable after purge, got %v", err)
+	}
+}
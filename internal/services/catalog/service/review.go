@@ -0,0 +1,252 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/orders"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+const (
+	DefaultReviewPageSize = 20
+	MaxReviewPageSize     = 100
+)
+
+var (
+	ErrInvalidReview    = errors.New("invalid review")
+	ErrReviewNotAllowed = errors.New("only customers who received the product can review it")
+	ErrInvalidVote      = errors.New("invalid vote")
+)
+
+type IReviewService interface {
+	CreateReview(productCode string, reviewReq *dto.CreateReviewRequest) (*dto.ReviewResponse, error)
+	GetProductReviews(productCode string, page int, pageSize int, sort enums.ReviewSort) (*dto.ReviewPage, error)
+	GetReviews(status enums.ReviewStatus, page int, pageSize int) (*dto.ReviewPage, error)
+	GetReview(reviewId int) (*dto.ReviewResponse, error)
+	ModerateReview(reviewId int, moderateReq *dto.ModerateReviewRequest) (*dto.ReviewResponse, error)
+	AddHelpfulVote(reviewId int, userId string) (*dto.ReviewResponse, error)
+	RemoveHelpfulVote(reviewId int, userId string) (*dto.ReviewResponse, error)
+}
+
+// ReviewService manages product reviews. New reviews wait for moderation and
+// only approved reviews are listed under the product and counted in its
+// rating.
+type ReviewService struct {
+	repo        repository.IReviewRepository
+	products    repository.IProductRepository
+	orderClient orders.IOrderClient
+	publisher   *messaging.Publisher
+}
+
+func NewReviewService(repo repository.IReviewRepository, products repository.IProductRepository, orderClient orders.IOrderClient, publisher *messaging.Publisher) *ReviewService {
+	return &ReviewService{
+		repo:        repo,
+		products:    products,
+		orderClient: orderClient,
+		publisher:   publisher,
+	}
+}
+
+// CreateReview accepts a review from a user with a delivered order of the
+// product. Each user can review a product once.
+func (s *ReviewService) CreateReview(productCode string, reviewReq *dto.CreateReviewRequest) (*dto.ReviewResponse, error) {
+	reviewReq.Title = strings.TrimSpace(reviewReq.Title)
+	reviewReq.Body = strings.TrimSpace(reviewReq.Body)
+	if err := validateReview(reviewReq); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.products.GetProductByCode(productCode); err != nil {
+		return nil, err
+	}
+
+	delivered, err := s.orderClient.HasDeliveredProduct(reviewReq.UserId, productCode)
+	if err != nil {
+		return nil, err
+	}
+	if !delivered {
+		return nil, ErrReviewNotAllowed
+	}
+
+	review, err := s.repo.CreateReview(productCode, &model.Review{
+		UserId: reviewReq.UserId,
+		Rating: reviewReq.Rating,
+		Title:  reviewReq.Title,
+		Body:   reviewReq.Body,
+		Status: enums.ReviewStatusPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapReviewModelToDto(review)
+	response.ProductCode = productCode
+	return response, nil
+}
+
+// GetProductReviews lists the approved reviews of a product.
+func (s *ReviewService) GetProductReviews(productCode string, page int, pageSize int, sort enums.ReviewSort) (*dto.ReviewPage, error) {
+	if _, err := s.products.GetProductByCode(productCode); err != nil {
+		return nil, err
+	}
+
+	return s.getReviews(repository.ReviewFilter{
+		ProductCode: productCode,
+		Status:      enums.ReviewStatusApproved,
+		Sort:        sort,
+	}, page, pageSize)
+}
+
+// GetReviews lists the reviews of all products, optionally by status, such as
+// the pending reviews awaiting moderation.
+func (s *ReviewService) GetReviews(status enums.ReviewStatus, page int, pageSize int) (*dto.ReviewPage, error) {
+	switch status {
+	case "", enums.ReviewStatusPending, enums.ReviewStatusApproved, enums.ReviewStatusRejected:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidReview, status)
+	}
+
+	return s.getReviews(repository.ReviewFilter{Status: status}, page, pageSize)
+}
+
+func (s *ReviewService) GetReview(reviewId int) (*dto.ReviewResponse, error) {
+	review, err := s.repo.GetReviewById(reviewId)
+	if err != nil {
+		return nil, err
+	}
+	return mapReviewModelToDto(review), nil
+}
+
+// ModerateReview approves or rejects a review. Approving a review, or
+// rejecting an approved one, changes the product's rating, so the product is
+// also dropped from the product cache.
+func (s *ReviewService) ModerateReview(reviewId int, moderateReq *dto.ModerateReviewRequest) (*dto.ReviewResponse, error) {
+	status := enums.ReviewStatus(moderateReq.Status)
+	if status != enums.ReviewStatusApproved && status != enums.ReviewStatusRejected {
+		return nil, fmt.Errorf("%w: status must be %q or %q", ErrInvalidReview, enums.ReviewStatusApproved, enums.ReviewStatusRejected)
+	}
+
+	previous, err := s.repo.GetReviewById(reviewId)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := s.repo.SetReviewStatus(reviewId, status, strings.TrimSpace(moderateReq.Note))
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.Status == enums.ReviewStatusApproved || status == enums.ReviewStatusApproved {
+		if cache, ok := s.products.(messaging.ProductCacheInvalidator); ok && review.Product != nil {
+			cache.InvalidateProduct(review.Product.ProductCode)
+		}
+		publishProductChanged(s.publisher, review.Product, "rating")
+	}
+
+	return mapReviewModelToDto(review), nil
+}
+
+// AddHelpfulVote marks an approved review as helpful to a user. Voting again
+// has no effect and authors cannot vote on their own reviews.
+func (s *ReviewService) AddHelpfulVote(reviewId int, userId string) (*dto.ReviewResponse, error) {
+	review, err := s.repo.GetReviewById(reviewId)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case userId == "":
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidVote)
+	case review.Status != enums.ReviewStatusApproved:
+		return nil, fmt.Errorf("%w: only approved reviews can be voted on", ErrInvalidVote)
+	case review.UserId == userId:
+		return nil, fmt.Errorf("%w: authors cannot vote on their own reviews", ErrInvalidVote)
+	}
+
+	if _, err := s.repo.AddVote(reviewId, userId); err != nil {
+		return nil, err
+	}
+	return s.GetReview(reviewId)
+}
+
+func (s *ReviewService) RemoveHelpfulVote(reviewId int, userId string) (*dto.ReviewResponse, error) {
+	if _, err := s.repo.GetReviewById(reviewId); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.RemoveVote(reviewId, userId); err != nil {
+		return nil, err
+	}
+	return s.GetReview(reviewId)
+}
+
+func (s *ReviewService) getReviews(filter repository.ReviewFilter, page int, pageSize int) (*dto.ReviewPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultReviewPageSize
+	}
+	pageSize = min(pageSize, MaxReviewPageSize)
+
+	filter.Offset = (page - 1) * pageSize
+	filter.Limit = pageSize
+	reviews, total, err := s.repo.GetReviews(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		responses[i] = mapReviewModelToDto(review)
+	}
+
+	return &dto.ReviewPage{
+		Reviews:  responses,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+func validateReview(review *dto.CreateReviewRequest) error {
+	switch {
+	case review.UserId == "":
+		return fmt.Errorf("%w: user_id is required", ErrInvalidReview)
+	case len(review.UserId) > 100:
+		return fmt.Errorf("%w: user_id must be at most 100 characters", ErrInvalidReview)
+	case review.Rating < 1 || review.Rating > 5:
+		return fmt.Errorf("%w: rating must be between 1 and 5", ErrInvalidReview)
+	case len(review.Title) > 150:
+		return fmt.Errorf("%w: title must be at most 150 characters", ErrInvalidReview)
+	case review.Body == "":
+		return fmt.Errorf("%w: body is required", ErrInvalidReview)
+	case len(review.Body) > 5000:
+		return fmt.Errorf("%w: body must be at most 5000 characters", ErrInvalidReview)
+	}
+	return nil
+}
+
+func mapReviewModelToDto(review *model.Review) *dto.ReviewResponse {
+	response := &dto.ReviewResponse{
+		ReviewId:       review.ReviewId,
+		UserId:         review.UserId,
+		Rating:         review.Rating,
+		Title:          review.Title,
+		Body:           review.Body,
+		Status:         string(review.Status),
+		ModerationNote: review.ModerationNote,
+		HelpfulCount:   review.HelpfulCount,
+		ModeratedAt:    review.ModeratedAt,
+		CreatedAt:      review.CreatedAt,
+	}
+	if review.Product != nil {
+		response.ProductCode = review.Product.ProductCode
+	}
+	return response
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/enums"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/orders"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+func newReviewTestService(t *testing.T) (*ReviewService, *ProductService, *orders.MockOrderClient) {
+	t.Helper()
+
+	products, productRepo := newVariantTestService(t)
+	orderClient := orders.NewMockOrderClient()
+	s := NewReviewService(repository.NewInMemoryReviewRepository(productRepo), productRepo, orderClient, nil)
+	return s, products, orderClient
+}
+
+// invalidatingProductRepository records the products the review service
+// drops from the product cache.
+type invalidatingProductRepository struct {
+	*repository.MockProductRepository
+	invalidated []string
+}
+
+func (repo *invalidatingProductRepository) InvalidateProduct(productCode string) {
+	repo.invalidated = append(repo.invalidated, productCode)
+}
+
+// createApprovedReview posts a review from a customer who received the
+// product and approves it.
+func createApprovedReview(t *testing.T, s *ReviewService, orderClient *orders.MockOrderClient, productCode string, userId string, rating int) *dto.ReviewResponse {
+	t.Helper()
+
+	orderClient.SetDelivered(userId, productCode)
+	review, err := s.CreateReview(productCode, &dto.CreateReviewRequest{UserId: userId, Rating: rating, Body: "Review by " + userId})
+	if err != nil {
+		t.Fatalf("Expected no error creating review, got %v", err)
+	}
+	review, err = s.ModerateReview(review.ReviewId, &dto.ModerateReviewRequest{Status: "approved"})
+	if err != nil {
+		t.Fatalf("Expected no error approving review, got %v", err)
+	}
+	return review
+}
+
+func TestModerateReviewInvalidatesCachedProduct(t *testing.T) {
+	_, productRepo := newVariantTestService(t)
+	cache := &invalidatingProductRepository{MockProductRepository: productRepo}
+	orderClient := orders.NewMockOrderClient()
+	s := NewReviewService(repository.NewInMemoryReviewRepository(productRepo), cache, orderClient, nil)
+
+	orderClient.SetDelivered("user-1", "TSHIRT")
+	review, err := s.CreateReview("TSHIRT", &dto.CreateReviewRequest{UserId: "user-1", Rating: 4, Body: "Fits well"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cache.invalidated) != 0 {
+		t.Fatalf("Expected a pending review to leave the cache alone, got %v", cache.invalidated)
+	}
+
+	if _, err := s.ModerateReview(review.ReviewId, &dto.ModerateReviewRequest{Status: "approved"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.ModerateReview(review.ReviewId, &dto.ModerateReviewRequest{Status: "rejected"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cache.invalidated) != 2 || cache.invalidated[0] != "TSHIRT" || cache.invalidated[1] != "TSHIRT" {
+		t.Errorf("Expected TSHIRT to be invalidated after each rating change, got %v", cache.invalidated)
+	}
+}
+
+func TestCreateReviewRequiresDeliveredOrder(t *testing.T) {
+	s, _, orderClient := newReviewTestService(t)
+	req := &dto.CreateReviewRequest{UserId: "user-1", Rating: 5, Title: "Great", Body: "Keeps coffee hot"}
+
+	if _, err := s.CreateReview("MUG", req); !errors.Is(err, ErrReviewNotAllowed) {
+		t.Fatalf("Expected ErrReviewNotAllowed, got %v", err)
+	}
+
+	orderClient.SetDelivered("user-1", "MUG")
+	review, err := s.CreateReview("MUG", req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if review.Status != "pending" || review.ProductCode != "MUG" || review.Rating != 5 {
+		t.Errorf("Expected a pending 5 star review of MUG, got %+v", review)
+	}
+
+	if _, err := s.CreateReview("MUG", req); !errors.Is(err, repository.ErrDuplicateReview) {
+		t.Errorf("Expected ErrDuplicateReview, got %v", err)
+	}
+
+	if _, err := s.CreateReview("MISSING", req); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+
+	orderClient.SetError(orders.ErrUnavailable)
+	if _, err := s.CreateReview("TSHIRT", req); !errors.Is(err, orders.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestCreateReviewValidation(t *testing.T) {
+	s, _, orderClient := newReviewTestService(t)
+	orderClient.SetDelivered("user-1", "MUG")
+
+	tests := []*dto.CreateReviewRequest{
+		{Rating: 5, Body: "No user"},
+		{UserId: "user-1", Rating: 0, Body: "Too low"},
+		{UserId: "user-1", Rating: 6, Body: "Too high"},
+		{UserId: "user-1", Rating: 4, Body: "   "},
+	}
+	for _, req := range tests {
+		if _, err := s.CreateReview("MUG", req); !errors.Is(err, ErrInvalidReview) {
+			t.Errorf("Expected ErrInvalidReview for %+v, got %v", req, err)
+		}
+	}
+}
+
+func TestModerationMaintainsProductRating(t *testing.T) {
+	s, products, orderClient := newReviewTestService(t)
+
+	first := createApprovedReview(t, s, orderClient, "MUG", "user-1", 5)
+	createApprovedReview(t, s, orderClient, "MUG", "user-2", 4)
+	createApprovedReview(t, s, orderClient, "MUG", "user-3", 4)
+
+	orderClient.SetDelivered("user-4", "MUG")
+	s.CreateReview("MUG", &dto.CreateReviewRequest{UserId: "user-4", Rating: 1, Body: "Still pending"})
+
+	product, _ := products.GetProductByCode("MUG")
+	if product.Rating.Count != 3 || product.Rating.Average != 4.33 {
+		t.Errorf("Expected 3 ratings averaging 4.33, got %+v", product.Rating)
+	}
+
+	rejected, err := s.ModerateReview(first.ReviewId, &dto.ModerateReviewRequest{Status: "rejected", Note: "Off topic"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rejected.Status != "rejected" || rejected.ModerationNote != "Off topic" || rejected.ModeratedAt == nil {
+		t.Errorf("Expected a rejected review with a note, got %+v", rejected)
+	}
+
+	product, _ = products.GetProductByCode("MUG")
+	if product.Rating.Count != 2 || product.Rating.Average != 4 {
+		t.Errorf("Expected 2 ratings averaging 4, got %+v", product.Rating)
+	}
+
+	page, _ := s.GetProductReviews("MUG", 1, 0, "")
+	if page.Total != 2 {
+		t.Errorf("Expected only the 2 approved reviews to be listed, got %d", page.Total)
+	}
+
+	pending, _ := s.GetReviews(enums.ReviewStatusPending, 1, 0)
+	if pending.Total != 1 || pending.Reviews[0].UserId != "user-4" {
+		t.Errorf("Expected the pending review of user-4 in the moderation queue, got %+v", pending)
+	}
+
+	if _, err := s.ModerateReview(first.ReviewId, &dto.ModerateReviewRequest{Status: "pending"}); !errors.Is(err, ErrInvalidReview) {
+		t.Errorf("Expected ErrInvalidReview, got %v", err)
+	}
+	if _, err := s.ModerateReview(999, &dto.ModerateReviewRequest{Status: "approved"}); !errors.Is(err, repository.ErrReviewNotFound) {
+		t.Errorf("Expected ErrReviewNotFound, got %v", err)
+	}
+}
+
+func TestGetProductReviewsPaginatesAndSorts(t *testing.T) {
+	s, _, orderClient := newReviewTestService(t)
+
+	for i := 1; i <= 5; i++ {
+		createApprovedReview(t, s, orderClient, "MUG", fmt.Sprintf("user-%d", i), i)
+	}
+	createApprovedReview(t, s, orderClient, "TSHIRT", "user-1", 2)
+
+	page, err := s.GetProductReviews("MUG", 3, 2, enums.ReviewSortHighest)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 5 || page.Page != 3 || page.PageSize != 2 || len(page.Reviews) != 1 {
+		t.Fatalf("Expected the last page with 1 of 5 reviews, got %+v", page)
+	}
+	if page.Reviews[0].Rating != 1 {
+		t.Errorf("Expected the lowest rating last when sorting by highest, got %d", page.Reviews[0].Rating)
+	}
+
+	lowest, _ := s.GetProductReviews("MUG", 1, 2, enums.ReviewSortLowest)
+	if lowest.Reviews[0].Rating != 1 || lowest.Reviews[1].Rating != 2 {
+		t.Errorf("Expected ratings 1 and 2 first when sorting by lowest, got %+v", lowest.Reviews)
+	}
+
+	capped, _ := s.GetProductReviews("MUG", 0, 1000, "")
+	if capped.Page != 1 || capped.PageSize != MaxReviewPageSize {
+		t.Errorf("Expected page 1 with page size %d, got %d and %d", MaxReviewPageSize, capped.Page, capped.PageSize)
+	}
+
+	if _, err := s.GetProductReviews("MISSING", 1, 10, ""); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestHelpfulVotes(t *testing.T) {
+	s, _, orderClient := newReviewTestService(t)
+
+	review := createApprovedReview(t, s, orderClient, "MUG", "user-1", 5)
+	other := createApprovedReview(t, s, orderClient, "MUG", "user-2", 3)
+
+	s.AddHelpfulVote(review.ReviewId, "user-2")
+	s.AddHelpfulVote(review.ReviewId, "user-3")
+	voted, err := s.AddHelpfulVote(review.ReviewId, "user-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if voted.HelpfulCount != 2 {
+		t.Errorf("Expected 2 helpful votes after a repeated vote, got %d", voted.HelpfulCount)
+	}
+
+	if _, err := s.AddHelpfulVote(review.ReviewId, "user-1"); !errors.Is(err, ErrInvalidVote) {
+		t.Errorf("Expected ErrInvalidVote for a vote on the author's own review, got %v", err)
+	}
+
+	helpful, _ := s.GetProductReviews("MUG", 1, 10, enums.ReviewSortHelpful)
+	if helpful.Reviews[0].ReviewId != review.ReviewId || helpful.Reviews[1].ReviewId != other.ReviewId {
+		t.Errorf("Expected the most helpful review first, got %+v", helpful.Reviews)
+	}
+
+	unvoted, err := s.RemoveHelpfulVote(review.ReviewId, "user-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if unvoted.HelpfulCount != 1 {
+		t.Errorf("Expected 1 helpful vote after removing a vote, got %d", unvoted.HelpfulCount)
+	}
+
+	orderClient.SetDelivered("user-3", "MUG")
+	pending, _ := s.CreateReview("MUG", &dto.CreateReviewRequest{UserId: "user-3", Rating: 2, Body: "Pending"})
+	if _, err := s.AddHelpfulVote(pending.ReviewId, "user-2"); !errors.Is(err, ErrInvalidVote) {
+		t.Errorf("Expected ErrInvalidVote for a vote on a pending review, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"image"
+	"image/draw"
+)
+
+type thumbnailSize struct {
+	name    string
+	maxEdge int
+}
+
+// thumbnailSizes are generated for every uploaded image.
+var thumbnailSizes = []thumbnailSize{
+	{name: "small", maxEdge: 160},
+	{name: "medium", maxEdge: 480},
+	{name: "large", maxEdge: 1024},
+}
+
+// resizeToFit scales src down so that its longer edge is at most maxEdge,
+// keeping the aspect ratio. Each target pixel is the average of the source
+// pixels it covers. Images that already fit keep their size.
+func resizeToFit(src *image.RGBA, maxEdge int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	width, height := srcWidth, srcHeight
+	if longest := max(srcWidth, srcHeight); longest > maxEdge {
+		width = max(1, (srcWidth*maxEdge+longest/2)/longest)
+		height = max(1, (srcHeight*maxEdge+longest/2)/longest)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			count := (x1 - x0) * (y1 - y0)
+			offset := y*dst.Stride + x*4
+			for c := range sum {
+				dst.Pix[offset+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+	return dst
+}
+
+// toRGBA converts an image to premultiplied RGBA with its origin at (0, 0),
+// so that averaging pixels does not bleed the colour of transparent areas.
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+func TestPatchProductWritesZeroValues(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	product, err := s.PatchProduct("MUG", []byte(`{"price": 0, "stock": 0, "description": null}`), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if product.Price != 0 {
+		t.Errorf("Expected price 0, got %.2f", product.Price)
+	}
+	if product.Description != "" {
+		t.Errorf("Expected description to be cleared, got %q", product.Description)
+	}
+	if product.Name != "Mug" || product.Category != "Home" {
+		t.Errorf("Expected fields missing from the patch to be kept, got %+v", product)
+	}
+
+	history, _ := s.GetPriceHistory("MUG", 0)
+	if len(history) != 1 || history[0].NewPrice != 0 {
+		t.Errorf("Expected the change to price 0 to be recorded, got %+v", history)
+	}
+}
+
+func TestPatchProductMergesAttributes(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	product, err := s.PatchProduct("MUG", []byte(`{"attributes": {"dishwasher_safe": null, "colour": "white"}}`), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := product.Attributes["dishwasher_safe"]; ok {
+		t.Errorf("Expected dishwasher_safe to be removed, got %v", product.Attributes)
+	}
+	if product.Attributes["colour"] != "white" || product.Attributes["capacity_ml"] == nil {
+		t.Errorf("Expected colour to be added and capacity_ml kept, got %v", product.Attributes)
+	}
+}
+
+func TestPatchProductKeepsVariants(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	product, err := s.PatchProduct("TSHIRT", []byte(`{"stock": 12}`), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if product.Stock != 12 || len(product.Variants) != 2 {
+		t.Errorf("Expected stock 12 and 2 variants, got stock %d and %d variants", product.Stock, len(product.Variants))
+	}
+}
+
+func TestPatchProductRejectsInvalidPatches(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	tests := []string{
+		`[]`,
+		`{"price": "free"}`,
+		`{"price": -1}`,
+		`{"name": null}`,
+		`{"product_code": "CUP"}`,
+		`not json`,
+	}
+	for _, patch := range tests {
+		if _, err := s.PatchProduct("MUG", []byte(patch), nil); !errors.Is(err, ErrInvalidProduct) {
+			t.Errorf("Expected ErrInvalidProduct for %s, got %v", patch, err)
+		}
+	}
+
+	product, _ := s.GetProductByCode("MUG")
+	if product.Price != 8 || product.Name != "Mug" {
+		t.Errorf("Expected rejected patches to leave the product unchanged, got %+v", product)
+	}
+
+	if _, err := s.PatchProduct("MISSING", []byte(`{}`), nil); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestUpdateProductReplacesAllFields(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	product, err := s.UpdateProduct("MUG", &dto.UpdateProductRequest{Name: "Mug", Category: "Home", Price: 8}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if product.Description != "" || len(product.Attributes) != 0 {
+		t.Errorf("Expected omitted fields to be reset, got %+v", product)
+	}
+	if product.ProductCode != "MUG" {
+		t.Errorf("Expected product code MUG, got %s", product.ProductCode)
+	}
+}
+
+func TestUpdateProductPathCodeIsAuthoritative(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	_, err := s.UpdateProduct("MUG", &dto.UpdateProductRequest{ProductCode: "TSHIRT", Name: "Mug", Category: "Home", Price: 8}, nil)
+	if !errors.Is(err, ErrInvalidProduct) {
+		t.Fatalf("Expected ErrInvalidProduct, got %v", err)
+	}
+
+	tshirt, _ := s.GetProductByCode("TSHIRT")
+	if tshirt.Name != "T-Shirt" {
+		t.Errorf("Expected TSHIRT to be untouched, got %+v", tshirt)
+	}
+
+	if _, err := s.UpdateProduct("MUG", &dto.UpdateProductRequest{ProductCode: "MUG", Name: "Mug", Category: "Home"}, nil); err != nil {
+		t.Errorf("Expected a matching product code to be accepted, got %v", err)
+	}
+}
+
+func TestUpdateProductRejectsStaleVersion(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	read, _ := s.GetProductByCode("MUG")
+	version := read.UpdatedAt
+	if _, err := s.PatchProduct("MUG", []byte(`{"stock": 3}`), &version); err != nil {
+		t.Fatalf("Expected the current version to be accepted, got %v", err)
+	}
+
+	// A second writer still holding the first read loses
+	_, err := s.UpdateProduct("MUG", &dto.UpdateProductRequest{Name: "Mug", Category: "Home", Price: 8}, &version)
+	if !errors.Is(err, repository.ErrProductModified) {
+		t.Fatalf("Expected ErrProductModified, got %v", err)
+	}
+	if product, _ := s.GetProductByCode("MUG"); product.Stock != 3 {
+		t.Errorf("Expected the stale update to be rejected, got %+v", product)
+	}
+}
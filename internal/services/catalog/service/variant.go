@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/messaging"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/model"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+var ErrInvalidVariant = errors.New("invalid variant")
+
+func (p *ProductService) GetVariantBySKU(sku string) (*dto.VariantDetailResponse, error) {
+	variant, err := p.repo.GetVariantBySKU(sku)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.VariantDetailResponse{
+		ProductCode:     variant.Product.ProductCode,
+		Name:            variant.Product.Name,
+		Category:        variant.Product.Category,
+		MaxOrderQty:     variant.Product.MaxOrderQty,
+		VariantResponse: p.mapVariantModelToDto(variant, variant.Product.Price),
+	}, nil
+}
+
+func (p *ProductService) CreateVariant(productCode string, variantReq *dto.VariantRequest) (*dto.VariantResponse, error) {
+	if err := validateVariant(variantReq); err != nil {
+		return nil, err
+	}
+
+	product, err := p.repo.GetProductByCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := p.repo.CreateVariant(productCode, p.mapVariantDtoToModel(variantReq))
+	if err != nil {
+		return nil, err
+	}
+
+	publishProductChanged(p.publisher, product, "variants")
+
+	response := p.mapVariantModelToDto(variant, product.Price)
+	return &response, nil
+}
+
+// UpdateVariant replaces the variant's price override, stock and attributes.
+// The SKU in the path is authoritative.
+func (p *ProductService) UpdateVariant(sku string, variantReq *dto.VariantRequest) (*dto.VariantResponse, error) {
+	variantReq.SKU = sku
+	if err := validateVariant(variantReq); err != nil {
+		return nil, err
+	}
+
+	existing, err := p.repo.GetVariantBySKU(sku)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := p.repo.UpdateVariant(p.mapVariantDtoToModel(variantReq))
+	if err != nil {
+		return nil, err
+	}
+
+	publishProductChanged(p.publisher, existing.Product, "variants")
+
+	response := p.mapVariantModelToDto(variant, existing.Product.Price)
+	return &response, nil
+}
+
+func (p *ProductService) DeleteVariant(sku string) (bool, error) {
+	existing, err := p.repo.GetVariantBySKU(sku)
+	if errors.Is(err, repository.ErrVariantNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := p.repo.DeleteVariant(sku)
+	if err != nil {
+		return false, err
+	}
+
+	if deleted {
+		publishProductChanged(p.publisher, existing.Product, "variants")
+	}
+	return deleted, nil
+}
+
+// publishProductChanged announces a change to data nested in the product
+// representation, such as its variants or media, as an update of the product
+// so that caches of the product are dropped.
+func publishProductChanged(publisher *messaging.Publisher, product *model.Product, field string) {
+	if publisher == nil || product == nil {
+		return
+	}
+
+	event := &events.ProductUpdatedEvent{
+		ProductEvent: events.ProductEvent{
+			ProductCode: product.ProductCode,
+		},
+		ChangedFields: []string{field},
+		OldPrice:      product.Price,
+		NewPrice:      product.Price,
+	}
+	if err := publisher.PublishProductUpdated(event); err != nil {
+		fmt.Printf("Failed to publish ProductUpdated event: %v\n", err)
+	}
+}
+
+func validateVariant(variant *dto.VariantRequest) error {
+	switch {
+	case variant.SKU == "":
+		return fmt.Errorf("%w: sku is required", ErrInvalidVariant)
+	case len(variant.SKU) > 40:
+		return fmt.Errorf("%w: sku must be at most 40 characters", ErrInvalidVariant)
+	case variant.Price != nil && *variant.Price < 0:
+		return fmt.Errorf("%w: price must not be negative", ErrInvalidVariant)
+	case variant.Stock < 0:
+		return fmt.Errorf("%w: stock must not be negative", ErrInvalidVariant)
+	}
+	return nil
+}
+
+func (p *ProductService) mapVariantDtoToModel(variant *dto.VariantRequest) *model.ProductVariant {
+	return &model.ProductVariant{
+		SKU:        variant.SKU,
+		Price:      variant.Price,
+		Stock:      variant.Stock,
+		Attributes: variant.Attributes,
+	}
+}
+
+func (p *ProductService) mapVariantModelToDto(variant *model.ProductVariant, parentPrice float64) dto.VariantResponse {
+	return dto.VariantResponse{
+		SKU:        variant.SKU,
+		Price:      variant.EffectivePrice(parentPrice),
+		Stock:      variant.Stock,
+		Attributes: attributesOrEmpty(variant.Attributes),
+	}
+}
+
+func (p *ProductService) mapVariantModelsToDto(variants []*model.ProductVariant, parentPrice float64) []*dto.VariantResponse {
+	responses := make([]*dto.VariantResponse, len(variants))
+	for i, variant := range variants {
+		response := p.mapVariantModelToDto(variant, parentPrice)
+		responses[i] = &response
+	}
+	return responses
+}
+
+func attributesOrEmpty(attributes map[string]any) map[string]any {
+	if attributes == nil {
+		return map[string]any{}
+	}
+	return attributes
+}
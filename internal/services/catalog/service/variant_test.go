@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/services/catalog/dto"
+	"github.com/dinosgnk/agora-project/internal/services/catalog/repository"
+)
+
+func newVariantTestService(t *testing.T) (*ProductService, *repository.MockProductRepository) {
+	t.Helper()
+
+	repo := repository.NewMockProductRepository()
+	s := NewProductService(repo, nil, nil)
+
+	override := 24.99
+	_, err := s.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "TSHIRT",
+		Name:        "T-Shirt",
+		Category:    "Clothing",
+		Description: "Cotton t-shirt",
+		Price:       19.99,
+		MaxOrderQty: 5,
+		Attributes:  map[string]any{"material": "cotton"},
+		Variants: []*dto.VariantRequest{
+			{SKU: "TSHIRT-M-RED", Stock: 3, Attributes: map[string]any{"size": "M", "colour": "red"}},
+			{SKU: "TSHIRT-XL-RED", Price: &override, Stock: 1, Attributes: map[string]any{"size": "XL", "colour": "red"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	s.CreateProduct(&dto.CreateProductRequest{
+		ProductCode: "MUG",
+		Name:        "Mug",
+		Category:    "Home",
+		Description: "Ceramic mug",
+		Price:       8,
+		Attributes:  map[string]any{"capacity_ml": 350, "dishwasher_safe": true},
+	})
+
+	return s, repo
+}
+
+func TestGetProductByCodeIncludesVariantsWithEffectivePrice(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	product, err := s.GetProductByCode("TSHIRT")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(product.Variants) != 2 {
+		t.Fatalf("Expected 2 variants, got %d", len(product.Variants))
+	}
+	if product.Variants[0].Price != 19.99 {
+		t.Errorf("Expected inherited price 19.99, got %.2f", product.Variants[0].Price)
+	}
+	if product.Variants[1].Price != 24.99 {
+		t.Errorf("Expected override price 24.99, got %.2f", product.Variants[1].Price)
+	}
+	if product.Attributes["material"] != "cotton" {
+		t.Errorf("Expected material attribute, got %v", product.Attributes)
+	}
+}
+
+func TestGetVariantBySKUIncludesParentDetails(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	variant, err := s.GetVariantBySKU("TSHIRT-XL-RED")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if variant.ProductCode != "TSHIRT" || variant.Name != "T-Shirt" || variant.MaxOrderQty != 5 {
+		t.Errorf("Unexpected parent details %+v", variant)
+	}
+	if variant.Price != 24.99 || variant.Stock != 1 || variant.Attributes["size"] != "XL" {
+		t.Errorf("Unexpected variant details %+v", variant.VariantResponse)
+	}
+
+	if _, err := s.GetVariantBySKU("MISSING"); !errors.Is(err, repository.ErrVariantNotFound) {
+		t.Errorf("Expected ErrVariantNotFound, got %v", err)
+	}
+}
+
+func TestFilterProductsMatchesProductAndVariantAttributes(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	tests := []struct {
+		name     string
+		filter   repository.ProductFilter
+		expected int
+	}{
+		{"variant attribute", repository.ProductFilter{Attributes: map[string]string{"size": "XL"}}, 1},
+		{"product attribute", repository.ProductFilter{Attributes: map[string]string{"material": "cotton"}}, 1},
+		{"typed number and boolean", repository.ProductFilter{Attributes: map[string]string{"capacity_ml": "350", "dishwasher_safe": "true"}}, 1},
+		{"category and attribute", repository.ProductFilter{Category: "Home", Attributes: map[string]string{"size": "M"}}, 0},
+		{"no match", repository.ProductFilter{Attributes: map[string]string{"colour": "blue"}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products, err := s.FilterProducts(tt.filter)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(products) != tt.expected {
+				t.Errorf("Expected %d products, got %d", tt.expected, len(products))
+			}
+		})
+	}
+}
+
+func TestVariantLifecycle(t *testing.T) {
+	s, _ := newVariantTestService(t)
+
+	if _, err := s.CreateVariant("TSHIRT", &dto.VariantRequest{SKU: "TSHIRT-S-BLUE", Stock: -1}); !errors.Is(err, ErrInvalidVariant) {
+		t.Errorf("Expected ErrInvalidVariant, got %v", err)
+	}
+	if _, err := s.CreateVariant("MISSING", &dto.VariantRequest{SKU: "X-1"}); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+
+	created, err := s.CreateVariant("TSHIRT", &dto.VariantRequest{SKU: "TSHIRT-S-BLUE", Stock: 4, Attributes: map[string]any{"size": "S"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.Price != 19.99 {
+		t.Errorf("Expected inherited price 19.99, got %.2f", created.Price)
+	}
+
+	price := 17.5
+	updated, err := s.UpdateVariant("TSHIRT-S-BLUE", &dto.VariantRequest{Price: &price, Stock: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Price != 17.5 || updated.Stock != 2 {
+		t.Errorf("Unexpected updated variant %+v", updated)
+	}
+
+	deleted, err := s.DeleteVariant("TSHIRT-S-BLUE")
+	if err != nil || !deleted {
+		t.Fatalf("Expected variant to be deleted, got %v, %v", deleted, err)
+	}
+	if deleted, _ := s.DeleteVariant("TSHIRT-S-BLUE"); deleted {
+		t.Errorf("Expected second delete to report not found")
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalFileStorage keeps blobs as files below a root directory.
+type LocalFileStorage struct {
+	root string
+}
+
+func NewLocalFileStorage(root string) (*LocalFileStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory %s: %w", root, err)
+	}
+	return &LocalFileStorage{
+		root: root,
+	}, nil
+}
+
+// Put writes to a temporary file first and renames it into place, so readers
+// never observe a partially written blob.
+func (s *LocalFileStorage) Put(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalFileStorage) Open(key string) (Blob, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, ErrBlobNotFound
+	}
+
+	return &localBlob{File: file, info: info}, nil
+}
+
+// Delete removes a blob. Deleting a missing blob is not an error.
+func (s *LocalFileStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps a key to a file below the root, rejecting keys that would
+// escape it.
+func (s *LocalFileStorage) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+type localBlob struct {
+	*os.File
+	info fs.FileInfo
+}
+
+func (b *localBlob) Size() int64 {
+	return b.info.Size()
+}
+
+func (b *localBlob) ModTime() time.Time {
+	return b.info.ModTime()
+}
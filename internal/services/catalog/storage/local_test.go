@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalFileStoragePutOpenDelete(t *testing.T) {
+	s, err := NewLocalFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := s.Put("products/P-1/a/original.png", strings.NewReader("image bytes")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	blob, err := s.Open("products/P-1/a/original.png")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	content, _ := io.ReadAll(blob)
+	blob.Close()
+	if string(content) != "image bytes" || blob.Size() != int64(len("image bytes")) {
+		t.Errorf("Expected stored content, got %q (%d bytes)", content, blob.Size())
+	}
+
+	if err := s.Delete("products/P-1/a/original.png"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.Open("products/P-1/a/original.png"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Expected ErrBlobNotFound, got %v", err)
+	}
+	if err := s.Delete("products/P-1/a/original.png"); err != nil {
+		t.Errorf("Expected deleting a missing blob to succeed, got %v", err)
+	}
+}
+
+func TestLocalFileStorageRejectsKeysOutsideRoot(t *testing.T) {
+	s, _ := NewLocalFileStorage(t.TempDir())
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", "products/../../secret"} {
+		if err := s.Put(key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey for %q, got %v", key, err)
+		}
+		if _, err := s.Open(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey opening %q, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrBlobNotFound = errors.New("blob not found")
+	ErrInvalidKey   = errors.New("invalid blob key")
+)
+
+// Blob is an open stored object. It is seekable so that it can be served
+// with range and conditional request support.
+type Blob interface {
+	io.ReadSeekCloser
+	Size() int64
+	ModTime() time.Time
+}
+
+// IBlobStorage stores opaque blobs under slash-separated keys such as
+// "products/ABC-1/<id>/original.jpg".
+type IBlobStorage interface {
+	Put(key string, r io.Reader) error
+	Open(key string) (Blob, error)
+	Delete(key string) error
+}
@@ -0,0 +1,19 @@
+// Package channel delivers rendered notifications.
+package channel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+// ErrRejected means the channel refused the notification, so sending it
+// again would fail the same way.
+var ErrRejected = errors.New("notification was rejected")
+
+type Channel interface {
+	// Name identifies the channel in delivery records
+	Name() string
+	Send(ctx context.Context, notification *model.Notification) error
+}
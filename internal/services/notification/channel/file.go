@@ -0,0 +1,51 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+// FileChannel appends notifications to a file as JSON lines, which stands in
+// for real channels in development and tests.
+type FileChannel struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileChannel(path string) (*FileChannel, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &FileChannel{path: path}, nil
+}
+
+func (c *FileChannel) Name() string {
+	return "file"
+}
+
+func (c *FileChannel) Send(_ context.Context, notification *model.Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open notification file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
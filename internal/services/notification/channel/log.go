@@ -0,0 +1,32 @@
+package channel
+
+import (
+	"context"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+// LogChannel logs notifications instead of sending them, for development.
+type LogChannel struct {
+	log logger.Logger
+}
+
+func NewLogChannel(log logger.Logger) *LogChannel {
+	return &LogChannel{log: log}
+}
+
+func (c *LogChannel) Name() string {
+	return "log"
+}
+
+func (c *LogChannel) Send(_ context.Context, notification *model.Notification) error {
+	c.log.Info("Notification",
+		"event_id", notification.EventID,
+		"event_type", notification.EventType,
+		"recipient", notification.Recipient,
+		"subject", notification.Subject,
+		"text", notification.Text,
+	)
+	return nil
+}
@@ -0,0 +1,168 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPChannel emails notifications as multipart messages with a plain text
+// and an HTML alternative. It upgrades to TLS when the server offers
+// STARTTLS and authenticates when a username is configured. Permanent SMTP
+// failures (5xx replies) are reported as ErrRejected.
+type SMTPChannel struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPChannel(cfg SMTPConfig) *SMTPChannel {
+	return &SMTPChannel{cfg: cfg}
+}
+
+func (c *SMTPChannel) Name() string {
+	return "email"
+}
+
+func (c *SMTPChannel) Send(ctx context.Context, notification *model.Notification) error {
+	if notification.Recipient == "" {
+		return fmt.Errorf("%w: no recipient address", ErrRejected)
+	}
+
+	message, err := c.compose(notification)
+	if err != nil {
+		return err
+	}
+
+	if err := c.send(ctx, notification.Recipient, message); err != nil {
+		var reply *textproto.Error
+		if errors.As(err, &reply) && reply.Code >= 500 {
+			return fmt.Errorf("%w: %v", ErrRejected, err)
+		}
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (c *SMTPChannel) send(ctx context.Context, recipient string, message []byte) error {
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if c.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	from, err := mail.ParseAddress(c.cfg.From)
+	if err != nil {
+		return fmt.Errorf("%w: invalid sender %q: %v", ErrRejected, c.cfg.From, err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient); err != nil {
+		return err
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(message); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose builds the message. Its Message-ID derives from the event, so that
+// mail clients can recognise a notification that was sent twice.
+func (c *SMTPChannel) compose(notification *model.Notification) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", notification.Text},
+		{"text/html; charset=utf-8", notification.HTML},
+	} {
+		if part.content == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	headers := [][2]string{
+		{"From", c.cfg.From},
+		{"To", notification.Recipient},
+		{"Subject", mime.QEncoding.Encode("utf-8", notification.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s.%s@agora>", notification.EventID, notification.EventType)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary())},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&message, "%s: %s\r\n", header[0], header[1])
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
@@ -0,0 +1,157 @@
+package channel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+// smtpServer is a local SMTP stand-in that accepts one message per
+// connection, or replies to RCPT with rcptReply when set.
+type smtpServer struct {
+	listener  net.Listener
+	rcptReply string
+	messages  chan string
+}
+
+func startSMTPServer(t *testing.T, rcptReply string) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{listener: listener, rcptReply: rcptReply, messages: make(chan string, 1)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.Fields(line)[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "RCPT":
+			if s.rcptReply != "" {
+				reply(s.rcptReply)
+			} else {
+				reply("250 OK")
+			}
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var message strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				message.WriteString(line)
+			}
+			s.messages <- message.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpServer) channel() *SMTPChannel {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return NewSMTPChannel(SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "Agora <no-reply@agora.local>"})
+}
+
+func TestSMTPChannelSendsMultipartEmail(t *testing.T) {
+	server := startSMTPServer(t, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := server.channel().Send(ctx, &model.Notification{
+		EventID:   "e1",
+		EventType: "order.shipped",
+		Recipient: "user123@agora.local",
+		Subject:   "Your order o1 has shipped",
+		Text:      "Tracking number: TRK1\n",
+		HTML:      "<p>Tracking number: <strong>TRK1</strong></p>",
+	})
+	if err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(<-server.messages))
+	if err != nil {
+		t.Fatalf("Failed to parse the email: %v", err)
+	}
+	if to := message.Header.Get("To"); to != "user123@agora.local" {
+		t.Errorf("Expected the email to user123@agora.local, got %s", to)
+	}
+	if subject := message.Header.Get("Subject"); subject != "Your order o1 has shipped" {
+		t.Errorf("Unexpected subject %q", subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected a multipart/alternative email, got %s (%v)", mediaType, err)
+	}
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	var contentTypes []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		if !strings.Contains(string(body), "TRK1") {
+			t.Errorf("Expected the %s part to contain the tracking number, got %q", part.Header.Get("Content-Type"), body)
+		}
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+	}
+	if len(contentTypes) != 2 || !strings.HasPrefix(contentTypes[0], "text/plain") || !strings.HasPrefix(contentTypes[1], "text/html") {
+		t.Errorf("Expected a text and an HTML part, got %v", contentTypes)
+	}
+}
+
+func TestSMTPChannelRejectedRecipient(t *testing.T) {
+	server := startSMTPServer(t, "550 No such user")
+
+	err := server.channel().Send(context.Background(), &model.Notification{EventID: "e1", Recipient: "nobody@agora.local", Text: "Hello"})
+	if !errors.Is(err, ErrRejected) {
+		t.Errorf("Expected ErrRejected, got %v", err)
+	}
+}
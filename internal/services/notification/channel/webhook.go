@@ -0,0 +1,72 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, keyed
+// with the webhook secret, so receivers can verify the sender.
+const SignatureHeader = "X-Agora-Signature"
+
+// WebhookChannel posts notifications as JSON to a URL. Client errors are
+// reported as ErrRejected; server errors and network failures can be
+// retried.
+type WebhookChannel struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookChannel(url, secret string, timeout time.Duration) *WebhookChannel {
+	return &WebhookChannel{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, notification *model.Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("%w: webhook responded with %s", ErrRejected, resp.Status)
+	}
+	return nil
+}
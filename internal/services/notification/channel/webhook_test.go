@@ -0,0 +1,53 @@
+package channel
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+func TestWebhookChannel(t *testing.T) {
+	status := http.StatusNoContent
+	var received model.Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		if r.Header.Get(SignatureHeader) != hex.EncodeToString(mac.Sum(nil)) {
+			t.Error("Expected the body to be signed with the secret")
+		}
+		json.Unmarshal(body, &received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookChannel(server.URL, "secret", time.Second)
+	notification := &model.Notification{EventID: "e1", EventType: "order.shipped", UserID: "user123", Subject: "Shipped"}
+
+	if err := webhook.Send(context.Background(), notification); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	if received != *notification {
+		t.Errorf("Expected %+v to be posted, got %+v", *notification, received)
+	}
+
+	status = http.StatusBadRequest
+	if err := webhook.Send(context.Background(), notification); !errors.Is(err, ErrRejected) {
+		t.Errorf("Expected ErrRejected for a client error, got %v", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := webhook.Send(context.Background(), notification); err == nil || errors.Is(err, ErrRejected) {
+		t.Errorf("Expected a retryable error for a server error, got %v", err)
+	}
+}
@@ -1,17 +1,38 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	confighelper "github.com/dinosgnk/agora-project/internal/pkg/config"
+	"github.com/dinosgnk/agora-project/internal/pkg/idempotency"
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
 	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+	"github.com/dinosgnk/agora-project/internal/services/notification/channel"
+	"github.com/dinosgnk/agora-project/internal/services/notification/config"
 	"github.com/dinosgnk/agora-project/internal/services/notification/consumer"
+	"github.com/dinosgnk/agora-project/internal/services/notification/repository"
+	"github.com/dinosgnk/agora-project/internal/services/notification/service"
+	"github.com/dinosgnk/agora-project/internal/services/notification/templates"
 )
 
+// purgeInterval is how often processed events beyond the retention window are
+// forgotten
+const purgeInterval = time.Hour
+
 func main() {
 	log := logger.NewLogger()
+	cfg := confighelper.LoadConfig[config.AppConfig](log)
 
 	rabbitClient, err := rabbitmq.NewRabbitMQClient(log)
 	if err != nil {
@@ -20,24 +41,132 @@ func main() {
 	}
 	defer rabbitClient.Close()
 
-	eventConsumer, err := consumer.NewEventConsumer(rabbitClient, log)
+	processed, err := newIdempotencyStore(cfg, log)
+	if err != nil {
+		log.Error("Failed to initialize idempotency store", "store", cfg.IdempotencyStore, "error", err)
+		os.Exit(1)
+	}
+
+	purger := idempotency.NewPurger(processed, cfg.IdempotencyRetention, purgeInterval, clock.Real(), log)
+	purger.Start()
+	defer purger.Stop()
+
+	renderer, err := templates.NewRenderer()
+	if err != nil {
+		log.Error("Failed to parse notification templates", "error", err)
+		os.Exit(1)
+	}
+
+	channels, err := newChannels(cfg, log)
+	if err != nil {
+		log.Error("Failed to initialize notification channels", "error", err)
+		os.Exit(1)
+	}
+
+	deliveryRepository, err := newDeliveryRepository(cfg, log)
+	if err != nil {
+		log.Error("Failed to initialize delivery repository", "store", cfg.DeliveryStore, "error", err)
+		os.Exit(1)
+	}
+
+	notificationService := service.NewNotificationService(renderer, channels, deliveryRepository, cfg.RecipientAddress, log)
+
+	eventConsumer, err := consumer.NewEventConsumer(rabbitClient, rabbitmq.ConsumeOptions{
+		Prefetch:        cfg.ConsumerPrefetch,
+		Workers:         cfg.ConsumerWorkers,
+		HandlerTimeout:  cfg.ConsumerHandlerTimeout,
+		ShutdownTimeout: cfg.ConsumerShutdownTimeout,
+	}, processed, notificationService, log)
 	if err != nil {
 		log.Error("Failed to initialize event consumer", "error", err)
 		os.Exit(1)
 	}
 
-	// Start consuming messages
-	if err := eventConsumer.Start(); err != nil {
+	// Stop consuming on an interrupt signal
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	subscription, err := eventConsumer.Start(ctx)
+	if err != nil {
 		log.Error("Failed to start event consumer", "error", err)
 		os.Exit(1)
 	}
 
+	// The service has no API, so it only serves its metrics
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		if err := http.ListenAndServe("0.0.0.0:"+cfg.Port, mux); err != nil {
+			log.Error("Failed to serve metrics", "error", err)
+		}
+	}()
+
 	log.Info("Notification service started successfully")
 
-	// Wait for interrupt signal to gracefully shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	<-ctx.Done()
+	log.Info("Shutting down notification service, waiting for events in flight...")
+	subscription.Wait()
+	log.Info("Notification service stopped")
+}
 
-	log.Info("Shutting down notification service...")
+func newIdempotencyStore(cfg *config.AppConfig, log logger.Logger) (idempotency.Store, error) {
+	switch cfg.IdempotencyStore {
+	case "postgres":
+		db, err := postgres.NewGormDatabase(log, &gorm.Config{})
+		if err != nil {
+			return nil, err
+		}
+		return idempotency.NewPostgresStore(db.GetDB(), clock.Real()), nil
+	case "file":
+		return idempotency.NewFileStore(cfg.IdempotencyFile, clock.Real())
+	default:
+		return nil, fmt.Errorf("unknown idempotency store %q", cfg.IdempotencyStore)
+	}
+}
+
+func newChannels(cfg *config.AppConfig, log logger.Logger) ([]channel.Channel, error) {
+	var channels []channel.Channel
+	for _, name := range cfg.Channels {
+		switch name {
+		case "email":
+			channels = append(channels, channel.NewSMTPChannel(channel.SMTPConfig{
+				Host:     cfg.SMTPHost,
+				Port:     cfg.SMTPPort,
+				Username: cfg.SMTPUsername,
+				Password: cfg.SMTPPassword,
+				From:     cfg.SMTPFrom,
+			}))
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, fmt.Errorf("the webhook channel needs NOTIFICATION_WEBHOOK_URL")
+			}
+			channels = append(channels, channel.NewWebhookChannel(cfg.WebhookURL, cfg.WebhookSecret, cfg.WebhookTimeout))
+		case "file":
+			fileChannel, err := channel.NewFileChannel(cfg.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			channels = append(channels, fileChannel)
+		case "log":
+			channels = append(channels, channel.NewLogChannel(log))
+		default:
+			return nil, fmt.Errorf("unknown notification channel %q", name)
+		}
+	}
+	return channels, nil
+}
+
+func newDeliveryRepository(cfg *config.AppConfig, log logger.Logger) (repository.IDeliveryRepository, error) {
+	switch cfg.DeliveryStore {
+	case "postgres":
+		deliveryRepository := repository.NewPostgresDeliveryRepository(log)
+		if deliveryRepository == nil {
+			return nil, fmt.Errorf("failed to connect to the database")
+		}
+		return deliveryRepository, nil
+	case "memory":
+		return repository.NewInMemoryDeliveryRepository(), nil
+	default:
+		return nil, fmt.Errorf("unknown delivery store %q", cfg.DeliveryStore)
+	}
 }
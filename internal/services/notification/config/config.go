@@ -1,7 +1,41 @@
 package config
 
+import "time"
+
 type AppConfig struct {
 	Environment string `env:"ENVIRONMENT"`
 	Port        string `env:"PORT"`
 	Service     string `env:"SERVICE_NAME"`
+
+	ConsumerPrefetch       int           `env:"NOTIFICATION_CONSUMER_PREFETCH" envDefault:"20"`
+	ConsumerWorkers        int           `env:"NOTIFICATION_CONSUMER_WORKERS" envDefault:"4"`
+	ConsumerHandlerTimeout time.Duration `env:"NOTIFICATION_HANDLER_TIMEOUT" envDefault:"30s"`
+	// ConsumerShutdownTimeout bounds how long stopping waits for handlers
+	// that outlived their timeout
+	ConsumerShutdownTimeout time.Duration `env:"NOTIFICATION_SHUTDOWN_TIMEOUT" envDefault:"10s"`
+
+	// IdempotencyStore is "file" for an embedded store or "postgres"
+	IdempotencyStore     string        `env:"NOTIFICATION_IDEMPOTENCY_STORE" envDefault:"file"`
+	IdempotencyFile      string        `env:"NOTIFICATION_IDEMPOTENCY_FILE" envDefault:"data/processed-events.ndjson"`
+	IdempotencyRetention time.Duration `env:"NOTIFICATION_IDEMPOTENCY_RETENTION" envDefault:"168h"`
+
+	// Channels lists where notifications are sent: email, webhook, file or log
+	Channels []string `env:"NOTIFICATION_CHANNELS" envSeparator:"," envDefault:"log"`
+	// RecipientAddress is the email address of a user, with {user_id} in
+	// place of the user's ID
+	RecipientAddress string `env:"NOTIFICATION_RECIPIENT_ADDRESS" envDefault:"{user_id}@agora.local"`
+	// DeliveryStore is "memory" or "postgres"
+	DeliveryStore string `env:"NOTIFICATION_DELIVERY_STORE" envDefault:"memory"`
+
+	SMTPHost     string `env:"NOTIFICATION_SMTP_HOST" envDefault:"localhost"`
+	SMTPPort     int    `env:"NOTIFICATION_SMTP_PORT" envDefault:"1025"`
+	SMTPUsername string `env:"NOTIFICATION_SMTP_USERNAME"`
+	SMTPPassword string `env:"NOTIFICATION_SMTP_PASSWORD"`
+	SMTPFrom     string `env:"NOTIFICATION_SMTP_FROM" envDefault:"Agora <no-reply@agora.local>"`
+
+	WebhookURL     string        `env:"NOTIFICATION_WEBHOOK_URL"`
+	WebhookSecret  string        `env:"NOTIFICATION_WEBHOOK_SECRET"`
+	WebhookTimeout time.Duration `env:"NOTIFICATION_WEBHOOK_TIMEOUT" envDefault:"10s"`
+
+	FilePath string `env:"NOTIFICATION_FILE_PATH" envDefault:"data/notifications.jsonl"`
 }
@@ -1,73 +1,149 @@
 package consumer
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/idempotency"
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
 	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+	"github.com/dinosgnk/agora-project/internal/services/notification/service"
 )
 
 const (
-	ordersExchange     = "orders"
 	notificationsQueue = "notifications"
-)
 
-type OrderEvent struct {
-	EventID   string `json:"event_id"`
-	EventType string `json:"event_type"`
-	Timestamp string `json:"timestamp"`
-	OrderID   string `json:"order_id"`
-	UserID    string `json:"user_id"`
-}
+	// defaultClaimLease bounds how long an event stays claimed by a delivery
+	// without a handler timeout
+	defaultClaimLease = 5 * time.Minute
+)
 
 type EventConsumer struct {
-	client *rabbitmq.RabbitMQClient
-	log    logger.Logger
+	client    rabbitmq.Broker
+	options   rabbitmq.ConsumeOptions
+	processed idempotency.Store
+	notifier  service.INotificationService
+	log       logger.Logger
 }
 
-func NewEventConsumer(client *rabbitmq.RabbitMQClient, log logger.Logger) (*EventConsumer, error) {
-	if err := client.DeclareExchange(ordersExchange, "topic"); err != nil {
-		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+func NewEventConsumer(client rabbitmq.Broker, options rabbitmq.ConsumeOptions, processed idempotency.Store, notifier service.INotificationService, log logger.Logger) (*EventConsumer, error) {
+	for _, exchange := range []string{events.OrdersExchange, events.CartExchange} {
+		if err := client.DeclareExchange(exchange, "topic"); err != nil {
+			return nil, fmt.Errorf("failed to declare exchange: %w", err)
+		}
 	}
 
 	if err := client.DeclareQueue(notificationsQueue); err != nil {
 		return nil, fmt.Errorf("failed to declare queue: %w", err)
 	}
 
-	if err := client.BindQueue(notificationsQueue, ordersExchange, "order.*"); err != nil {
+	if err := client.BindQueue(notificationsQueue, events.OrdersExchange, "order.#"); err != nil {
 		return nil, fmt.Errorf("failed to bind queue: %w", err)
 	}
 
+	if err := client.BindQueue(notificationsQueue, events.CartExchange, events.WishlistPriceDropped); err != nil {
+		return nil, fmt.Errorf("failed to bind queue: %w", err)
+	}
+
+	if err := client.DeclareRetryQueues(notificationsQueue, rabbitmq.DefaultRetryPolicy); err != nil {
+		return nil, fmt.Errorf("failed to declare retry queues: %w", err)
+	}
+
 	return &EventConsumer{
-		client: client,
-		log:    log,
+		client:    client,
+		options:   options,
+		processed: processed,
+		notifier:  notifier,
+		log:       log,
 	}, nil
 }
 
-func (c *EventConsumer) Start() error {
-	c.log.Info("Starting event consumer", "queue", notificationsQueue)
+// Start consumes order and wishlist events until ctx is cancelled. The returned
+// subscription waits for the events being handled at that point.
+func (c *EventConsumer) Start(ctx context.Context) (*rabbitmq.Subscription, error) {
+	c.log.Info("Starting event consumer", "queue", notificationsQueue, "workers", c.options.Workers, "prefetch", c.options.Prefetch)
+
+	return c.client.ConsumeWithOptions(ctx, notificationsQueue, c.handler(), c.options)
+}
+
+func (c *EventConsumer) handler() rabbitmq.DeliveryHandler {
+	router := rabbitmq.NewRouter(c.log)
+	router.AddMiddleware(rabbitmq.Tracing())
+	router.AddMiddleware(rabbitmq.Logging(c.log))
+	router.AddMiddleware(rabbitmq.Metrics())
+	router.AddMiddleware(rabbitmq.Recovery(c.log))
+	router.AddMiddleware(idempotency.Middleware(c.processed, c.claimLease(), c.log))
 
-	return c.client.Consume(notificationsQueue, c.handleMessage)
+	rabbitmq.Handle(router, events.OrderCreated, c.handleOrderCreated)
+	rabbitmq.Handle(router, events.OrderConfirmed, c.handleOrderConfirmed)
+	rabbitmq.Handle(router, events.OrderProcessing, c.handleOrderProcessing)
+	rabbitmq.Handle(router, events.OrderShipped, c.handleOrderShipped)
+	rabbitmq.Handle(router, events.OrderDelivered, c.handleOrderDelivered)
+	rabbitmq.Handle(router, events.OrderCancelled, c.handleOrderCancelled)
+	rabbitmq.Handle(router, events.WishlistPriceDropped, c.handleWishlistPriceDropped)
+	return router.BuildHandler()
 }
 
-func (c *EventConsumer) handleMessage(body []byte) error {
-	var event OrderEvent
-	if err := json.Unmarshal(body, &event); err != nil {
-		c.log.Error("Failed to unmarshal event", "error", err)
-		return err
+// claimLease outlasts the handler timeout, so an event is not handled twice
+// at once unless its handler ignores cancellation.
+func (c *EventConsumer) claimLease() time.Duration {
+	if c.options.HandlerTimeout <= 0 {
+		return defaultClaimLease
 	}
+	return 2 * c.options.HandlerTimeout
+}
+
+func (c *EventConsumer) handleOrderCreated(ctx context.Context, event events.Envelope[events.OrderCreatedEvent]) error {
+	return c.notify(ctx, event.Metadata, event.Data.UserID, event.Data)
+}
 
-	c.log.Info("Received order event",
-		"event_id", event.EventID,
-		"event_type", event.EventType,
-		"order_id", event.OrderID,
-		"user_id", event.UserID,
-		"timestamp", event.Timestamp,
+func (c *EventConsumer) handleOrderConfirmed(ctx context.Context, event events.Envelope[events.OrderConfirmedEvent]) error {
+	return c.notify(ctx, event.Metadata, event.Data.UserID, event.Data)
+}
+
+func (c *EventConsumer) handleOrderProcessing(ctx context.Context, event events.Envelope[events.OrderProcessingEvent]) error {
+	return c.notify(ctx, event.Metadata, event.Data.UserID, event.Data)
+}
+
+func (c *EventConsumer) handleOrderShipped(ctx context.Context, event events.Envelope[events.OrderShippedEvent]) error {
+	return c.notify(ctx, event.Metadata, event.Data.UserID, event.Data)
+}
+
+func (c *EventConsumer) handleOrderDelivered(ctx context.Context, event events.Envelope[events.OrderDeliveredEvent]) error {
+	return c.notify(ctx, event.Metadata, event.Data.UserID, event.Data)
+}
+
+func (c *EventConsumer) handleOrderCancelled(ctx context.Context, event events.Envelope[events.OrderCancelledEvent]) error {
+	return c.notify(ctx, event.Metadata, event.Data.UserID, event.Data)
+}
+
+func (c *EventConsumer) handleWishlistPriceDropped(ctx context.Context, event events.Envelope[events.WishlistPriceDroppedEvent]) error {
+	return c.notify(ctx, event.Metadata, event.Data.UserId, event.Data)
+}
+
+// notify sends the notification of an event. Events whose notification
+// cannot be rendered, or that every failed channel rejected, are parked
+// rather than retried.
+func (c *EventConsumer) notify(ctx context.Context, metadata events.Metadata, userId string, event any) error {
+	c.log.Info("Received event",
+		"event_id", metadata.EventID,
+		"event_type", metadata.EventType,
+		"user_id", userId,
+		"occurred_at", metadata.OccurredAt,
+		"correlation_id", metadata.CorrelationID,
 	)
 
-	// TODO: Implement actual notification logic here
-	c.log.Info("Processing notification", "event_type", event.EventType, "user_id", event.UserID)
+	err := c.notifier.Notify(ctx, metadata, userId, event)
+	if err == nil {
+		return nil
+	}
 
-	return nil
+	var deliveryErr *service.DeliveryError
+	if !errors.As(err, &deliveryErr) || deliveryErr.Rejected() {
+		return rabbitmq.Permanent(err)
+	}
+	return err
 }
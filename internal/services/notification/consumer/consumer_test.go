@@ -0,0 +1,135 @@
+package consumer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/idempotency"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
+	"github.com/dinosgnk/agora-project/internal/services/notification/channel"
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+	"github.com/dinosgnk/agora-project/internal/services/notification/repository"
+	"github.com/dinosgnk/agora-project/internal/services/notification/service"
+	"github.com/dinosgnk/agora-project/internal/services/notification/templates"
+)
+
+// readNotifications reads the notifications a FileChannel wrote.
+func readNotifications(t *testing.T, path string) []model.Notification {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var notifications []model.Notification
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var notification model.Notification
+		if err := json.Unmarshal(scanner.Bytes(), &notification); err != nil {
+			t.Fatal(err)
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+func TestEventsAreNotified(t *testing.T) {
+	log := logger.NewLogger()
+	broker := rabbitmq.NewInMemoryBroker(clock.Real(), log)
+	dir := t.TempDir()
+
+	processed, err := idempotency.NewFileStore(filepath.Join(dir, "processed.json"), clock.Real())
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderer, err := templates.NewRenderer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink, err := channel.NewFileChannel(filepath.Join(dir, "notifications.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries := repository.NewInMemoryDeliveryRepository()
+	notifier := service.NewNotificationService(renderer, []channel.Channel{sink}, deliveries, "{user_id}@agora.local", log)
+
+	eventConsumer, err := NewEventConsumer(broker, rabbitmq.ConsumeOptions{Workers: 2}, processed, notifier, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := eventConsumer.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shipped := events.New("order-service", events.OrderShippedEvent{
+		OrderEvent:     events.OrderEvent{OrderID: "o1", UserID: "user123"},
+		TrackingNumber: "TRK1",
+	})
+	// The second copy is a redelivery of the same event
+	for range 2 {
+		if err := broker.PublishMandatory(events.OrdersExchange, events.OrderShipped, shipped); err != nil {
+			t.Fatalf("Expected the event to be routed to notifications, got %v", err)
+		}
+	}
+	if err := broker.PublishMandatory(events.OrdersExchange, events.OrderCreated, "not an envelope"); err != nil {
+		t.Fatal(err)
+	}
+	// order.status.updated is bound by order.# but has no notification
+	statusUpdated := events.New("order-service", events.OrderStatusUpdatedEvent{
+		OrderEvent: events.OrderEvent{OrderID: "o1", UserID: "user123"},
+	})
+	if err := broker.PublishMandatory(events.OrdersExchange, events.OrderStatusUpdated, statusUpdated); err != nil {
+		t.Fatalf("Expected the event to be routed to notifications, got %v", err)
+	}
+	priceDropped := events.New("cart-service", events.WishlistPriceDroppedEvent{
+		UserId:       "user123",
+		WishlistName: "Kitchen",
+		ProductCode:  "MUG-1",
+		Name:         "Mug",
+		OldPrice:     12,
+		NewPrice:     9.5,
+	})
+	if err := broker.PublishMandatory(events.CartExchange, events.WishlistPriceDropped, priceDropped); err != nil {
+		t.Fatalf("Expected the event to be routed to notifications, got %v", err)
+	}
+
+	if err := broker.WaitForQueue(notificationsQueue, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	subscription.Wait()
+
+	notifications := readNotifications(t, filepath.Join(dir, "notifications.jsonl"))
+	if len(notifications) != 2 {
+		t.Fatalf("Expected user123 to be notified twice, got %d notifications", len(notifications))
+	}
+	subjects := map[string]bool{}
+	for _, notification := range notifications {
+		if notification.Recipient != "user123@agora.local" {
+			t.Errorf("Unexpected recipient %s", notification.Recipient)
+		}
+		subjects[notification.Subject] = true
+	}
+	for _, subject := range []string{"Your order o1 has shipped", "Price drop: Mug is now 9.50"} {
+		if !subjects[subject] {
+			t.Errorf("Expected a notification %q, got %+v", subject, notifications)
+		}
+	}
+	if recorded, _ := deliveries.GetDeliveriesByEventId(shipped.EventID); len(recorded) != 1 {
+		t.Errorf("Expected 1 recorded delivery, got %d", len(recorded))
+	}
+	if parked := broker.Pending(rabbitmq.ParkingLotName(notificationsQueue)); parked != 1 {
+		t.Errorf("Expected the malformed event to be parked, got %d parked", parked)
+	}
+}
@@ -0,0 +1,8 @@
+package enums
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent   DeliveryStatus = "sent"
+	DeliveryStatusFailed DeliveryStatus = "failed"
+)
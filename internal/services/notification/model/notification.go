@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/notification/enums"
+)
+
+// Notification is a rendered message about an event, addressed to a user.
+type Notification struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	UserID    string `json:"user_id"`
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject"`
+	Text      string `json:"text"`
+	HTML      string `json:"html"`
+}
+
+// Delivery records the outcome of sending a notification through a channel.
+type Delivery struct {
+	ID        int                  `gorm:"primaryKey;column:id"`
+	EventID   string               `gorm:"column:event_id"`
+	EventType string               `gorm:"column:event_type"`
+	UserID    string               `gorm:"column:user_id"`
+	Channel   string               `gorm:"column:channel"`
+	Recipient string               `gorm:"column:recipient"`
+	Status    enums.DeliveryStatus `gorm:"column:status"`
+	Error     string               `gorm:"column:error"`
+	CreatedAt time.Time            `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Delivery) TableName() string {
+	return "notifications.t_delivery"
+}
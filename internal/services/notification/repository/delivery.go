@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/dinosgnk/agora-project/internal/services/notification/model"
+
+type IDeliveryRepository interface {
+	CreateDelivery(delivery *model.Delivery) error
+	// IsDelivered reports whether a notification of the event was sent
+	// through the channel, so that retries skip the channels that succeeded
+	IsDelivered(eventId, channel string) (bool, error)
+	GetDeliveriesByEventId(eventId string) ([]*model.Delivery, error)
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/services/notification/enums"
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+// InMemoryDeliveryRepository keeps deliveries for the lifetime of the
+// process, for development without a database and for tests.
+type InMemoryDeliveryRepository struct {
+	deliveries []*model.Delivery
+	mu         sync.RWMutex
+}
+
+func NewInMemoryDeliveryRepository() *InMemoryDeliveryRepository {
+	return &InMemoryDeliveryRepository{}
+}
+
+func (repo *InMemoryDeliveryRepository) CreateDelivery(delivery *model.Delivery) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delivery.ID = len(repo.deliveries) + 1
+	delivery.CreatedAt = time.Now()
+	deliveryCopy := *delivery
+	repo.deliveries = append(repo.deliveries, &deliveryCopy)
+	return nil
+}
+
+func (repo *InMemoryDeliveryRepository) IsDelivered(eventId, channel string) (bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, delivery := range repo.deliveries {
+		if delivery.EventID == eventId && delivery.Channel == channel && delivery.Status == enums.DeliveryStatusSent {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repo *InMemoryDeliveryRepository) GetDeliveriesByEventId(eventId string) ([]*model.Delivery, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var deliveries []*model.Delivery
+	for _, delivery := range repo.deliveries {
+		if delivery.EventID == eventId {
+			deliveryCopy := *delivery
+			deliveries = append(deliveries, &deliveryCopy)
+		}
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+	"github.com/dinosgnk/agora-project/internal/services/notification/enums"
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+	"gorm.io/gorm"
+)
+
+type PostgresDeliveryRepository struct {
+	gormDb *postgres.GormDatabase
+}
+
+func NewPostgresDeliveryRepository(logger logger.Logger) *PostgresDeliveryRepository {
+	gormDb, err := postgres.NewGormDatabase(logger, &gorm.Config{})
+	if err != nil {
+		return nil
+	}
+
+	return &PostgresDeliveryRepository{
+		gormDb: gormDb,
+	}
+}
+
+func (repo *PostgresDeliveryRepository) CreateDelivery(delivery *model.Delivery) error {
+	return repo.gormDb.Create(delivery).Error
+}
+
+func (repo *PostgresDeliveryRepository) IsDelivered(eventId, channel string) (bool, error) {
+	var count int64
+	result := repo.gormDb.Model(&model.Delivery{}).
+		Where("event_id = ? AND channel = ? AND status = ?", eventId, channel, enums.DeliveryStatusSent).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+func (repo *PostgresDeliveryRepository) GetDeliveriesByEventId(eventId string) ([]*model.Delivery, error) {
+	var deliveries []*model.Delivery
+	result := repo.gormDb.Where("event_id = ?", eventId).Order("created_at, id").Find(&deliveries)
+	return deliveries, result.Error
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/notification/channel"
+	"github.com/dinosgnk/agora-project/internal/services/notification/enums"
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+	"github.com/dinosgnk/agora-project/internal/services/notification/repository"
+	"github.com/dinosgnk/agora-project/internal/services/notification/templates"
+)
+
+// UserIdPlaceholder is replaced with the user ID in the recipient address
+// format.
+const UserIdPlaceholder = "{user_id}"
+
+// DeliveryError lists the channels a notification could not be sent through.
+type DeliveryError struct {
+	Failed map[string]error
+}
+
+func (e *DeliveryError) Error() string {
+	channels := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		channels = append(channels, name)
+	}
+	sort.Strings(channels)
+
+	errs := make([]string, 0, len(channels))
+	for _, name := range channels {
+		errs = append(errs, fmt.Sprintf("%s: %v", name, e.Failed[name]))
+	}
+	return "failed to deliver notification: " + strings.Join(errs, "; ")
+}
+
+func (e *DeliveryError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Rejected reports whether every failed channel rejected the notification,
+// so that sending it again cannot succeed.
+func (e *DeliveryError) Rejected() bool {
+	return !slices.ContainsFunc(e.Unwrap(), func(err error) bool {
+		return !errors.Is(err, channel.ErrRejected)
+	})
+}
+
+type INotificationService interface {
+	// Notify renders the notification of an event to a user and sends it
+	// through every channel it was not sent through yet, recording each
+	// delivery. It returns a *DeliveryError when any channel failed.
+	Notify(ctx context.Context, metadata events.Metadata, userId string, event any) error
+}
+
+type NotificationService struct {
+	renderer      *templates.Renderer
+	channels      []channel.Channel
+	deliveries    repository.IDeliveryRepository
+	addressFormat string
+	log           logger.Logger
+}
+
+// NewNotificationService sends notifications to the address made from
+// addressFormat by replacing UserIdPlaceholder, since events only identify
+// users by their ID.
+func NewNotificationService(renderer *templates.Renderer, channels []channel.Channel, deliveries repository.IDeliveryRepository, addressFormat string, log logger.Logger) *NotificationService {
+	return &NotificationService{
+		renderer:      renderer,
+		channels:      channels,
+		deliveries:    deliveries,
+		addressFormat: addressFormat,
+		log:           log,
+	}
+}
+
+func (s *NotificationService) Notify(ctx context.Context, metadata events.Metadata, userId string, event any) error {
+	notification, err := s.renderer.Render(metadata.EventType, templates.Data{
+		Metadata: metadata,
+		UserID:   userId,
+		Event:    event,
+	})
+	if err != nil {
+		return err
+	}
+	notification.Recipient = strings.ReplaceAll(s.addressFormat, UserIdPlaceholder, userId)
+
+	failed := make(map[string]error)
+	for _, ch := range s.channels {
+		delivered, err := s.deliveries.IsDelivered(notification.EventID, ch.Name())
+		if err != nil {
+			failed[ch.Name()] = fmt.Errorf("failed to check earlier deliveries: %w", err)
+			continue
+		}
+		if delivered {
+			s.log.Debug("Skipping channel that delivered the notification", "event_id", notification.EventID, "channel", ch.Name())
+			continue
+		}
+
+		sendErr := ch.Send(ctx, notification)
+		s.record(notification, ch.Name(), sendErr)
+		if sendErr != nil {
+			failed[ch.Name()] = sendErr
+		}
+	}
+
+	if len(failed) > 0 {
+		return &DeliveryError{Failed: failed}
+	}
+	return nil
+}
+
+// record saves the outcome of a delivery. A delivery that cannot be recorded
+// is only logged; at worst a retry sends the notification again.
+func (s *NotificationService) record(notification *model.Notification, channelName string, sendErr error) {
+	delivery := &model.Delivery{
+		EventID:   notification.EventID,
+		EventType: notification.EventType,
+		UserID:    notification.UserID,
+		Channel:   channelName,
+		Recipient: notification.Recipient,
+		Status:    enums.DeliveryStatusSent,
+	}
+	if sendErr != nil {
+		delivery.Status = enums.DeliveryStatusFailed
+		delivery.Error = sendErr.Error()
+		s.log.Warn("Failed to deliver notification", "event_id", notification.EventID, "channel", channelName, "error", sendErr)
+	} else {
+		s.log.Info("Delivered notification", "event_id", notification.EventID, "event_type", notification.EventType, "channel", channelName)
+	}
+
+	if err := s.deliveries.CreateDelivery(delivery); err != nil {
+		s.log.Error("Failed to record notification delivery", "event_id", notification.EventID, "channel", channelName, "error", err)
+	}
+}
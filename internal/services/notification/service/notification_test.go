@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/services/notification/channel"
+	"github.com/dinosgnk/agora-project/internal/services/notification/enums"
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+	"github.com/dinosgnk/agora-project/internal/services/notification/repository"
+	"github.com/dinosgnk/agora-project/internal/services/notification/templates"
+)
+
+type fakeChannel struct {
+	name     string
+	failures []error
+	sent     []*model.Notification
+}
+
+func (c *fakeChannel) Name() string {
+	return c.name
+}
+
+func (c *fakeChannel) Send(_ context.Context, notification *model.Notification) error {
+	if len(c.failures) > 0 {
+		err := c.failures[0]
+		c.failures = c.failures[1:]
+		return err
+	}
+	c.sent = append(c.sent, notification)
+	return nil
+}
+
+func newTestService(t *testing.T, channels ...channel.Channel) (*NotificationService, *repository.InMemoryDeliveryRepository) {
+	renderer, err := templates.NewRenderer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries := repository.NewInMemoryDeliveryRepository()
+	return NewNotificationService(renderer, channels, deliveries, "{user_id}@agora.local", logger.NewLogger()), deliveries
+}
+
+func notifyShipped(s *NotificationService) error {
+	order := events.OrderEvent{OrderID: "o1", UserID: "user123"}
+	return s.Notify(context.Background(),
+		events.Metadata{EventID: "e1", EventType: events.OrderShipped},
+		order.UserID,
+		events.OrderShippedEvent{OrderEvent: order, TrackingNumber: "TRK1"},
+	)
+}
+
+func TestNotifyRetriesOnlyFailedChannels(t *testing.T) {
+	email := &fakeChannel{name: "email", failures: []error{errors.New("connection refused")}}
+	webhook := &fakeChannel{name: "webhook"}
+	s, deliveries := newTestService(t, email, webhook)
+
+	err := notifyShipped(s)
+	var deliveryErr *DeliveryError
+	if !errors.As(err, &deliveryErr) || deliveryErr.Rejected() {
+		t.Fatalf("Expected a retryable DeliveryError, got %v", err)
+	}
+
+	if err := notifyShipped(s); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if len(email.sent) != 1 || len(webhook.sent) != 1 {
+		t.Errorf("Expected one notification per channel, got %d emails and %d webhooks", len(email.sent), len(webhook.sent))
+	}
+	if recipient := email.sent[0].Recipient; recipient != "user123@agora.local" {
+		t.Errorf("Expected the notification to user123@agora.local, got %s", recipient)
+	}
+
+	recorded, _ := deliveries.GetDeliveriesByEventId("e1")
+	var statuses []string
+	for _, delivery := range recorded {
+		statuses = append(statuses, delivery.Channel+" "+string(delivery.Status))
+	}
+	expected := []string{"email " + string(enums.DeliveryStatusFailed), "webhook " + string(enums.DeliveryStatusSent), "email " + string(enums.DeliveryStatusSent)}
+	if len(statuses) != len(expected) {
+		t.Fatalf("Expected deliveries %v, got %v", expected, statuses)
+	}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Errorf("Expected deliveries %v, got %v", expected, statuses)
+			break
+		}
+	}
+}
+
+func TestNotifyRejected(t *testing.T) {
+	email := &fakeChannel{name: "email", failures: []error{channel.ErrRejected}}
+	s, _ := newTestService(t, email)
+
+	var deliveryErr *DeliveryError
+	if err := notifyShipped(s); !errors.As(err, &deliveryErr) || !deliveryErr.Rejected() {
+		t.Errorf("Expected a rejected DeliveryError, got %v", err)
+	}
+}
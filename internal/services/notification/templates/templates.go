@@ -0,0 +1,119 @@
+// Package templates renders the notifications of events. Each event type has
+// a text template, defining its "subject" and plain "text" body, and an HTML
+// template, defining the "content" of the shared HTML layout.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/services/notification/model"
+)
+
+//go:embed *.tmpl *.html
+var files embed.FS
+
+const layoutFile = "layout.html"
+
+var ErrNoTemplate = errors.New("no notification template for event type")
+
+// Data is what templates render: the event metadata, the user it notifies
+// and the event itself, whose fields depend on its type.
+type Data struct {
+	events.Metadata
+	UserID string
+	Event  any
+}
+
+// page is the data of the HTML layout, which titles the page with the
+// rendered subject.
+type page struct {
+	Data
+	Subject string
+}
+
+var funcs = map[string]any{
+	"money": func(amount float64) string {
+		return fmt.Sprintf("%.2f", amount)
+	},
+	"date": func(t time.Time) string {
+		return t.UTC().Format("2 Jan 2006 15:04 MST")
+	},
+}
+
+type Renderer struct {
+	texts map[string]*texttemplate.Template
+	htmls map[string]*htmltemplate.Template
+}
+
+// NewRenderer parses the embedded templates.
+func NewRenderer() (*Renderer, error) {
+	names, err := fs.Glob(files, "*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{
+		texts: make(map[string]*texttemplate.Template),
+		htmls: make(map[string]*htmltemplate.Template),
+	}
+	for _, name := range names {
+		eventType := strings.TrimSuffix(name, ".tmpl")
+
+		text, err := texttemplate.New(name).Funcs(funcs).ParseFS(files, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, required := range []string{"subject", "text"} {
+			if text.Lookup(required) == nil {
+				return nil, fmt.Errorf("template %s does not define %q", name, required)
+			}
+		}
+
+		html, err := htmltemplate.New(layoutFile).Funcs(funcs).ParseFS(files, layoutFile, eventType+".html")
+		if err != nil {
+			return nil, err
+		}
+
+		r.texts[eventType] = text
+		r.htmls[eventType] = html
+	}
+	return r, nil
+}
+
+// Render renders the notification of an event, leaving its recipient to the
+// caller.
+func (r *Renderer) Render(eventType string, data Data) (*model.Notification, error) {
+	text, ok := r.texts[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoTemplate, eventType)
+	}
+
+	var subject, body, html bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render subject of %s: %w", eventType, err)
+	}
+	if err := text.ExecuteTemplate(&body, "text", data); err != nil {
+		return nil, fmt.Errorf("failed to render text of %s: %w", eventType, err)
+	}
+	if err := r.htmls[eventType].Execute(&html, page{Data: data, Subject: strings.TrimSpace(subject.String())}); err != nil {
+		return nil, fmt.Errorf("failed to render HTML of %s: %w", eventType, err)
+	}
+
+	return &model.Notification{
+		EventID:   data.EventID,
+		EventType: eventType,
+		UserID:    data.UserID,
+		Subject:   strings.TrimSpace(subject.String()),
+		Text:      strings.TrimSpace(body.String()) + "\n",
+		HTML:      html.String(),
+	}, nil
+}
@@ -0,0 +1,80 @@
+package templates
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+)
+
+func TestEveryNotifiedEventHasTemplates(t *testing.T) {
+	renderer, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("Failed to parse templates: %v", err)
+	}
+
+	notified := map[string]any{
+		events.OrderCreated:         events.OrderCreatedEvent{},
+		events.OrderConfirmed:       events.OrderConfirmedEvent{},
+		events.OrderProcessing:      events.OrderProcessingEvent{},
+		events.OrderShipped:         events.OrderShippedEvent{},
+		events.OrderDelivered:       events.OrderDeliveredEvent{},
+		events.OrderCancelled:       events.OrderCancelledEvent{},
+		events.WishlistPriceDropped: events.WishlistPriceDroppedEvent{},
+	}
+	for eventType, event := range notified {
+		notification, err := renderer.Render(eventType, Data{
+			Metadata: events.Metadata{EventID: "e1", EventType: eventType},
+			UserID:   "user123",
+			Event:    event,
+		})
+		if err != nil {
+			t.Errorf("Failed to render %s: %v", eventType, err)
+			continue
+		}
+		if notification.Subject == "" || notification.Text == "" || notification.HTML == "" {
+			t.Errorf("Expected %s to render a subject, text and HTML, got %+v", eventType, notification)
+		}
+	}
+
+	if _, err := renderer.Render(events.OrderStatusUpdated, Data{}); !errors.Is(err, ErrNoTemplate) {
+		t.Errorf("Expected ErrNoTemplate, got %v", err)
+	}
+}
+
+func TestRenderOrderCreated(t *testing.T) {
+	renderer, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("Failed to parse templates: %v", err)
+	}
+
+	notification, err := renderer.Render(events.OrderCreated, Data{
+		Metadata: events.Metadata{EventID: "e1", OccurredAt: time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)},
+		UserID:   "user123",
+		Event: events.OrderCreatedEvent{
+			OrderEvent:      events.OrderEvent{OrderID: "o1", UserID: "user123"},
+			TotalAmount:     59.9,
+			ShippingAddress: "1 Main St",
+			Products: []events.OrderCreatedProduct{
+				{ProductName: "Mug <Large>", Quantity: 2, Price: 29.95},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+
+	if notification.Subject != "Your order o1 has been placed" {
+		t.Errorf("Unexpected subject %q", notification.Subject)
+	}
+	for _, expected := range []string{"1 Mar 2025 10:30 UTC", "- 2 x Mug <Large>: 29.95", "Total: 59.90"} {
+		if !strings.Contains(notification.Text, expected) {
+			t.Errorf("Expected the text to contain %q, got:\n%s", expected, notification.Text)
+		}
+	}
+	if !strings.Contains(notification.HTML, "Mug &lt;Large&gt;") || !strings.Contains(notification.HTML, "<title>Your order o1 has been placed</title>") {
+		t.Errorf("Expected escaped product names and the subject as title, got:\n%s", notification.HTML)
+	}
+}
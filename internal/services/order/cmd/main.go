@@ -32,7 +32,13 @@ func main() {
 	}
 
 	orderRepository := repository.NewPostgresOrderRepository(log)
-	orderService := service.NewOrderService(orderRepository, publisher)
+	promotionRepository := repository.NewPostgresPromotionRepository(log)
+	if promotionRepository == nil {
+		log.Error("Failed to initialize promotion repository")
+		os.Exit(1)
+	}
+
+	orderService := service.NewOrderService(orderRepository, promotionRepository, publisher)
 	orderHandler := handler.NewOrderHandler(orderService, log)
 
 	server := server.NewServer(cfg.Port, orderHandler, log, cfg.Service)
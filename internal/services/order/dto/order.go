@@ -6,24 +6,36 @@ import (
 	"github.com/dinosgnk/agora-project/internal/services/order/enums"
 )
 
+// OrderedProduct references the purchased variant by SKU when the product
+// has variants. Attributes are a snapshot of the variant at order time.
 type OrderedProduct struct {
-	ProductCode string  `json:"code" binding:"required"`
-	ProductName string  `json:"product_name" binding:"required"`
-	Quantity    int     `json:"quantity" binding:"required"`
-	Price       float64 `json:"price" binding:"required"`
+	ProductCode string         `json:"code" binding:"required"`
+	SKU         string         `json:"sku,omitempty"`
+	ProductName string         `json:"product_name" binding:"required"`
+	Attributes  map[string]any `json:"attributes,omitempty"`
+	Quantity    int            `json:"quantity" binding:"required"`
+	Price       float64        `json:"price" binding:"required"`
+}
+
+// AppliedDiscount names a coupon the order is placed with. Its amount is
+// computed by order-service from the promotion.
+type AppliedDiscount struct {
+	CouponCode string `json:"coupon_code" binding:"required"`
 }
 
 type CreateOrderRequest struct {
-	UserID          string            `json:"user_id" binding:"required"`
-	Products        []*OrderedProduct `json:"products" binding:"required,min=1"`
-	ShippingAddress string            `json:"shipping_address" binding:"required"`
-	PaymentMethod   string            `json:"payment_method" binding:"required"`
+	UserID          string             `json:"user_id" binding:"required"`
+	Products        []*OrderedProduct  `json:"products" binding:"required,min=1"`
+	Discounts       []*AppliedDiscount `json:"discounts"`
+	ShippingAddress string             `json:"shipping_address" binding:"required"`
+	PaymentMethod   string             `json:"payment_method" binding:"required"`
 }
 
 type OrderSummaryResponse struct {
 	OrderID         string            `json:"order_id"`
 	UserID          string            `json:"user_id"`
 	Status          enums.OrderStatus `json:"status"`
+	DiscountAmount  float64           `json:"discount_amount"`
 	TotalAmount     float64           `json:"total_amount"`
 	ShippingAddress string            `json:"shipping_address"`
 	PaymentMethod   string            `json:"payment_method"`
@@ -35,6 +47,7 @@ type OrderResponse struct {
 	OrderID         string            `json:"order_id"`
 	UserID          string            `json:"user_id"`
 	Status          enums.OrderStatus `json:"status"`
+	DiscountAmount  float64           `json:"discount_amount"`
 	TotalAmount     float64           `json:"total_amount"`
 	ShippingAddress string            `json:"shipping_address"`
 	PaymentMethod   string            `json:"payment_method"`
@@ -48,6 +61,14 @@ type UserOrdersResponse struct {
 	Orders []*OrderSummaryResponse `json:"orders"`
 }
 
+// DeliveredProductResponse tells whether a user has received a product, which
+// catalog-service requires before accepting a review.
+type DeliveredProductResponse struct {
+	UserID      string `json:"user_id"`
+	ProductCode string `json:"product_code"`
+	Delivered   bool   `json:"delivered"`
+}
+
 type UpdateOrderStatusRequest struct {
 	Status enums.OrderStatus `json:"status" binding:"required"`
 }
@@ -2,8 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/httpx"
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
 	"github.com/dinosgnk/agora-project/internal/services/order/dto"
 	"github.com/dinosgnk/agora-project/internal/services/order/service"
@@ -27,6 +30,7 @@ func (h *OrderHandler) RegisterRoutes(mux *http.ServeMux) http.Handler {
 	mux.HandleFunc("GET /orders", h.GetAllOrders)
 	mux.HandleFunc("GET /orders/user/{userId}/summary", h.GetAllOrderSummariesByUserID)
 	mux.HandleFunc("GET /orders/user/{userId}", h.GetAllOrdersByUserID)
+	mux.HandleFunc("GET /orders/user/{userId}/delivered/{productCode}", h.GetDeliveredProduct)
 	mux.HandleFunc("GET /orders/order/{orderId}/summary", h.GetOrderSummaryByID)
 	mux.HandleFunc("GET /orders/order/{orderId}/products", h.GetProductsByOrderID)
 	mux.HandleFunc("GET /orders/order/{orderId}", h.GetOrderByID)
@@ -45,8 +49,17 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 
 	createdOrder, err := h.service.CreateOrder(&orderReq)
 	if err != nil {
-		h.log.Error("Failed to create order", "user_id", orderReq.UserID, "error", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, service.ErrInvalidCoupon):
+			h.log.Warn("Rejected order discounts", "user_id", orderReq.UserID, "error", err.Error())
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case errors.Is(err, service.ErrCouponsUnavailable):
+			h.log.Warn("Rejected order discounts", "user_id", orderReq.UserID, "error", err.Error())
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		default:
+			h.log.Error("Failed to create order", "user_id", orderReq.UserID, "error", err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -63,9 +76,9 @@ func (h *OrderHandler) GetAllOrderSummaries(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(orders)
+	if err := httpx.WriteConditionalListJSON(w, r, orders); err != nil {
+		h.log.Error("Failed to write orders summary response", "error", err.Error())
+	}
 }
 
 func (h *OrderHandler) GetAllOrders(w http.ResponseWriter, r *http.Request) {
@@ -76,9 +89,9 @@ func (h *OrderHandler) GetAllOrders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(orders)
+	if err := httpx.WriteConditionalListJSON(w, r, orders); err != nil {
+		h.log.Error("Failed to write orders response", "error", err.Error())
+	}
 }
 
 func (h *OrderHandler) GetOrderSummaryByID(w http.ResponseWriter, r *http.Request) {
@@ -91,9 +104,9 @@ func (h *OrderHandler) GetOrderSummaryByID(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(order)
+	if err := httpx.WriteConditionalJSON(w, r, order, order.UpdatedAt); err != nil {
+		h.log.Error("Failed to write order summary response", "order_id", orderId, "error", err.Error())
+	}
 }
 
 func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
@@ -106,9 +119,9 @@ func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(order)
+	if err := httpx.WriteConditionalJSON(w, r, order, order.UpdatedAt); err != nil {
+		h.log.Error("Failed to write order response", "order_id", orderId, "error", err.Error())
+	}
 }
 
 func (h *OrderHandler) GetAllOrderSummariesByUserID(w http.ResponseWriter, r *http.Request) {
@@ -121,9 +134,9 @@ func (h *OrderHandler) GetAllOrderSummariesByUserID(w http.ResponseWriter, r *ht
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(orders)
+	if err := httpx.WriteConditionalListJSON(w, r, orders); err != nil {
+		h.log.Error("Failed to write orders summary response", "user_id", userId, "error", err.Error())
+	}
 }
 
 func (h *OrderHandler) GetAllOrdersByUserID(w http.ResponseWriter, r *http.Request) {
@@ -136,9 +149,25 @@ func (h *OrderHandler) GetAllOrdersByUserID(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if err := httpx.WriteConditionalListJSON(w, r, orders); err != nil {
+		h.log.Error("Failed to write orders response", "user_id", userId, "error", err.Error())
+	}
+}
+
+func (h *OrderHandler) GetDeliveredProduct(w http.ResponseWriter, r *http.Request) {
+	userId := r.PathValue("userId")
+	productCode := r.PathValue("productCode")
+
+	delivered, err := h.service.GetDeliveredProduct(userId, productCode)
+	if err != nil {
+		h.log.Error("Failed to check delivered product", "user_id", userId, "product_code", productCode, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(orders)
+	json.NewEncoder(w).Encode(delivered)
 }
 
 func (h *OrderHandler) GetProductsByOrderID(w http.ResponseWriter, r *http.Request) {
@@ -150,9 +179,9 @@ func (h *OrderHandler) GetProductsByOrderID(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(products)
+	if err := httpx.WriteConditionalJSON(w, r, products, time.Time{}); err != nil {
+		h.log.Error("Failed to write ordered products response", "order_id", orderId, "error", err.Error())
+	}
 }
 
 func (h *OrderHandler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
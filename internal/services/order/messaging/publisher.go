@@ -2,22 +2,23 @@ package messaging
 
 import (
 	"fmt"
-	"time"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
 	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
-	"github.com/google/uuid"
 )
 
-const (
-	OrderExchange = "orders"
-)
+// producer identifies the service in the envelope of its events
+const producer = "order-service"
 
+// Publisher publishes order events. The events of an order share the order
+// ID as their correlation ID, so that the status changes can be traced back
+// to the order.created event.
 type Publisher struct {
-	client *rabbitmq.RabbitMQClient
+	client rabbitmq.Broker
 }
 
-func NewPublisher(client *rabbitmq.RabbitMQClient) (*Publisher, error) {
-	if err := client.DeclareExchange(OrderExchange, "topic"); err != nil {
+func NewPublisher(client rabbitmq.Broker) (*Publisher, error) {
+	if err := client.DeclareExchange(events.OrdersExchange, "topic"); err != nil {
 		return nil, fmt.Errorf("failed to declare exchange: %w", err)
 	}
 
@@ -26,58 +27,30 @@ func NewPublisher(client *rabbitmq.RabbitMQClient) (*Publisher, error) {
 	}, nil
 }
 
-func (p *Publisher) PublishOrderCreated(event *OrderCreatedEvent) error {
-	event.EventID = uuid.New().String()
-	event.Timestamp = time.Now()
-	routingKey := "order.created"
-
-	return p.client.PublishMessage(OrderExchange, routingKey, event)
+func (p *Publisher) PublishOrderCreated(event *events.OrderCreatedEvent) error {
+	return p.client.PublishMessage(events.OrdersExchange, events.OrderCreated, events.New(producer, *event, events.WithCorrelationID(event.OrderID)))
 }
 
-func (p *Publisher) PublishOrderStatusUpdated(event *OrderStatusUpdatedEvent) error {
-	event.EventID = uuid.New().String()
-	event.Timestamp = time.Now()
-	routingKey := "order.status.updated"
-
-	return p.client.PublishMessage(OrderExchange, routingKey, event)
+func (p *Publisher) PublishOrderStatusUpdated(event *events.OrderStatusUpdatedEvent) error {
+	return p.client.PublishMessage(events.OrdersExchange, events.OrderStatusUpdated, events.New(producer, *event, events.WithCorrelationID(event.OrderID)))
 }
 
-func (p *Publisher) PublishOrderConfirmed(event *OrderConfirmedEvent) error {
-	event.EventID = uuid.New().String()
-	event.Timestamp = time.Now()
-	routingKey := "order.confirmed"
-
-	return p.client.PublishMessage(OrderExchange, routingKey, event)
+func (p *Publisher) PublishOrderConfirmed(event *events.OrderConfirmedEvent) error {
+	return p.client.PublishMessage(events.OrdersExchange, events.OrderConfirmed, events.New(producer, *event, events.WithCorrelationID(event.OrderID)))
 }
 
-func (p *Publisher) PublishOrderProcessing(event *OrderProcessingEvent) error {
-	event.EventID = uuid.New().String()
-	event.Timestamp = time.Now()
-	routingKey := "order.processing"
-
-	return p.client.PublishMessage(OrderExchange, routingKey, event)
+func (p *Publisher) PublishOrderProcessing(event *events.OrderProcessingEvent) error {
+	return p.client.PublishMessage(events.OrdersExchange, events.OrderProcessing, events.New(producer, *event, events.WithCorrelationID(event.OrderID)))
 }
 
-func (p *Publisher) PublishOrderShipped(event *OrderShippedEvent) error {
-	event.EventID = uuid.New().String()
-	event.Timestamp = time.Now()
-	routingKey := "order.shipped"
-
-	return p.client.PublishMessage(OrderExchange, routingKey, event)
+func (p *Publisher) PublishOrderShipped(event *events.OrderShippedEvent) error {
+	return p.client.PublishMessage(events.OrdersExchange, events.OrderShipped, events.New(producer, *event, events.WithCorrelationID(event.OrderID)))
 }
 
-func (p *Publisher) PublishOrderDelivered(event *OrderDeliveredEvent) error {
-	event.EventID = uuid.New().String()
-	event.Timestamp = time.Now()
-	routingKey := "order.delivered"
-
-	return p.client.PublishMessage(OrderExchange, routingKey, event)
+func (p *Publisher) PublishOrderDelivered(event *events.OrderDeliveredEvent) error {
+	return p.client.PublishMessage(events.OrdersExchange, events.OrderDelivered, events.New(producer, *event, events.WithCorrelationID(event.OrderID)))
 }
 
-func (p *Publisher) PublishOrderCancelled(event *OrderCancelledEvent) error {
-	event.EventID = uuid.New().String()
-	event.Timestamp = time.Now()
-	routingKey := "order.cancelled"
-
-	return p.client.PublishMessage(OrderExchange, routingKey, event)
+func (p *Publisher) PublishOrderCancelled(event *events.OrderCancelledEvent) error {
+	return p.client.PublishMessage(events.OrdersExchange, events.OrderCancelled, events.New(producer, *event, events.WithCorrelationID(event.OrderID)))
 }
@@ -3,6 +3,7 @@ package model
 import (
 	"time"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
 	"github.com/dinosgnk/agora-project/internal/services/order/enums"
 )
 
@@ -10,6 +11,7 @@ type Order struct {
 	ID              string            `gorm:"primaryKey;column:id"`
 	UserID          string            `gorm:"column:user_id"`
 	Status          enums.OrderStatus `gorm:"column:status"`
+	DiscountAmount  float64           `gorm:"column:discount_amount"`
 	TotalAmount     float64           `gorm:"column:total_amount"`
 	ShippingAddress string            `gorm:"column:shipping_address"`
 	PaymentMethod   string            `gorm:"column:payment_method"`
@@ -22,21 +24,40 @@ func (Order) TableName() string {
 }
 
 type OrderedProduct struct {
-	ID           string    `gorm:"primaryKey;column:id"`
-	OrderID      string    `gorm:"column:order_id"`
-	ProductCode  string    `gorm:"column:code"`
-	ProductName  string    `gorm:"column:product_name"`
-	ProductPrice float64   `gorm:"column:price"`
-	Quantity     int       `gorm:"column:quantity"`
-	Price        float64   `gorm:"column:price"`
-	Subtotal     float64   `gorm:"column:subtotal"`
-	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	ID           string           `gorm:"primaryKey;column:id"`
+	OrderID      string           `gorm:"column:order_id"`
+	ProductCode  string           `gorm:"column:code"`
+	SKU          string           `gorm:"column:sku"`
+	ProductName  string           `gorm:"column:product_name"`
+	Attributes   postgres.JSONMap `gorm:"column:attributes"`
+	ProductPrice float64          `gorm:"column:price"`
+	Quantity     int              `gorm:"column:quantity"`
+	Price        float64          `gorm:"column:price"`
+	Subtotal     float64          `gorm:"column:subtotal"`
+	CreatedAt    time.Time        `gorm:"column:created_at;autoCreateTime"`
 }
 
 func (OrderedProduct) TableName() string {
 	return "orders.t_ordered_product"
 }
 
+// CouponRedemption records a coupon used on an order. Cart-service counts
+// these rows to enforce per-user usage limits. MaxUsesPerUser is the limit of
+// the promotion, which is checked when the redemption is stored.
+type CouponRedemption struct {
+	ID             string    `gorm:"primaryKey;column:id"`
+	CouponCode     string    `gorm:"column:coupon_code"`
+	UserID         string    `gorm:"column:user_id"`
+	OrderID        string    `gorm:"column:order_id"`
+	Amount         float64   `gorm:"column:amount"`
+	RedeemedAt     time.Time `gorm:"column:redeemed_at;autoCreateTime"`
+	MaxUsesPerUser int       `gorm:"-"`
+}
+
+func (CouponRedemption) TableName() string {
+	return "promotions.t_coupon_redemption"
+}
+
 type OrderWithProducts struct {
 	Order    Order
 	Products []*OrderedProduct
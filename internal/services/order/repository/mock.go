@@ -1,17 +1,21 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
 	"github.com/dinosgnk/agora-project/internal/services/order/enums"
 	"github.com/dinosgnk/agora-project/internal/services/order/model"
 )
 
 type MockOrderRepository struct {
-	orders   map[string]*model.Order
-	products map[string][]*model.OrderedProduct
-	mutex    sync.RWMutex
+	orders      map[string]*model.Order
+	products    map[string][]*model.OrderedProduct
+	redemptions []*model.CouponRedemption
+	mutex       sync.RWMutex
 }
 
 func NewMockOrderRepository() *MockOrderRepository {
@@ -21,15 +25,39 @@ func NewMockOrderRepository() *MockOrderRepository {
 	}
 }
 
-func (repo *MockOrderRepository) CreateOrder(order *model.Order, products []*model.OrderedProduct) (*model.Order, error) {
+func (repo *MockOrderRepository) CreateOrder(order *model.Order, products []*model.OrderedProduct, redemptions []*model.CouponRedemption) (*model.Order, error) {
 	repo.mutex.Lock()
 	defer repo.mutex.Unlock()
 
+	for _, redemption := range redemptions {
+		if redemption.MaxUsesPerUser > 0 && repo.countRedemptions(redemption) >= redemption.MaxUsesPerUser {
+			return nil, promotions.Invalid("coupon %s usage limit reached", redemption.CouponCode)
+		}
+	}
+
 	repo.orders[order.ID] = order
 	repo.products[order.ID] = products
+	repo.redemptions = append(repo.redemptions, redemptions...)
 	return order, nil
 }
 
+func (repo *MockOrderRepository) countRedemptions(redemption *model.CouponRedemption) int {
+	count := 0
+	for _, existing := range repo.redemptions {
+		if strings.EqualFold(existing.CouponCode, redemption.CouponCode) && existing.UserID == redemption.UserID {
+			count++
+		}
+	}
+	return count
+}
+
+func (repo *MockOrderRepository) GetRedemptions() []*model.CouponRedemption {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	return repo.redemptions
+}
+
 func (repo *MockOrderRepository) GetAllOrderSummaries() ([]*model.Order, error) {
 	repo.mutex.RLock()
 	defer repo.mutex.RUnlock()
@@ -133,6 +161,24 @@ func (repo *MockOrderRepository) GetProductsByOrderID(orderId string) ([]*model.
 	return products, nil
 }
 
+func (repo *MockOrderRepository) HasDeliveredProduct(userId string, productCode string) (bool, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	for _, order := range repo.orders {
+		if order.UserID != userId || order.Status != enums.OrderStatusDelivered {
+			continue
+		}
+		for _, product := range repo.products[order.ID] {
+			if product.ProductCode == productCode {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 func (repo *MockOrderRepository) UpdateOrderStatus(orderId string, status enums.OrderStatus) error {
 	repo.mutex.Lock()
 	defer repo.mutex.Unlock()
@@ -145,3 +191,58 @@ func (repo *MockOrderRepository) UpdateOrderStatus(orderId string, status enums.
 	order.Status = status
 	return nil
 }
+
+// MockPromotionRepository serves promotions and product categories. Usage
+// limits are enforced by MockOrderRepository when redemptions are stored.
+type MockPromotionRepository struct {
+	promotions map[string]*promotions.Promotion
+	categories map[string]string
+	mutex      sync.RWMutex
+}
+
+func NewMockPromotionRepository() *MockPromotionRepository {
+	return &MockPromotionRepository{
+		promotions: make(map[string]*promotions.Promotion),
+		categories: make(map[string]string),
+	}
+}
+
+func (repo *MockPromotionRepository) AddPromotion(promotion *promotions.Promotion) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	repo.promotions[strings.ToUpper(promotion.Code)] = promotion
+}
+
+func (repo *MockPromotionRepository) SetProductCategory(productCode string, category string) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	repo.categories[productCode] = category
+}
+
+func (repo *MockPromotionRepository) GetPromotionByCode(code string) (*promotions.Promotion, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	promotion, exists := repo.promotions[strings.ToUpper(code)]
+	if !exists {
+		return nil, errors.New("promotion not found")
+	}
+
+	return promotion, nil
+}
+
+func (repo *MockPromotionRepository) GetProductCategories(productCodes []string) (map[string]string, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	categories := make(map[string]string, len(productCodes))
+	for _, productCode := range productCodes {
+		if category, exists := repo.categories[productCode]; exists {
+			categories[productCode] = category
+		}
+	}
+
+	return categories, nil
+}
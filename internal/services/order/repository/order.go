@@ -6,7 +6,7 @@ import (
 )
 
 type IOrderRepository interface {
-	CreateOrder(order *model.Order, products []*model.OrderedProduct) (*model.Order, error)
+	CreateOrder(order *model.Order, products []*model.OrderedProduct, redemptions []*model.CouponRedemption) (*model.Order, error)
 	GetAllOrderSummaries() ([]*model.Order, error)
 	GetAllOrders() ([]*model.OrderWithProducts, error)
 	GetOrderSummaryByID(orderId string) (*model.Order, error)
@@ -14,5 +14,8 @@ type IOrderRepository interface {
 	GetAllOrderSummariesByUserID(userId string) ([]*model.Order, error)
 	GetAllOrdersByUserID(userId string) ([]*model.OrderWithProducts, error)
 	GetProductsByOrderID(orderId string) ([]*model.OrderedProduct, error)
+	// HasDeliveredProduct reports whether the user has a delivered order
+	// containing the product
+	HasDeliveredProduct(userId string, productCode string) (bool, error)
 	UpdateOrderStatus(orderId string, status enums.OrderStatus) error
 }
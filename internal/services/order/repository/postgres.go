@@ -5,6 +5,7 @@ import (
 
 	"github.com/dinosgnk/agora-project/internal/pkg/logger"
 	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
 	"github.com/dinosgnk/agora-project/internal/services/order/enums"
 	"github.com/dinosgnk/agora-project/internal/services/order/model"
 	"gorm.io/gorm"
@@ -35,7 +36,11 @@ func NewPostgresOrderRepository(logger logger.Logger) *PostgresOrderRepository {
 	}
 }
 
-func (repo *PostgresOrderRepository) CreateOrder(order *model.Order, products []*model.OrderedProduct) (*model.Order, error) {
+// CreateOrder stores an order with its products and coupon redemptions in one
+// transaction. The promotion of a redemption with a per-user limit is locked
+// while the user's redemptions are counted, so concurrent orders cannot both
+// use up the last allowed redemption.
+func (repo *PostgresOrderRepository) CreateOrder(order *model.Order, products []*model.OrderedProduct, redemptions []*model.CouponRedemption) (*model.Order, error) {
 	tx := repo.gormDb.Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -53,6 +58,17 @@ func (repo *PostgresOrderRepository) CreateOrder(order *model.Order, products []
 		}
 	}
 
+	for _, redemption := range redemptions {
+		if err := checkRedemptionLimit(tx, redemption); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Create(redemption).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
@@ -60,6 +76,29 @@ func (repo *PostgresOrderRepository) CreateOrder(order *model.Order, products []
 	return repo.GetOrderSummaryByID(order.ID)
 }
 
+func checkRedemptionLimit(tx *gorm.DB, redemption *model.CouponRedemption) error {
+	if redemption.MaxUsesPerUser <= 0 {
+		return nil
+	}
+
+	err := tx.Exec("SELECT 1 FROM promotions.t_promotion WHERE code = ? FOR UPDATE", redemption.CouponCode).Error
+	if err != nil {
+		return err
+	}
+
+	var used int64
+	err = tx.Model(&model.CouponRedemption{}).
+		Where("UPPER(coupon_code) = UPPER(?) AND user_id = ?", redemption.CouponCode, redemption.UserID).
+		Count(&used).Error
+	if err != nil {
+		return err
+	}
+	if int(used) >= redemption.MaxUsesPerUser {
+		return promotions.Invalid("coupon %s usage limit reached", redemption.CouponCode)
+	}
+	return nil
+}
+
 func (repo *PostgresOrderRepository) GetAllOrderSummaries() ([]*model.Order, error) {
 	var orders []*model.Order
 	result := repo.gormDb.Find(&orders)
@@ -161,6 +200,21 @@ func (repo *PostgresOrderRepository) GetProductsByOrderID(orderId string) ([]*mo
 	return orderedProducts, nil
 }
 
+func (repo *PostgresOrderRepository) HasDeliveredProduct(userId string, productCode string) (bool, error) {
+	var delivered bool
+	err := repo.gormDb.Raw(`
+		SELECT EXISTS (
+			SELECT 1 FROM orders.t_order o
+			JOIN orders.t_ordered_product p ON p.order_id = o.id
+			WHERE o.user_id = ? AND o.status = ? AND p.code = ?
+		)`, userId, enums.OrderStatusDelivered, productCode).Scan(&delivered).Error
+	if err != nil {
+		return false, err
+	}
+
+	return delivered, nil
+}
+
 func (repo *PostgresOrderRepository) UpdateOrderStatus(orderId string, status enums.OrderStatus) error {
 	result := repo.gormDb.Model(&model.Order{}).Where("id = ?", orderId).Update("status", status)
 	if result.Error != nil {
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+)
+
+// IPromotionRepository reads the promotions cart-service manages, so that
+// order-service charges the discounts of the coupons an order is placed with.
+type IPromotionRepository interface {
+	GetPromotionByCode(code string) (*promotions.Promotion, error)
+	// GetProductCategories returns the catalog category of each product code
+	// that category-wide promotions are checked against
+	GetProductCategories(productCodes []string) (map[string]string, error)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/postgres"
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type PostgresPromotionRepository struct {
+	gormDb *postgres.GormDatabase
+}
+
+func NewPostgresPromotionRepository(logger logger.Logger) *PostgresPromotionRepository {
+	gormDb, err := postgres.NewGormDatabase(
+		logger,
+		&gorm.Config{
+			NamingStrategy: schema.NamingStrategy{
+				TablePrefix:   "promotions.t_",
+				SingularTable: true,
+			},
+		},
+	)
+
+	if err != nil {
+		return nil
+	}
+
+	return &PostgresPromotionRepository{
+		gormDb: gormDb,
+	}
+}
+
+func (repo *PostgresPromotionRepository) GetPromotionByCode(code string) (*promotions.Promotion, error) {
+	var promotion promotions.Promotion
+	result := repo.gormDb.Where("UPPER(code) = UPPER(?)", code).First(&promotion)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &promotion, nil
+}
+
+func (repo *PostgresPromotionRepository) GetProductCategories(productCodes []string) (map[string]string, error) {
+	var rows []struct {
+		ProductCode string
+		Category    string
+	}
+	err := repo.gormDb.Raw(`
+		SELECT product_code, COALESCE(category, '') AS category
+		FROM products.t_product
+		WHERE product_code IN ?`, productCodes).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string]string, len(rows))
+	for _, row := range rows {
+		categories[row.ProductCode] = row.Category
+	}
+
+	return categories, nil
+}
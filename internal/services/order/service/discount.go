@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+	"github.com/dinosgnk/agora-project/internal/services/order/dto"
+	"github.com/dinosgnk/agora-project/internal/services/order/model"
+)
+
+var (
+	ErrCouponsUnavailable = errors.New("coupons are not available")
+	ErrInvalidCoupon      = promotions.ErrInvalidCoupon
+)
+
+// couponRedemptions computes the discount of each coupon the order is placed
+// with from the stored promotion, rejecting coupons the user cannot use.
+func (s *OrderService) couponRedemptions(orderReq *dto.CreateOrderRequest, orderId string) ([]*model.CouponRedemption, error) {
+	if len(orderReq.Discounts) == 0 {
+		return nil, nil
+	}
+	if s.promotionRepo == nil {
+		return nil, ErrCouponsUnavailable
+	}
+
+	items, err := s.promotionItems(orderReq.Products)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	redemptions := make([]*model.CouponRedemption, 0, len(orderReq.Discounts))
+	for i, discount := range orderReq.Discounts {
+		for _, previous := range orderReq.Discounts[:i] {
+			if strings.EqualFold(previous.CouponCode, discount.CouponCode) {
+				return nil, promotions.Invalid("coupon %s is applied more than once", discount.CouponCode)
+			}
+		}
+
+		promotion, err := s.promotionRepo.GetPromotionByCode(discount.CouponCode)
+		if err != nil {
+			return nil, promotions.Invalid("coupon %s does not exist", discount.CouponCode)
+		}
+
+		// The per-user limit is checked when the redemption is stored, in
+		// the transaction that creates the order
+		if err := promotions.Validate(promotion, items, 0, now); err != nil {
+			return nil, err
+		}
+
+		redemptions = append(redemptions, &model.CouponRedemption{
+			ID:             uuid.New().String(),
+			CouponCode:     promotion.Code,
+			UserID:         orderReq.UserID,
+			OrderID:        orderId,
+			Amount:         promotions.Discount(promotion, items),
+			MaxUsesPerUser: promotion.MaxUsesPerUser,
+		})
+	}
+
+	return redemptions, nil
+}
+
+// promotionItems looks up the catalog category of the ordered products, which
+// category-wide promotions apply to.
+func (s *OrderService) promotionItems(products []*dto.OrderedProduct) ([]promotions.Item, error) {
+	productCodes := make([]string, 0, len(products))
+	for _, product := range products {
+		productCodes = append(productCodes, product.ProductCode)
+	}
+
+	categories, err := s.promotionRepo.GetProductCategories(productCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]promotions.Item, 0, len(products))
+	for _, product := range products {
+		items = append(items, promotions.Item{
+			ProductCode: product.ProductCode,
+			Category:    categories[product.ProductCode],
+			Quantity:    product.Quantity,
+			Price:       product.Price,
+		})
+	}
+
+	return items, nil
+}
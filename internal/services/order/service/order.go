@@ -2,9 +2,11 @@ package service
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/google/uuid"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
 	"github.com/dinosgnk/agora-project/internal/services/order/dto"
 	"github.com/dinosgnk/agora-project/internal/services/order/enums"
 	"github.com/dinosgnk/agora-project/internal/services/order/messaging"
@@ -21,18 +23,23 @@ type IOrderService interface {
 	GetAllOrderSummariesByUserID(userId string) ([]*dto.OrderSummaryResponse, error)
 	GetAllOrdersByUserID(userId string) ([]*dto.OrderResponse, error)
 	GetProductsByOrderID(orderId string) ([]*dto.OrderedProduct, error)
+	GetDeliveredProduct(userId string, productCode string) (*dto.DeliveredProductResponse, error)
 	UpdateOrderStatus(orderId string, statusReq *dto.UpdateOrderStatusRequest) error
 }
 
 type OrderService struct {
-	repo      repository.IOrderRepository
-	publisher *messaging.Publisher
+	repo          repository.IOrderRepository
+	promotionRepo repository.IPromotionRepository
+	publisher     *messaging.Publisher
 }
 
-func NewOrderService(repo repository.IOrderRepository, publisher *messaging.Publisher) *OrderService {
+// NewOrderService creates an OrderService. Orders placed with a coupon are
+// rejected when promotionRepo is nil.
+func NewOrderService(repo repository.IOrderRepository, promotionRepo repository.IPromotionRepository, publisher *messaging.Publisher) *OrderService {
 	return &OrderService{
-		repo:      repo,
-		publisher: publisher,
+		repo:          repo,
+		promotionRepo: promotionRepo,
+		publisher:     publisher,
 	}
 }
 
@@ -49,40 +56,56 @@ func (s *OrderService) CreateOrder(orderReq *dto.CreateOrderRequest) (*dto.Order
 			ID:          uuid.New().String(),
 			OrderID:     orderId,
 			ProductCode: product.ProductCode,
+			SKU:         product.SKU,
 			ProductName: product.ProductName,
+			Attributes:  maps.Clone(product.Attributes),
 			Quantity:    product.Quantity,
 			Price:       product.Price,
 			Subtotal:    subtotal,
 		})
 	}
 
+	redemptions, err := s.couponRedemptions(orderReq, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	var discountAmount float64
+	for _, redemption := range redemptions {
+		discountAmount += redemption.Amount
+	}
+	discountAmount = min(discountAmount, totalAmount)
+
 	order := &model.Order{
 		ID:              orderId,
 		UserID:          orderReq.UserID,
 		Status:          enums.OrderStatusPending,
-		TotalAmount:     totalAmount,
+		DiscountAmount:  discountAmount,
+		TotalAmount:     totalAmount - discountAmount,
 		ShippingAddress: orderReq.ShippingAddress,
 		PaymentMethod:   orderReq.PaymentMethod,
 	}
 
-	createdOrder, err := s.repo.CreateOrder(order, orderProducts)
+	createdOrder, err := s.repo.CreateOrder(order, orderProducts, redemptions)
 	if err != nil {
 		return nil, err
 	}
 
 	if s.publisher != nil {
-		eventProducts := make([]messaging.OrderCreatedProduct, 0, len(orderReq.Products))
+		eventProducts := make([]events.OrderCreatedProduct, 0, len(orderReq.Products))
 		for _, p := range orderReq.Products {
-			eventProducts = append(eventProducts, messaging.OrderCreatedProduct{
+			eventProducts = append(eventProducts, events.OrderCreatedProduct{
 				ProductCode: p.ProductCode,
+				SKU:         p.SKU,
 				ProductName: p.ProductName,
+				Attributes:  p.Attributes,
 				Quantity:    p.Quantity,
 				Price:       p.Price,
 			})
 		}
 
-		orderCreatedEvent := &messaging.OrderCreatedEvent{
-			OrderEvent: messaging.OrderEvent{
+		orderCreatedEvent := &events.OrderCreatedEvent{
+			OrderEvent: events.OrderEvent{
 				OrderID: createdOrder.ID,
 				UserID:  createdOrder.UserID,
 			},
@@ -101,6 +124,7 @@ func (s *OrderService) CreateOrder(orderReq *dto.CreateOrderRequest) (*dto.Order
 		OrderID:         createdOrder.ID,
 		UserID:          createdOrder.UserID,
 		Status:          createdOrder.Status,
+		DiscountAmount:  createdOrder.DiscountAmount,
 		TotalAmount:     createdOrder.TotalAmount,
 		ShippingAddress: createdOrder.ShippingAddress,
 		PaymentMethod:   createdOrder.PaymentMethod,
@@ -123,6 +147,7 @@ func (s *OrderService) GetAllOrderSummaries() ([]*dto.OrderSummaryResponse, erro
 			OrderID:         order.ID,
 			UserID:          order.UserID,
 			Status:          order.Status,
+			DiscountAmount:  order.DiscountAmount,
 			TotalAmount:     order.TotalAmount,
 			ShippingAddress: order.ShippingAddress,
 			PaymentMethod:   order.PaymentMethod,
@@ -146,7 +171,9 @@ func (s *OrderService) GetAllOrders() ([]*dto.OrderResponse, error) {
 		for _, p := range order.Products {
 			orderedProducts = append(orderedProducts, &dto.OrderedProduct{
 				ProductCode: p.ProductCode,
+				SKU:         p.SKU,
 				ProductName: p.ProductName,
+				Attributes:  p.Attributes,
 				Quantity:    p.Quantity,
 				Price:       p.Price,
 			})
@@ -156,6 +183,7 @@ func (s *OrderService) GetAllOrders() ([]*dto.OrderResponse, error) {
 			OrderID:         order.Order.ID,
 			UserID:          order.Order.UserID,
 			Status:          order.Order.Status,
+			DiscountAmount:  order.Order.DiscountAmount,
 			TotalAmount:     order.Order.TotalAmount,
 			ShippingAddress: order.Order.ShippingAddress,
 			PaymentMethod:   order.Order.PaymentMethod,
@@ -178,6 +206,7 @@ func (s *OrderService) GetOrderSummaryByID(orderId string) (*dto.OrderSummaryRes
 		OrderID:         order.ID,
 		UserID:          order.UserID,
 		Status:          order.Status,
+		DiscountAmount:  order.DiscountAmount,
 		TotalAmount:     order.TotalAmount,
 		ShippingAddress: order.ShippingAddress,
 		PaymentMethod:   order.PaymentMethod,
@@ -202,7 +231,9 @@ func (s *OrderService) GetOrderByID(orderId string) (*dto.OrderResponse, error)
 	for _, p := range products {
 		orderedProducts = append(orderedProducts, &dto.OrderedProduct{
 			ProductCode: p.ProductCode,
+			SKU:         p.SKU,
 			ProductName: p.ProductName,
+			Attributes:  p.Attributes,
 			Quantity:    p.Quantity,
 			Price:       p.Price,
 		})
@@ -212,6 +243,7 @@ func (s *OrderService) GetOrderByID(orderId string) (*dto.OrderResponse, error)
 		OrderID:         order.Order.ID,
 		UserID:          order.Order.UserID,
 		Status:          order.Order.Status,
+		DiscountAmount:  order.Order.DiscountAmount,
 		TotalAmount:     order.Order.TotalAmount,
 		ShippingAddress: order.Order.ShippingAddress,
 		PaymentMethod:   order.Order.PaymentMethod,
@@ -234,6 +266,7 @@ func (s *OrderService) GetAllOrderSummariesByUserID(userId string) ([]*dto.Order
 			OrderID:         order.ID,
 			UserID:          order.UserID,
 			Status:          order.Status,
+			DiscountAmount:  order.DiscountAmount,
 			TotalAmount:     order.TotalAmount,
 			ShippingAddress: order.ShippingAddress,
 			PaymentMethod:   order.PaymentMethod,
@@ -258,7 +291,9 @@ func (s *OrderService) GetAllOrdersByUserID(userId string) ([]*dto.OrderResponse
 		for _, p := range order.Products {
 			orderedProducts = append(orderedProducts, &dto.OrderedProduct{
 				ProductCode: p.ProductCode,
+				SKU:         p.SKU,
 				ProductName: p.ProductName,
+				Attributes:  p.Attributes,
 				Quantity:    p.Quantity,
 				Price:       p.Price,
 			})
@@ -268,6 +303,7 @@ func (s *OrderService) GetAllOrdersByUserID(userId string) ([]*dto.OrderResponse
 			OrderID:         order.Order.ID,
 			UserID:          order.Order.UserID,
 			Status:          order.Order.Status,
+			DiscountAmount:  order.Order.DiscountAmount,
 			TotalAmount:     order.Order.TotalAmount,
 			ShippingAddress: order.Order.ShippingAddress,
 			PaymentMethod:   order.Order.PaymentMethod,
@@ -290,7 +326,9 @@ func (s *OrderService) GetProductsByOrderID(orderId string) ([]*dto.OrderedProdu
 	for _, p := range products {
 		orderProducts = append(orderProducts, &dto.OrderedProduct{
 			ProductCode: p.ProductCode,
+			SKU:         p.SKU,
 			ProductName: p.ProductName,
+			Attributes:  p.Attributes,
 			Quantity:    p.Quantity,
 			Price:       p.Price,
 		})
@@ -299,6 +337,19 @@ func (s *OrderService) GetProductsByOrderID(orderId string) ([]*dto.OrderedProdu
 	return orderProducts, nil
 }
 
+func (s *OrderService) GetDeliveredProduct(userId string, productCode string) (*dto.DeliveredProductResponse, error) {
+	delivered, err := s.repo.HasDeliveredProduct(userId, productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DeliveredProductResponse{
+		UserID:      userId,
+		ProductCode: productCode,
+		Delivered:   delivered,
+	}, nil
+}
+
 func (s *OrderService) UpdateOrderStatus(orderId string, statusReq *dto.UpdateOrderStatusRequest) error {
 	order, err := s.repo.GetOrderSummaryByID(orderId)
 	if err != nil {
@@ -322,13 +373,13 @@ func (s *OrderService) UpdateOrderStatus(orderId string, statusReq *dto.UpdateOr
 
 	// Publish events based on new status
 	if s.publisher != nil {
-		baseEvent := messaging.OrderEvent{
+		baseEvent := events.OrderEvent{
 			OrderID: orderId,
 			UserID:  order.UserID,
 		}
 
 		// Always publish status updated event
-		statusUpdatedEvent := &messaging.OrderStatusUpdatedEvent{
+		statusUpdatedEvent := &events.OrderStatusUpdatedEvent{
 			OrderEvent: baseEvent,
 			OldStatus:  string(oldStatus),
 			NewStatus:  string(statusReq.Status),
@@ -340,7 +391,7 @@ func (s *OrderService) UpdateOrderStatus(orderId string, statusReq *dto.UpdateOr
 		// Publish specific status events
 		switch statusReq.Status {
 		case enums.OrderStatusConfirmed:
-			event := &messaging.OrderConfirmedEvent{
+			event := &events.OrderConfirmedEvent{
 				OrderEvent:    baseEvent,
 				PaymentMethod: order.PaymentMethod,
 				TotalAmount:   order.TotalAmount,
@@ -350,7 +401,7 @@ func (s *OrderService) UpdateOrderStatus(orderId string, statusReq *dto.UpdateOr
 			}
 
 		case enums.OrderStatusProcessing:
-			event := &messaging.OrderProcessingEvent{
+			event := &events.OrderProcessingEvent{
 				OrderEvent: baseEvent,
 			}
 			if err := s.publisher.PublishOrderProcessing(event); err != nil {
@@ -358,7 +409,7 @@ func (s *OrderService) UpdateOrderStatus(orderId string, statusReq *dto.UpdateOr
 			}
 
 		case enums.OrderStatusShipped:
-			event := &messaging.OrderShippedEvent{
+			event := &events.OrderShippedEvent{
 				OrderEvent: baseEvent,
 			}
 			if err := s.publisher.PublishOrderShipped(event); err != nil {
@@ -366,7 +417,7 @@ func (s *OrderService) UpdateOrderStatus(orderId string, statusReq *dto.UpdateOr
 			}
 
 		case enums.OrderStatusDelivered:
-			event := &messaging.OrderDeliveredEvent{
+			event := &events.OrderDeliveredEvent{
 				OrderEvent: baseEvent,
 			}
 			if err := s.publisher.PublishOrderDelivered(event); err != nil {
@@ -374,7 +425,7 @@ func (s *OrderService) UpdateOrderStatus(orderId string, statusReq *dto.UpdateOr
 			}
 
 		case enums.OrderStatusCancelled:
-			event := &messaging.OrderCancelledEvent{
+			event := &events.OrderCancelledEvent{
 				OrderEvent: baseEvent,
 			}
 			if err := s.publisher.PublishOrderCancelled(event); err != nil {
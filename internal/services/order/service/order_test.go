@@ -1,16 +1,25 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
 
+	"github.com/dinosgnk/agora-project/internal/pkg/clock"
+	"github.com/dinosgnk/agora-project/internal/pkg/events"
+	"github.com/dinosgnk/agora-project/internal/pkg/logger"
+	"github.com/dinosgnk/agora-project/internal/pkg/promotions"
+	"github.com/dinosgnk/agora-project/internal/pkg/rabbitmq"
 	"github.com/dinosgnk/agora-project/internal/services/order/dto"
 	"github.com/dinosgnk/agora-project/internal/services/order/enums"
+	"github.com/dinosgnk/agora-project/internal/services/order/messaging"
 	"github.com/dinosgnk/agora-project/internal/services/order/repository"
 )
 
 func TestDeleteOrderSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
 		Products: []*dto.OrderedProduct{
@@ -69,7 +78,7 @@ func TestDeleteOrderSuccessfully(t *testing.T) {
 
 func TestGetAllOrderSummariesSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq1 := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -103,7 +112,7 @@ func TestGetAllOrderSummariesSuccessfully(t *testing.T) {
 
 func TestGetAllOrdersSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -132,7 +141,7 @@ func TestGetAllOrdersSuccessfully(t *testing.T) {
 
 func TestGetOrderSummaryByIDSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -160,7 +169,7 @@ func TestGetOrderSummaryByIDSuccessfully(t *testing.T) {
 
 func TestGetOrderByIDSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -189,7 +198,7 @@ func TestGetOrderByIDSuccessfully(t *testing.T) {
 
 func TestGetAllOrderSummariesByUserIDSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	userId := "user123"
 
@@ -236,7 +245,7 @@ func TestGetAllOrderSummariesByUserIDSuccessfully(t *testing.T) {
 
 func TestGetAllOrdersByUserIDSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	userId := "user123"
 
@@ -267,7 +276,7 @@ func TestGetAllOrdersByUserIDSuccessfully(t *testing.T) {
 
 func TestGetProductsByOrderIDSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -304,7 +313,7 @@ func TestGetProductsByOrderIDSuccessfully(t *testing.T) {
 
 func TestUpdateOrderStatusWithInvalidTransition(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -332,7 +341,7 @@ func TestUpdateOrderStatusWithInvalidTransition(t *testing.T) {
 
 func TestCancelOrderSuccessfully(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -361,7 +370,7 @@ func TestCancelOrderSuccessfully(t *testing.T) {
 
 func TestCancelOrderFromConfirmedStatus(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -394,7 +403,7 @@ func TestCancelOrderFromConfirmedStatus(t *testing.T) {
 
 func TestCancelOrderWithInvalidStatus(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	orderReq := &dto.CreateOrderRequest{
 		UserID: "user123",
@@ -422,7 +431,7 @@ func TestCancelOrderWithInvalidStatus(t *testing.T) {
 
 func TestCreateOrderCalculatesTotalCorrectly(t *testing.T) {
 	repo := repository.NewMockOrderRepository()
-	svc := NewOrderService(repo, nil)
+	svc := NewOrderService(repo, nil, nil)
 
 	testCases := []struct {
 		name     string
@@ -473,3 +482,294 @@ func TestCreateOrderCalculatesTotalCorrectly(t *testing.T) {
 		})
 	}
 }
+
+func newCouponTestService() (*OrderService, *repository.MockOrderRepository, *repository.MockPromotionRepository) {
+	repo := repository.NewMockOrderRepository()
+	promotionRepo := repository.NewMockPromotionRepository()
+	promotionRepo.AddPromotion(&promotions.Promotion{
+		Code:           "SAVE20",
+		Type:           promotions.TypeFixed,
+		Value:          20,
+		MinSpend:       50,
+		MaxUsesPerUser: 1,
+		Active:         true,
+	})
+	promotionRepo.AddPromotion(&promotions.Promotion{
+		Code:     "BOOKS15",
+		Type:     promotions.TypePercentage,
+		Value:    15,
+		Category: "Books",
+		Active:   true,
+	})
+	return NewOrderService(repo, promotionRepo, nil), repo, promotionRepo
+}
+
+func TestCreateOrderRecordsCouponRedemption(t *testing.T) {
+	svc, repo, _ := newCouponTestService()
+
+	orderReq := &dto.CreateOrderRequest{
+		UserID: "user123",
+		Products: []*dto.OrderedProduct{
+			{ProductCode: "P1", ProductName: "Product 1", Quantity: 2, Price: 50.00},
+		},
+		Discounts: []*dto.AppliedDiscount{
+			{CouponCode: "save20"},
+		},
+		ShippingAddress: "Address 123",
+		PaymentMethod:   "crypto",
+	}
+
+	orderResp, err := svc.CreateOrder(orderReq)
+	if err != nil {
+		t.Fatalf("Expected no error while creating order, got %v", err)
+	}
+
+	if orderResp.DiscountAmount != 20.00 || orderResp.TotalAmount != 80.00 {
+		t.Fatalf("Expected discount 20.00 and total 80.00, got %.2f and %.2f", orderResp.DiscountAmount, orderResp.TotalAmount)
+	}
+
+	redemptions := repo.GetRedemptions()
+	if len(redemptions) != 1 {
+		t.Fatalf("Expected 1 coupon redemption, got %d", len(redemptions))
+	}
+
+	if redemptions[0].OrderID != orderResp.OrderID || redemptions[0].UserID != orderReq.UserID {
+		t.Fatalf("Expected redemption for order %s and user %s, got %s and %s",
+			orderResp.OrderID, orderReq.UserID, redemptions[0].OrderID, redemptions[0].UserID)
+	}
+
+	if redemptions[0].CouponCode != "SAVE20" || redemptions[0].Amount != 20.00 {
+		t.Fatalf("Expected a 20.00 redemption of SAVE20, got %.2f of %s", redemptions[0].Amount, redemptions[0].CouponCode)
+	}
+
+	// SAVE20 can be used once per user
+	if _, err := svc.CreateOrder(orderReq); !errors.Is(err, ErrInvalidCoupon) {
+		t.Fatalf("Expected ErrInvalidCoupon for a used up coupon, got %v", err)
+	}
+}
+
+func TestCreateOrderEnforcesCouponLimitUnderConcurrency(t *testing.T) {
+	svc, repo, _ := newCouponTestService()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.CreateOrder(&dto.CreateOrderRequest{
+				UserID:          "user123",
+				Products:        []*dto.OrderedProduct{{ProductCode: "P1", ProductName: "Product 1", Quantity: 1, Price: 60.00}},
+				Discounts:       []*dto.AppliedDiscount{{CouponCode: "SAVE20"}},
+				ShippingAddress: "Address 123",
+				PaymentMethod:   "crypto",
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else if !errors.Is(err, ErrInvalidCoupon) {
+			t.Errorf("Expected ErrInvalidCoupon, got %v", err)
+		}
+	}
+	// SAVE20 can be used once per user
+	if succeeded != 1 || len(repo.GetRedemptions()) != 1 {
+		t.Fatalf("Expected exactly 1 order to redeem SAVE20, got %d orders and %d redemptions", succeeded, len(repo.GetRedemptions()))
+	}
+}
+
+func TestCreateOrderRejectsUnknownCoupon(t *testing.T) {
+	svc, repo, _ := newCouponTestService()
+
+	orderReq := &dto.CreateOrderRequest{
+		UserID: "user123",
+		Products: []*dto.OrderedProduct{
+			{ProductCode: "P1", ProductName: "Product 1", Quantity: 2, Price: 50.00},
+		},
+		Discounts: []*dto.AppliedDiscount{
+			{CouponCode: "FREE100"},
+		},
+		ShippingAddress: "Address 123",
+		PaymentMethod:   "crypto",
+	}
+
+	if _, err := svc.CreateOrder(orderReq); !errors.Is(err, ErrInvalidCoupon) {
+		t.Fatalf("Expected ErrInvalidCoupon for an unknown coupon, got %v", err)
+	}
+
+	if orders, _ := repo.GetAllOrderSummaries(); len(orders) != 0 || len(repo.GetRedemptions()) != 0 {
+		t.Fatalf("Expected no order to be created, got %d orders", len(orders))
+	}
+}
+
+func TestCreateOrderDiscountsByCatalogCategory(t *testing.T) {
+	svc, _, promotionRepo := newCouponTestService()
+	promotionRepo.SetProductCategory("B1", "Books")
+
+	orderResp, err := svc.CreateOrder(&dto.CreateOrderRequest{
+		UserID: "user123",
+		Products: []*dto.OrderedProduct{
+			{ProductCode: "B1", ProductName: "Book", Quantity: 1, Price: 40.00},
+			{ProductCode: "P1", ProductName: "Product 1", Quantity: 1, Price: 60.00},
+		},
+		Discounts:       []*dto.AppliedDiscount{{CouponCode: "BOOKS15"}},
+		ShippingAddress: "Address 123",
+		PaymentMethod:   "crypto",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error while creating order, got %v", err)
+	}
+
+	if orderResp.DiscountAmount != 6.00 || orderResp.TotalAmount != 94.00 {
+		t.Fatalf("Expected discount 6.00 and total 94.00, got %.2f and %.2f", orderResp.DiscountAmount, orderResp.TotalAmount)
+	}
+}
+
+func TestCreateOrderSnapshotsVariantAttributes(t *testing.T) {
+	repo := repository.NewMockOrderRepository()
+	svc := NewOrderService(repo, nil, nil)
+	attributes := map[string]any{"size": "M", "colour": "red"}
+	orderReq := &dto.CreateOrderRequest{
+		UserID: "user123",
+		Products: []*dto.OrderedProduct{
+			{
+				ProductCode: "TSHIRT",
+				SKU:         "TSHIRT-M-RED",
+				ProductName: "T-Shirt",
+				Attributes:  attributes,
+				Quantity:    1,
+				Price:       19.99,
+			},
+		},
+		ShippingAddress: "Address 123",
+		PaymentMethod:   "crypto",
+	}
+
+	orderResp, err := svc.CreateOrder(orderReq)
+	if err != nil {
+		t.Fatalf("Expected no error while creating order, got %v", err)
+	}
+
+	// Later catalog changes must not alter the recorded order
+	attributes["colour"] = "blue"
+
+	products, err := svc.GetProductsByOrderID(orderResp.OrderID)
+	if err != nil {
+		t.Fatalf("Expected no error while getting products, got %v", err)
+	}
+
+	if products[0].SKU != "TSHIRT-M-RED" {
+		t.Fatalf("Expected SKU TSHIRT-M-RED, got %s", products[0].SKU)
+	}
+	if products[0].Attributes["size"] != "M" || products[0].Attributes["colour"] != "red" {
+		t.Fatalf("Expected attribute snapshot, got %v", products[0].Attributes)
+	}
+}
+
+func TestGetDeliveredProductRequiresDeliveredOrder(t *testing.T) {
+	repo := repository.NewMockOrderRepository()
+	svc := NewOrderService(repo, nil, nil)
+
+	orderReq := &dto.CreateOrderRequest{
+		UserID: "user123",
+		Products: []*dto.OrderedProduct{
+			{ProductCode: "P1", ProductName: "Product", Quantity: 1, Price: 10.00},
+		},
+		ShippingAddress: "Address 123",
+		PaymentMethod:   "crypto",
+	}
+	createdOrder, _ := svc.CreateOrder(orderReq)
+
+	delivered, err := svc.GetDeliveredProduct("user123", "P1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if delivered.Delivered {
+		t.Fatal("Expected a pending order not to count as delivered")
+	}
+
+	for _, status := range []enums.OrderStatus{enums.OrderStatusConfirmed, enums.OrderStatusProcessing, enums.OrderStatusShipped, enums.OrderStatusDelivered} {
+		if err := svc.UpdateOrderStatus(createdOrder.OrderID, &dto.UpdateOrderStatusRequest{Status: status}); err != nil {
+			t.Fatalf("Expected no error moving to %s, got %v", status, err)
+		}
+	}
+
+	delivered, _ = svc.GetDeliveredProduct("user123", "P1")
+	if !delivered.Delivered {
+		t.Error("Expected P1 to be delivered to user123")
+	}
+
+	other, _ := svc.GetDeliveredProduct("user456", "P1")
+	if other.Delivered {
+		t.Error("Expected P1 not to be delivered to user456")
+	}
+
+	missing, _ := svc.GetDeliveredProduct("user123", "P2")
+	if missing.Delivered {
+		t.Error("Expected P2 not to be delivered to user123")
+	}
+}
+
+func TestOrderEventsArePublished(t *testing.T) {
+	broker := rabbitmq.NewInMemoryBroker(clock.Real(), logger.NewLogger())
+	publisher, err := messaging.NewPublisher(broker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.DeclareQueue("order-events")
+	broker.BindQueue("order-events", events.OrdersExchange, "order.#")
+
+	svc := NewOrderService(repository.NewMockOrderRepository(), nil, publisher)
+	order, err := svc.CreateOrder(&dto.CreateOrderRequest{
+		UserID:          "user123",
+		Products:        []*dto.OrderedProduct{{ProductCode: "P1", ProductName: "Product 1", Quantity: 2, Price: 10}},
+		ShippingAddress: "Address 123",
+		PaymentMethod:   "card",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error while creating order, got %v", err)
+	}
+	if err := svc.UpdateOrderStatus(order.OrderID, &dto.UpdateOrderStatusRequest{Status: enums.OrderStatusShipped}); err != nil {
+		t.Fatalf("Expected no error while updating status, got %v", err)
+	}
+
+	messages := broker.Messages("order-events")
+	routingKeys := make([]string, len(messages))
+	for i, message := range messages {
+		routingKeys[i] = message.RoutingKey
+	}
+	if len(messages) != 3 || routingKeys[0] != events.OrderCreated || routingKeys[1] != events.OrderStatusUpdated || routingKeys[2] != events.OrderShipped {
+		t.Fatalf("Expected created, status updated and shipped events, got %v", routingKeys)
+	}
+
+	var created events.Envelope[events.OrderCreatedEvent]
+	if err := json.Unmarshal(messages[0].Body, &created); err != nil {
+		t.Fatalf("Expected an order.created envelope, got %v", err)
+	}
+	if created.Producer != "order-service" || created.Data.OrderID != order.OrderID || created.Data.UserID != "user123" || len(created.Data.Products) != 1 {
+		t.Errorf("Expected the created order in the event, got %+v", created)
+	}
+
+	var updated events.Envelope[events.OrderStatusUpdatedEvent]
+	if err := json.Unmarshal(messages[1].Body, &updated); err != nil {
+		t.Fatalf("Expected an order.status.updated envelope, got %v", err)
+	}
+	if updated.Data.OldStatus != string(enums.OrderStatusPending) || updated.Data.NewStatus != string(enums.OrderStatusShipped) {
+		t.Errorf("Expected pending to shipped, got %s to %s", updated.Data.OldStatus, updated.Data.NewStatus)
+	}
+
+	var shipped events.Envelope[events.OrderShippedEvent]
+	if err := json.Unmarshal(messages[2].Body, &shipped); err != nil {
+		t.Fatalf("Expected an order.shipped envelope, got %v", err)
+	}
+	if created.CorrelationID != order.OrderID || updated.CorrelationID != created.CorrelationID || shipped.CorrelationID != created.CorrelationID {
+		t.Errorf("Expected the order events to be correlated by order id %s, got %s, %s and %s",
+			order.OrderID, created.CorrelationID, updated.CorrelationID, shipped.CorrelationID)
+	}
+}
@@ -1,3 +1,4 @@
+import csv
 import json
 from typing import List, Dict, Any
 from .product import ProductGenerator
@@ -37,3 +38,21 @@ class MockDataManager:
             print(f"Generated SQL INSERT statements for {len(data)} items and saved to '{filename}'")
         else:
             raise ValueError(f"Generator for {data_type} does not support SQL generation")
+
+    def save_to_ndjson_file(self, data: List[Dict[str, Any]], filename: str) -> None:
+        """Save data as JSON Lines, the format accepted by POST /products/import"""
+        with open(filename, 'w') as f:
+            for item in data:
+                f.write(json.dumps(item) + '\n')
+        print(f"Generated {len(data)} items and saved to '{filename}'")
+    
+    def save_to_csv_file(self, data: List[Dict[str, Any]], filename: str) -> None:
+        """Save data to CSV file with a header row"""
+        if not data:
+            return
+        
+        with open(filename, 'w', newline='') as f:
+            writer = csv.DictWriter(f, fieldnames=list(data[0].keys()))
+            writer.writeheader()
+            writer.writerows(data)
+        print(f"Generated {len(data)} items and saved to '{filename}'")
@@ -51,6 +51,7 @@ class ProductGenerator(BaseDataGenerator):
                 "name": self.fake.catch_phrase(),
                 "category": random.choice(self.categories),
                 "price": self._generate_price(),
+                "stock": random.randint(0, 200),
                 "description": description,
                 "product_code": self._generate_unique_upc(),
                 "created_at": self.fake.date_time_this_year().isoformat()
@@ -70,8 +71,8 @@ class ProductGenerator(BaseDataGenerator):
             category = product['category'].replace("'", "''")
             
             sql = (
-                f"INSERT INTO products.t_product (name, category, description, price, product_code, created_at) "
-                f"VALUES ('{name}', '{category}', '{description}', {product['price']}, '{product['product_code']}', '{product['created_at']}');"
+                f"INSERT INTO products.t_product (name, category, description, price, stock, product_code, created_at) "
+                f"VALUES ('{name}', '{category}', '{description}', {product['price']}, {product['stock']}, '{product['product_code']}', '{product['created_at']}');"
             )
             sql_lines.append(sql)
         
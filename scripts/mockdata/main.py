@@ -7,6 +7,8 @@ def main():
 
     manager.save_to_json_file(products, 'products.json')
     manager.save_to_sql_file('products', products, 'product.sql')
+    manager.save_to_ndjson_file(products, 'products.ndjson')
+    manager.save_to_csv_file(products, 'products.csv')
 
 
 if __name__ == "__main__":